@@ -3,10 +3,10 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/codex-switch/codex-switch/internal/config"
+	"github.com/codex-switch/codex-switch/internal/hooks"
 	"github.com/codex-switch/codex-switch/internal/logging"
 
 	"github.com/spf13/cobra"
@@ -17,6 +17,7 @@ var (
 	addAPIKey     string
 	addTags       string
 	addConfigPath string
+	addLocalOnly  bool
 )
 
 func init() {
@@ -30,6 +31,7 @@ func init() {
 	addCmd.Flags().StringVar(&addAPIKey, "key", "", "API Key 内容")
 	addCmd.Flags().StringVar(&addTags, "tags", "", "标签，逗号分隔")
 	addCmd.Flags().StringVar(&addConfigPath, "config-file", "", "配置文件路径，使用文件内容完整替换 Codex config.toml")
+	addCmd.Flags().BoolVar(&addLocalOnly, "local-only", false, "仅保存在本机，不参与远程同步")
 
 	RootCommand().AddCommand(addCmd)
 }
@@ -65,6 +67,7 @@ func runAdd(cmd *cobra.Command, _ []string) error {
 		APIKey:    apiKey,
 		Tags:      normalizeTags(addTags),
 		RawConfig: rawConfig,
+		LocalOnly: addLocalOnly,
 	}
 
 	created, err := manager.AddKey(newKey)
@@ -78,6 +81,8 @@ func runAdd(cmd *cobra.Command, _ []string) error {
 
 	fmt.Fprintf(cmd.OutOrStdout(), "✓ 成功添加 API Key: %s (%s)\n", created.Name, created.ID)
 	logging.Infof("添加 Key: %s (%s)", created.Name, created.ID)
+	runPostHook(cmd, manager, hooks.Payload{Event: config.HookPostAdd, Key: hooks.NewKeyInfo(created)})
+	autoPush(cmd, manager)
 	return nil
 }
 
@@ -85,23 +90,9 @@ func loadRawConfigContent(path string) (string, error) {
 	if strings.TrimSpace(path) == "" {
 		return "", fmt.Errorf("配置文件路径不能为空")
 	}
-	trimmed := strings.TrimSpace(path)
-
-	var finalPath string
-	if strings.HasPrefix(trimmed, "~") {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return "", fmt.Errorf("解析配置文件路径失败: %w", err)
-		}
-		rel := strings.TrimPrefix(trimmed, "~")
-		rel = strings.TrimPrefix(rel, string(os.PathSeparator))
-		finalPath = filepath.Join(home, rel)
-	} else {
-		abs, err := filepath.Abs(trimmed)
-		if err != nil {
-			return "", fmt.Errorf("解析配置文件路径失败: %w", err)
-		}
-		finalPath = abs
+	finalPath, err := resolvePath(path)
+	if err != nil {
+		return "", fmt.Errorf("解析配置文件路径失败: %w", err)
 	}
 
 	data, err := os.ReadFile(finalPath)
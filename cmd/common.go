@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/codex-switch/codex-switch/internal/config"
@@ -22,6 +24,14 @@ func mustLoadManager(cmd *cobra.Command) (*config.Manager, error) {
 	if _, err := manager.Load(); err != nil {
 		return nil, fmt.Errorf("加载配置失败: %w", err)
 	}
+	if remoteName != "" {
+		if err := manager.UseRemote(remoteName); err != nil {
+			return nil, err
+		}
+	}
+	if count := config.ErrorCount(manager.Issues()); count > 0 {
+		fmt.Fprintf(cmd.ErrOrStderr(), "⚠ 配置文件存在 %d 个错误，执行 ckm validate 查看详情\n", count)
+	}
 	return manager, nil
 }
 
@@ -41,3 +51,18 @@ func normalizeTags(input string) []string {
 	}
 	return result
 }
+
+// resolvePath 展开路径开头的 ~ 并转换为绝对路径
+func resolvePath(path string) (string, error) {
+	trimmed := strings.TrimSpace(path)
+	if strings.HasPrefix(trimmed, "~") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		rel := strings.TrimPrefix(trimmed, "~")
+		rel = strings.TrimPrefix(rel, string(os.PathSeparator))
+		return filepath.Join(home, rel), nil
+	}
+	return filepath.Abs(trimmed)
+}
@@ -2,12 +2,15 @@ package cmd
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/codex-switch/codex-switch/internal/config"
 	"github.com/codex-switch/codex-switch/internal/logging"
+	"github.com/codex-switch/codex-switch/internal/remote"
 
 	"github.com/pelletier/go-toml/v2"
 	"github.com/spf13/cobra"
@@ -15,23 +18,75 @@ import (
 )
 
 var (
-	exportOutput string
-	exportFormat string
+	exportOutput     string
+	exportFormat     string
+	exportKey        string
+	exportTag        string
+	exportSecretName string
+	exportNamespace  string
+	exportRepo       string
+	exportKeyVar     string
+	exportBaseURLVar string
+	exportType       string
+	exportRedact     string
+	exportNoRemote   bool
+	exportWithCreds  bool
+	exportWithHooks  bool
+)
+
+// 脱敏方式
+const (
+	redactMask = "mask"
+	redactEnv  = "env"
 )
 
 func init() {
 	exportCmd := &cobra.Command{
 		Use:   "export",
 		Short: "导出配置文件",
-		RunE:  runExport,
+		Long: `导出配置文件或部署所需的密钥格式。
+
+json/yaml/toml 导出完整配置，可通过 --key/--tag/--type 筛选 Key，
+并使用 --redact 与 --exclude-remote 生成可安全分享的无密钥配置；
+B2/S3 存储凭据、身份私钥与签名私钥默认导出为 env: 占位符，仅在指定 --include-credentials 时导出真实值；
+hooks 与 exec 凭据命令默认不导出，需要时指定 --include-hooks；
+dotenv、k8s-secret、docker-env、gh-secrets-script 针对单个 Key 生成部署文件，
+默认使用当前激活 Key，可通过 --key 或 --tag 选择。`,
+		Args: exportArgs,
+		RunE: runExport,
 	}
 
 	exportCmd.Flags().StringVar(&exportOutput, "output", "", "导出文件路径，默认输出到标准输出")
-	exportCmd.Flags().StringVar(&exportFormat, "format", "json", "导出格式: json/yaml/toml")
+	exportCmd.Flags().StringVar(&exportFormat, "format", "json", "导出格式: json/yaml/toml/dotenv/k8s-secret/docker-env/gh-secrets-script")
+	exportCmd.Flags().StringVar(&exportKey, "key", "", "按 Key ID 或名称筛选；部署格式默认使用当前激活 Key")
+	exportCmd.Flags().StringVar(&exportTag, "tag", "", "按标签筛选 Key；部署格式要求唯一匹配")
+	exportCmd.Flags().StringVar(&exportType, "type", "", "按类型筛选 Key: openai/crs")
+	exportCmd.Flags().StringVar(&exportRedact, "redact", "", "脱敏密钥: mask 使用掩码，env 使用 env: 占位符")
+	exportCmd.Flags().Lookup("redact").NoOptDefVal = redactMask
+	exportCmd.Flags().BoolVar(&exportNoRemote, "exclude-remote", false, "不导出 remote 远程同步配置")
+	exportCmd.Flags().BoolVar(&exportWithCreds, "include-credentials", false, "导出 B2/S3 存储凭据、身份私钥与签名私钥的真实值(默认替换为 env: 占位符)")
+	exportCmd.Flags().BoolVar(&exportWithHooks, "include-hooks", false, "导出 hooks 与 exec 凭据命令(默认不导出，导入方需 --allow-hooks)")
+	exportCmd.Flags().StringVar(&exportSecretName, "secret-name", "codex-api-key", "k8s-secret 格式的 Secret 名称")
+	exportCmd.Flags().StringVar(&exportNamespace, "namespace", "", "k8s-secret 格式的命名空间")
+	exportCmd.Flags().StringVar(&exportRepo, "repo", "", "gh-secrets-script 格式的目标仓库(owner/name)，默认当前仓库")
+	exportCmd.Flags().StringVar(&exportKeyVar, "api-key-var", "OPENAI_API_KEY", "部署格式中 API Key 的变量名")
+	exportCmd.Flags().StringVar(&exportBaseURLVar, "base-url-var", "OPENAI_BASE_URL", "部署格式中 Base URL 的变量名")
 
 	RootCommand().AddCommand(exportCmd)
 }
 
+// exportArgs 拒绝多余的位置参数。--redact 可省略取值，"--redact env" 中的 env
+// 会被当作位置参数，此时提示改用 --redact=env
+func exportArgs(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return nil
+	}
+	if cmd.Flags().Changed("redact") {
+		return fmt.Errorf("多余的参数 %q，指定脱敏方式需使用 --redact=%s", args[0], args[0])
+	}
+	return cobra.NoArgs(cmd, args)
+}
+
 func runExport(cmd *cobra.Command, _ []string) error {
 	manager, err := mustLoadManager(cmd)
 	if err != nil {
@@ -43,9 +98,54 @@ func runExport(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 
-	data, err := encodeConfig(cfg, exportFormat)
-	if err != nil {
-		return err
+	var data []byte
+	if isDeployFormat(exportFormat) {
+		if exportRedact != "" {
+			return fmt.Errorf("%s 格式用于部署真实密钥，不支持 --redact", exportFormat)
+		}
+		key, err := selectExportKey(cfg, exportKey, exportTag)
+		if err != nil {
+			return err
+		}
+		opts := deployOptions{
+			SecretName: exportSecretName,
+			Namespace:  exportNamespace,
+			Repo:       exportRepo,
+			KeyVar:     exportKeyVar,
+			BaseURLVar: exportBaseURLVar,
+		}
+		data, err = encodeDeploy(key, exportFormat, opts)
+		if err != nil {
+			return err
+		}
+		logging.Infof("导出部署格式: %s key=%s(%s)", exportFormat, key.Name, key.ID)
+	} else {
+		filtered := filterExportConfig(cfg, exportKey, exportTag, exportType)
+		if exportNoRemote {
+			filtered.Remote = nil
+			filtered.Remotes = nil
+			filtered.DefaultRemote = ""
+		}
+		if exportRedact != "" {
+			if exportWithCreds {
+				return errors.New("--include-credentials 不能与 --redact 同时使用")
+			}
+			if err := redactConfig(filtered, exportRedact); err != nil {
+				return err
+			}
+		}
+		if !exportWithHooks {
+			stripExecutables(filtered)
+		}
+		for _, settings := range configRemotes(filtered) {
+			if err := exportRemoteCredentials(settings, exportWithCreds); err != nil {
+				return err
+			}
+		}
+		data, err = encodeConfig(filtered, exportFormat)
+		if err != nil {
+			return err
+		}
 	}
 
 	if exportOutput == "" {
@@ -77,3 +177,157 @@ func encodeConfig(cfg *config.Config, format string) ([]byte, error) {
 		return nil, fmt.Errorf("不支持的格式: %s", format)
 	}
 }
+
+// filterExportConfig 返回按 ID/名称、标签、类型筛选后的配置副本，
+// 远程配置同样做拷贝，避免后续脱敏修改到管理器中的数据。
+// 存在命名远程时仅导出 remotes 字段，Remote 与其中一项重复。
+func filterExportConfig(cfg *config.Config, ref string, tag string, keyType string) *config.Config {
+	result := *cfg
+	if cfg.Remote != nil {
+		remoteCopy := *cfg.Remote
+		result.Remote = &remoteCopy
+	}
+	if len(cfg.Remotes) > 0 {
+		result.Remote = nil
+		result.Remotes = cloneRemotes(cfg.Remotes)
+	}
+
+	ref = strings.TrimSpace(ref)
+	tag = strings.TrimSpace(tag)
+	keyType = strings.TrimSpace(keyType)
+
+	result.Keys = make([]config.APIKey, 0, len(cfg.Keys))
+	for _, k := range cfg.Keys {
+		if ref != "" && k.ID != ref && !strings.EqualFold(k.Name, ref) {
+			continue
+		}
+		if tag != "" && !hasTag(k, tag) {
+			continue
+		}
+		if keyType != "" && !strings.EqualFold(k.Type, keyType) {
+			continue
+		}
+		k.Tags = append([]string(nil), k.Tags...)
+		result.Keys = append(result.Keys, k)
+	}
+
+	activeKept := false
+	for _, k := range result.Keys {
+		if k.ID == result.ActiveKeyID {
+			activeKept = true
+			break
+		}
+	}
+	if !activeKept {
+		result.ActiveKeyID = ""
+	}
+	return &result
+}
+
+// redactConfig 将 API Key、原始配置及远程凭据替换为掩码或 env: 占位符
+func redactConfig(cfg *config.Config, mode string) error {
+	if mode != redactMask && mode != redactEnv {
+		return fmt.Errorf("不支持的脱敏方式: %s", mode)
+	}
+
+	for i := range cfg.Keys {
+		key := &cfg.Keys[i]
+		key.APIKey = redactValue(key.APIKey, mode, config.EnvPlaceholderName(*key))
+		// 原始配置可能内嵌凭据，导入时会沿用本地已有内容
+		key.RawConfig = ""
+	}
+
+	for _, settings := range configRemotes(cfg) {
+		keyIDVar, appKeyVar := remoteEnvPlaceholders(settings)
+		settings.KeyID = redactValue(settings.KeyID, mode, keyIDVar)
+		settings.ApplicationKey = redactValue(settings.ApplicationKey, mode, appKeyVar)
+		settings.SyncToken = redactValue(settings.SyncToken, mode, "CKM_SYNC_TOKEN")
+		settings.Password = redactValue(settings.Password, mode, "CKM_WEBDAV_PASSWORD")
+		settings.BearerToken = redactValue(settings.BearerToken, mode, "CKM_WEBDAV_TOKEN")
+		settings.SigningKey = redactValue(settings.SigningKey, mode, "CKM_SIGNING_KEY")
+		settings.Identity = redactValue(settings.Identity, mode, "CKM_IDENTITY_KEY")
+	}
+	return nil
+}
+
+// stripExecutables 去除 hooks 与 exec 凭据命令，exec 方式的远程改为从环境变量读取凭据
+func stripExecutables(cfg *config.Config) {
+	cfg.Hooks = nil
+	for _, settings := range configRemotes(cfg) {
+		if settings.CredentialStore == config.CredentialStoreExec {
+			settings.CredentialStore = config.CredentialStoreEnv
+		}
+		settings.CredentialHelper = ""
+	}
+}
+
+// configRemotes 返回配置中的全部远程，旧格式仅有 Remote 时视为 origin
+func configRemotes(cfg *config.Config) map[string]*config.RemoteSettings {
+	if len(cfg.Remotes) > 0 {
+		return cfg.Remotes
+	}
+	if cfg.Remote == nil {
+		return nil
+	}
+	return map[string]*config.RemoteSettings{config.DefaultRemoteName: cfg.Remote}
+}
+
+// cloneRemotes 逐项拷贝远程配置，修改副本不影响原配置
+func cloneRemotes(remotes map[string]*config.RemoteSettings) map[string]*config.RemoteSettings {
+	if remotes == nil {
+		return nil
+	}
+	result := make(map[string]*config.RemoteSettings, len(remotes))
+	for name, settings := range remotes {
+		settingsCopy := *settings
+		result[name] = &settingsCopy
+	}
+	return result
+}
+
+// remoteEnvPlaceholders 返回远程凭据 env: 占位符使用的环境变量，默认远程沿用通用变量名
+func remoteEnvPlaceholders(settings *config.RemoteSettings) (string, string) {
+	if settings.Name() == config.DefaultRemoteName {
+		return config.EnvRemoteKeyID, config.EnvRemoteAppKey
+	}
+	return config.RemoteEnvNames(settings.Name())
+}
+
+// exportRemoteCredentials 处理导出的存储凭据与私钥：默认替换为 env: 占位符，导入时沿用本地值；
+// include 为 true 时解析出真实凭据写入配置，使导出文件不依赖本机的凭据文件或环境变量。
+// 身份私钥可解密全部按接收者加密的快照，签名私钥可伪造可信签名，因此与存储凭据同样处理。
+func exportRemoteCredentials(settings *config.RemoteSettings, include bool) error {
+	if !include {
+		keyIDVar, appKeyVar := remoteEnvPlaceholders(settings)
+		settings.KeyID = redactValue(settings.KeyID, redactEnv, keyIDVar)
+		settings.ApplicationKey = redactValue(settings.ApplicationKey, redactEnv, appKeyVar)
+		settings.SigningKey = redactValue(settings.SigningKey, redactEnv, "CKM_SIGNING_KEY")
+		settings.Identity = redactValue(settings.Identity, redactEnv, "CKM_IDENTITY_KEY")
+		return nil
+	}
+	switch providerOf(settings) {
+	case remote.ProviderB2, remote.ProviderS3:
+	default:
+		return nil
+	}
+	creds, err := config.ResolveRemoteCredentials(settings, credentialsPath(settings))
+	if err != nil {
+		return err
+	}
+	settings.KeyID = creds.KeyID
+	settings.ApplicationKey = creds.ApplicationKey
+	settings.CredentialStore = ""
+	settings.CredentialHelper = ""
+	return nil
+}
+
+// redactValue 脱敏单个值，空值保持为空以免误导
+func redactValue(value string, mode string, envName string) string {
+	if strings.TrimSpace(value) == "" {
+		return ""
+	}
+	if mode == redactEnv {
+		return config.EnvPlaceholder(envName)
+	}
+	return config.MaskSecret(value)
+}
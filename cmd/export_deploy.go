@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 部署格式名称
+const (
+	formatDotenv    = "dotenv"
+	formatK8sSecret = "k8s-secret"
+	formatDockerEnv = "docker-env"
+	formatGHSecrets = "gh-secrets-script"
+)
+
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// deployOptions 控制部署格式的命名细节
+type deployOptions struct {
+	SecretName string
+	Namespace  string
+	Repo       string
+	KeyVar     string
+	BaseURLVar string
+}
+
+// deployVar 表示一个待导出的环境变量
+type deployVar struct {
+	Name  string
+	Value string
+}
+
+func isDeployFormat(format string) bool {
+	switch format {
+	case formatDotenv, formatK8sSecret, formatDockerEnv, formatGHSecrets:
+		return true
+	default:
+		return false
+	}
+}
+
+// selectExportKey 根据 --key/--tag 选出唯一的 Key，均未指定时使用激活 Key
+func selectExportKey(cfg *config.Config, ref string, tag string) (config.APIKey, error) {
+	ref = strings.TrimSpace(ref)
+	tag = strings.TrimSpace(tag)
+
+	if ref != "" {
+		for _, k := range cfg.Keys {
+			if k.ID == ref {
+				return k, nil
+			}
+		}
+		for _, k := range cfg.Keys {
+			if strings.EqualFold(k.Name, ref) {
+				return k, nil
+			}
+		}
+		return config.APIKey{}, fmt.Errorf("未找到 ID 或名称为 %s 的 Key", ref)
+	}
+
+	if tag != "" {
+		var matched []config.APIKey
+		for _, k := range cfg.Keys {
+			if hasTag(k, tag) {
+				matched = append(matched, k)
+			}
+		}
+		switch len(matched) {
+		case 0:
+			return config.APIKey{}, fmt.Errorf("没有 Key 带有标签 %s", tag)
+		case 1:
+			return matched[0], nil
+		default:
+			names := make([]string, 0, len(matched))
+			for _, k := range matched {
+				names = append(names, k.Name)
+			}
+			return config.APIKey{}, fmt.Errorf("标签 %s 匹配到多个 Key (%s)，请使用 --key 指定", tag, strings.Join(names, ", "))
+		}
+	}
+
+	for _, k := range cfg.Keys {
+		if k.ID == cfg.ActiveKeyID {
+			return k, nil
+		}
+	}
+	return config.APIKey{}, errors.New("当前没有激活的 Key，请通过 --key 或 --tag 指定")
+}
+
+// hasTag 判断 Key 是否包含指定标签(忽略大小写)
+func hasTag(key config.APIKey, tag string) bool {
+	for _, t := range key.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// buildDeployVars 生成部署格式需要的变量列表，按名称排序保证输出稳定
+func buildDeployVars(key config.APIKey, opts deployOptions) ([]deployVar, error) {
+	keyVar := strings.TrimSpace(opts.KeyVar)
+	if keyVar == "" {
+		keyVar = "OPENAI_API_KEY"
+	}
+	baseVar := strings.TrimSpace(opts.BaseURLVar)
+	if baseVar == "" {
+		baseVar = "OPENAI_BASE_URL"
+	}
+	if strings.TrimSpace(key.APIKey) == "" {
+		return nil, fmt.Errorf("Key %s 的 API Key 为空", key.Name)
+	}
+
+	values := map[string]string{keyVar: key.APIKey}
+	base := strings.TrimSpace(key.BaseURL)
+	if base != "" {
+		if baseVar == keyVar {
+			return nil, fmt.Errorf("Base URL 变量名 %s 与 API Key 变量名相同", baseVar)
+		}
+		values[baseVar] = base
+	}
+	// Codex 通过 env_key 读取密钥，额外导出同名变量；与 Base URL 变量同名时会覆盖 Base URL，直接报错
+	if envKey := strings.TrimSpace(key.EnvKey); envKey != "" {
+		if base != "" && envKey == baseVar {
+			return nil, fmt.Errorf("Key %s 的 env_key %s 与 Base URL 变量名相同，请通过 --base-url-var 指定其他变量名", key.Name, envKey)
+		}
+		values[envKey] = key.APIKey
+	}
+
+	vars := make([]deployVar, 0, len(values))
+	for name, value := range values {
+		if !envNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("非法的变量名: %s", name)
+		}
+		vars = append(vars, deployVar{Name: name, Value: value})
+	}
+	sort.Slice(vars, func(i, j int) bool { return vars[i].Name < vars[j].Name })
+	return vars, nil
+}
+
+// encodeDeploy 将单个 Key 渲染为部署格式
+func encodeDeploy(key config.APIKey, format string, opts deployOptions) ([]byte, error) {
+	vars, err := buildDeployVars(key, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case formatDotenv:
+		return renderDotenv(vars), nil
+	case formatDockerEnv:
+		return renderDockerEnv(vars)
+	case formatK8sSecret:
+		return renderK8sSecret(key, vars, opts)
+	case formatGHSecrets:
+		return renderGHSecretsScript(vars, opts), nil
+	default:
+		return nil, fmt.Errorf("不支持的格式: %s", format)
+	}
+}
+
+// renderDotenv 输出 KEY="value" 形式，转义双引号、反斜杠、$ 与换行
+func renderDotenv(vars []deployVar) []byte {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "\n", `\n`)
+	var b strings.Builder
+	for _, v := range vars {
+		fmt.Fprintf(&b, "%s=\"%s\"\n", v.Name, replacer.Replace(v.Value))
+	}
+	return []byte(strings.TrimSuffix(b.String(), "\n"))
+}
+
+// renderDockerEnv 输出 docker --env-file 格式，该格式不支持引号与多行值
+func renderDockerEnv(vars []deployVar) ([]byte, error) {
+	var b strings.Builder
+	for _, v := range vars {
+		if strings.ContainsAny(v.Value, "\r\n") {
+			return nil, fmt.Errorf("变量 %s 含有换行，docker env-file 无法表示", v.Name)
+		}
+		fmt.Fprintf(&b, "%s=%s\n", v.Name, v.Value)
+	}
+	return []byte(strings.TrimSuffix(b.String(), "\n")), nil
+}
+
+type k8sMetadata struct {
+	Name      string            `yaml:"name"`
+	Namespace string            `yaml:"namespace,omitempty"`
+	Labels    map[string]string `yaml:"labels,omitempty"`
+}
+
+type k8sSecret struct {
+	APIVersion string            `yaml:"apiVersion"`
+	Kind       string            `yaml:"kind"`
+	Metadata   k8sMetadata       `yaml:"metadata"`
+	Type       string            `yaml:"type"`
+	Data       map[string]string `yaml:"data"`
+}
+
+// renderK8sSecret 输出 Opaque 类型的 Secret 清单，值使用 base64 编码
+func renderK8sSecret(key config.APIKey, vars []deployVar, opts deployOptions) ([]byte, error) {
+	name := strings.TrimSpace(opts.SecretName)
+	if name == "" {
+		name = "codex-api-key"
+	}
+	secret := k8sSecret{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Metadata: k8sMetadata{
+			Name:      name,
+			Namespace: strings.TrimSpace(opts.Namespace),
+			Labels:    map[string]string{"app.kubernetes.io/managed-by": "ckm"},
+		},
+		Type: "Opaque",
+		Data: make(map[string]string, len(vars)),
+	}
+	for _, v := range vars {
+		secret.Data[v.Name] = base64.StdEncoding.EncodeToString([]byte(v.Value))
+	}
+	data, err := yaml.Marshal(secret)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(strings.TrimSuffix(string(data), "\n")), nil
+}
+
+// renderGHSecretsScript 输出一组 gh secret set 命令，值通过单引号安全转义。
+// 值由 shell 内置的 printf 经标准输入传给 gh，不出现在 gh 进程的命令行参数中
+func renderGHSecretsScript(vars []deployVar, opts deployOptions) []byte {
+	repoArg := ""
+	if repo := strings.TrimSpace(opts.Repo); repo != "" {
+		repoArg = " --repo " + shellQuote(repo)
+	}
+	var b strings.Builder
+	b.WriteString("#!/usr/bin/env bash\n")
+	b.WriteString("set -euo pipefail\n")
+	for _, v := range vars {
+		fmt.Fprintf(&b, "printf '%%s' %s | gh secret set %s%s\n", shellQuote(v.Value), v.Name, repoArg)
+	}
+	return []byte(strings.TrimSuffix(b.String(), "\n"))
+}
+
+// shellQuote 使用单引号包裹字符串，适用于 POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
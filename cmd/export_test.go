@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+func exportTestConfig() *config.Config {
+	return &config.Config{
+		ActiveKeyID: "1",
+		Keys: []config.APIKey{
+			{ID: "1", Name: "main", APIKey: "sk-main", BaseURL: "https://api.openai.com/v1", Tags: []string{"prod"}, Active: true},
+			{ID: "2", Name: "relay", APIKey: "sk-'relay\"$", BaseURL: "https://relay.example.com", EnvKey: "CRS_OAI_KEY", Tags: []string{"dev", "shared"}},
+			{ID: "3", Name: "backup", APIKey: "sk-backup", Tags: []string{"shared"}},
+		},
+	}
+}
+
+// TestSelectExportKey 验证 --key/--tag 的选择规则
+func TestSelectExportKey(t *testing.T) {
+	cfg := exportTestConfig()
+
+	key, err := selectExportKey(cfg, "", "")
+	if err != nil || key.ID != "1" {
+		t.Fatalf("默认应选择激活 Key, got=%v err=%v", key.ID, err)
+	}
+	key, err = selectExportKey(cfg, "RELAY", "")
+	if err != nil || key.ID != "2" {
+		t.Fatalf("按名称选择失败, got=%v err=%v", key.ID, err)
+	}
+	key, err = selectExportKey(cfg, "", "dev")
+	if err != nil || key.ID != "2" {
+		t.Fatalf("按标签选择失败, got=%v err=%v", key.ID, err)
+	}
+	if _, err := selectExportKey(cfg, "", "shared"); err == nil {
+		t.Fatalf("标签匹配多个 Key 时应报错")
+	}
+	if _, err := selectExportKey(cfg, "missing", ""); err == nil {
+		t.Fatalf("不存在的 Key 应报错")
+	}
+}
+
+// TestEncodeDeployFormats 验证各部署格式的输出
+func TestEncodeDeployFormats(t *testing.T) {
+	cfg := exportTestConfig()
+	relay := cfg.Keys[1]
+	opts := deployOptions{SecretName: "codex", Namespace: "ai", Repo: "acme/app"}
+
+	dotenv, err := encodeDeploy(relay, formatDotenv, opts)
+	if err != nil {
+		t.Fatalf("dotenv 导出失败: %v", err)
+	}
+	if !strings.Contains(string(dotenv), `OPENAI_API_KEY="sk-'relay\"\$"`) {
+		t.Fatalf("dotenv 转义不正确: %s", dotenv)
+	}
+	if !strings.Contains(string(dotenv), `CRS_OAI_KEY=`) {
+		t.Fatalf("dotenv 应包含 env_key 变量: %s", dotenv)
+	}
+
+	dockerEnv, err := encodeDeploy(cfg.Keys[0], formatDockerEnv, opts)
+	if err != nil {
+		t.Fatalf("docker-env 导出失败: %v", err)
+	}
+	if string(dockerEnv) != "OPENAI_API_KEY=sk-main\nOPENAI_BASE_URL=https://api.openai.com/v1" {
+		t.Fatalf("docker-env 输出不符合预期: %q", dockerEnv)
+	}
+
+	manifest, err := encodeDeploy(relay, formatK8sSecret, opts)
+	if err != nil {
+		t.Fatalf("k8s-secret 导出失败: %v", err)
+	}
+	var secret k8sSecret
+	if err := yaml.Unmarshal(manifest, &secret); err != nil {
+		t.Fatalf("解析 Secret 失败: %v", err)
+	}
+	if secret.Kind != "Secret" || secret.Metadata.Name != "codex" || secret.Metadata.Namespace != "ai" {
+		t.Fatalf("Secret 元数据不正确: %#v", secret)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(secret.Data["OPENAI_API_KEY"])
+	if err != nil || string(decoded) != relay.APIKey {
+		t.Fatalf("Secret 数据解码不正确: %q err=%v", decoded, err)
+	}
+
+	script, err := encodeDeploy(relay, formatGHSecrets, opts)
+	if err != nil {
+		t.Fatalf("gh-secrets-script 导出失败: %v", err)
+	}
+	if !strings.Contains(string(script), `printf '%s' 'sk-'"'"'relay"$' | gh secret set OPENAI_API_KEY --repo 'acme/app'`) {
+		t.Fatalf("gh 脚本引用不正确: %s", script)
+	}
+
+	if _, err := encodeDeploy(relay, formatDotenv, deployOptions{KeyVar: "BAD-NAME"}); err == nil {
+		t.Fatalf("非法变量名应报错")
+	}
+
+	// env_key 或 API Key 变量与 Base URL 变量同名时会覆盖 Base URL，应报错
+	clash := relay
+	clash.BaseURL = "https://relay.example.com/v1"
+	clash.EnvKey = "OPENAI_BASE_URL"
+	if _, err := encodeDeploy(clash, formatDotenv, opts); err == nil {
+		t.Fatalf("env_key 与 Base URL 变量同名应报错")
+	}
+	clash.EnvKey = "RELAY_URL"
+	if _, err := encodeDeploy(clash, formatDotenv, deployOptions{BaseURLVar: "RELAY_URL"}); err == nil {
+		t.Fatalf("env_key 与 --base-url-var 同名应报错")
+	}
+	if _, err := encodeDeploy(clash, formatDotenv, deployOptions{KeyVar: "API", BaseURLVar: "API"}); err == nil {
+		t.Fatalf("API Key 变量与 Base URL 变量同名应报错")
+	}
+}
+
+// TestRedactedExportRoundTrip 验证脱敏导出后再导入能恢复本地密钥
+func TestRedactedExportRoundTrip(t *testing.T) {
+	cfg := exportTestConfig()
+	cfg.Keys[0].RawConfig = "model = \"gpt-5-codex\"\n"
+	cfg.Remote = &config.RemoteSettings{KeyID: "key-id-123456", ApplicationKey: "app-key-abcdefgh", SyncToken: "token-abcdefghijk"}
+
+	filtered := filterExportConfig(cfg, "", "shared", "")
+	if len(filtered.Keys) != 2 || filtered.ActiveKeyID != "" {
+		t.Fatalf("标签筛选结果不正确: %#v", filtered)
+	}
+
+	redacted := filterExportConfig(cfg, "", "", "")
+	if err := redactConfig(redacted, redactMask); err != nil {
+		t.Fatalf("脱敏失败: %v", err)
+	}
+	for _, k := range redacted.Keys {
+		if !config.IsSecretPlaceholder(k.APIKey) || k.RawConfig != "" {
+			t.Fatalf("Key %s 未被脱敏: %#v", k.Name, k)
+		}
+	}
+	if cfg.Keys[0].APIKey != "sk-main" || cfg.Remote.ApplicationKey != "app-key-abcdefgh" {
+		t.Fatalf("脱敏不应修改原始配置")
+	}
+	if !config.IsSecretPlaceholder(redacted.Remote.ApplicationKey) {
+		t.Fatalf("远程凭据未被脱敏: %#v", redacted.Remote)
+	}
+
+	if err := restoreRedacted(cfg, redacted); err != nil {
+		t.Fatalf("恢复脱敏配置失败: %v", err)
+	}
+	if redacted.Keys[0].APIKey != "sk-main" || redacted.Keys[0].RawConfig == "" {
+		t.Fatalf("未恢复本地密钥: %#v", redacted.Keys[0])
+	}
+	if redacted.Remote.ApplicationKey != "app-key-abcdefgh" {
+		t.Fatalf("未恢复远程凭据: %#v", redacted.Remote)
+	}
+
+	envOnly := filterExportConfig(cfg, "backup", "", "")
+	if err := redactConfig(envOnly, redactEnv); err != nil {
+		t.Fatalf("脱敏失败: %v", err)
+	}
+	if envOnly.Keys[0].APIKey != "env:CKM_KEY_BACKUP" {
+		t.Fatalf("env 占位符不正确: %s", envOnly.Keys[0].APIKey)
+	}
+	empty := &config.Config{}
+	if err := restoreRedacted(empty, envOnly); err == nil {
+		t.Fatalf("无法解析的占位符应报错")
+	}
+	t.Setenv("CKM_KEY_BACKUP", "sk-from-env")
+	if err := restoreRedacted(empty, envOnly); err != nil || envOnly.Keys[0].APIKey != "sk-from-env" {
+		t.Fatalf("应从环境变量恢复密钥, got=%s err=%v", envOnly.Keys[0].APIKey, err)
+	}
+}
+
+// TestExportRemoteCredentials 验证存储凭据默认以占位符导出，导入时沿用本地凭据
+func TestExportRemoteCredentials(t *testing.T) {
+	cfg := exportTestConfig()
+	cfg.Remote = &config.RemoteSettings{Provider: "b2", KeyID: "key-id-123456", ApplicationKey: "app-key-abcdefgh",
+		SigningKey: "signing-seed-base64", Identity: "x25519-identity-base64"}
+
+	exported := filterExportConfig(cfg, "", "", "")
+	if err := exportRemoteCredentials(exported.Remote, false); err != nil {
+		t.Fatalf("处理凭据失败: %v", err)
+	}
+	if exported.Remote.KeyID != "env:CKM_B2_KEY_ID" || exported.Remote.ApplicationKey != "env:CKM_B2_APP_KEY" {
+		t.Fatalf("凭据应替换为占位符: %#v", exported.Remote)
+	}
+	if exported.Remote.SigningKey != "env:CKM_SIGNING_KEY" || exported.Remote.Identity != "env:CKM_IDENTITY_KEY" {
+		t.Fatalf("私钥应替换为占位符: %#v", exported.Remote)
+	}
+	if cfg.Remote.KeyID != "key-id-123456" {
+		t.Fatalf("不应修改原始配置")
+	}
+	if err := restoreRedacted(cfg, exported); err != nil || exported.Remote.ApplicationKey != "app-key-abcdefgh" {
+		t.Fatalf("导入时应沿用本地凭据: %#v err=%v", exported.Remote, err)
+	}
+	if exported.Remote.SigningKey != "signing-seed-base64" || exported.Remote.Identity != "x25519-identity-base64" {
+		t.Fatalf("导入时应沿用本地私钥: %#v", exported.Remote)
+	}
+
+	t.Setenv(config.EnvRemoteKeyID, "")
+	t.Setenv(config.EnvRemoteAppKey, "")
+	included := filterExportConfig(cfg, "", "", "")
+	if err := exportRemoteCredentials(included.Remote, true); err != nil {
+		t.Fatalf("处理凭据失败: %v", err)
+	}
+	if included.Remote.ApplicationKey != "app-key-abcdefgh" || included.Remote.Identity != "x25519-identity-base64" {
+		t.Fatalf("--include-credentials 应导出真实凭据: %#v", included.Remote)
+	}
+
+	// 新机器上没有本地值时，无法解析的占位符应与 Key 一样报错而不是置空
+	fresh := filterExportConfig(cfg, "", "", "")
+	if err := exportRemoteCredentials(fresh.Remote, false); err != nil {
+		t.Fatalf("处理凭据失败: %v", err)
+	}
+	err := restoreRedacted(&config.Config{}, fresh)
+	if err == nil || !strings.Contains(err.Error(), "identity") || !strings.Contains(err.Error(), "signing_key") {
+		t.Fatalf("无法恢复的私钥应报错, got %v", err)
+	}
+	t.Setenv(config.EnvRemoteKeyID, "key-id-env")
+	t.Setenv(config.EnvRemoteAppKey, "app-key-env")
+	t.Setenv("CKM_SIGNING_KEY", "signing-env")
+	t.Setenv("CKM_IDENTITY_KEY", "identity-env")
+	fresh = filterExportConfig(cfg, "", "", "")
+	if err := exportRemoteCredentials(fresh.Remote, false); err != nil {
+		t.Fatalf("处理凭据失败: %v", err)
+	}
+	if err := restoreRedacted(&config.Config{}, fresh); err != nil || fresh.Remote.Identity != "identity-env" {
+		t.Fatalf("应从环境变量恢复私钥: %#v err=%v", fresh.Remote, err)
+	}
+}
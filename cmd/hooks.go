@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+	"github.com/codex-switch/codex-switch/internal/display"
+	"github.com/codex-switch/codex-switch/internal/hooks"
+	"github.com/codex-switch/codex-switch/internal/logging"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	hooksCmd := &cobra.Command{
+		Use:   "hooks",
+		Short: "管理 Key 切换、新增、删除及拉取后执行的用户脚本",
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "列出已配置的 hook",
+		Args:  cobra.NoArgs,
+		RunE:  runHooksList,
+	}
+	addCmd := &cobra.Command{
+		Use:   "add EVENT PATH",
+		Short: "为事件追加 hook，事件: " + strings.Join(config.HookEvents, "/"),
+		Args:  cobra.ExactArgs(2),
+		RunE:  runHooksAdd,
+	}
+	removeCmd := &cobra.Command{
+		Use:   "remove EVENT PATH",
+		Short: "移除事件的 hook",
+		Args:  cobra.ExactArgs(2),
+		RunE:  runHooksRemove,
+	}
+
+	hooksCmd.AddCommand(listCmd, addCmd, removeCmd)
+	RootCommand().AddCommand(hooksCmd)
+}
+
+func runHooksList(cmd *cobra.Command, _ []string) error {
+	manager, err := mustLoadManager(cmd)
+	if err != nil {
+		return err
+	}
+	cfg, err := manager.Config()
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "事件\t可执行文件")
+	count := 0
+	for _, event := range config.HookEvents {
+		for _, command := range cfg.Hooks.Commands(event) {
+			fmt.Fprintf(w, "%s\t%s\n", event, command)
+			count++
+		}
+	}
+	if count == 0 {
+		fmt.Fprintln(out, "尚未配置 hook，可执行 ckm hooks add <EVENT> <PATH>")
+		return nil
+	}
+	w.Flush()
+	fmt.Fprintf(out, "\n单个 hook 超时时间: %s\n", cfg.Hooks.TimeoutDuration())
+	return nil
+}
+
+func runHooksAdd(cmd *cobra.Command, args []string) error {
+	event, command := args[0], strings.TrimSpace(args[1])
+	// 相对路径转换为绝对路径；保留 ~ 写法，执行时再展开，便于配置在多台机器间共用
+	if !strings.HasPrefix(command, "~") && strings.ContainsAny(command, `/\`) {
+		abs, err := resolvePath(command)
+		if err != nil {
+			return err
+		}
+		command = abs
+	}
+	return updateHooks(cmd, event, func(commands []string) ([]string, error) {
+		if slices.Contains(commands, command) {
+			return nil, fmt.Errorf("%s 已配置 hook %s", event, command)
+		}
+		return append(commands, command), nil
+	}, fmt.Sprintf("✓ 已为 %s 添加 hook: %s", event, command))
+}
+
+func runHooksRemove(cmd *cobra.Command, args []string) error {
+	event, command := args[0], strings.TrimSpace(args[1])
+	return updateHooks(cmd, event, func(commands []string) ([]string, error) {
+		index := slices.IndexFunc(commands, func(c string) bool {
+			if c == command {
+				return true
+			}
+			abs, err := resolvePath(command)
+			return err == nil && c == abs
+		})
+		if index < 0 {
+			return nil, fmt.Errorf("%s 未配置 hook %s", event, command)
+		}
+		return slices.Delete(commands, index, index+1), nil
+	}, fmt.Sprintf("✓ 已移除 %s 的 hook: %s", event, command))
+}
+
+// updateHooks 修改事件的 hook 列表并保存配置
+func updateHooks(cmd *cobra.Command, event string, apply func([]string) ([]string, error), message string) error {
+	if !slices.Contains(config.HookEvents, event) {
+		return fmt.Errorf("未知的 hook 事件 %s，支持 %s", event, strings.Join(config.HookEvents, "/"))
+	}
+	manager, err := mustLoadManager(cmd)
+	if err != nil {
+		return err
+	}
+	cfg, err := manager.Config()
+	if err != nil {
+		return err
+	}
+	settings := config.HookSettings{}
+	if cfg.Hooks != nil {
+		settings = *cfg.Hooks
+	}
+	commands, err := apply(slices.Clone(settings.Commands(event)))
+	if err != nil {
+		return err
+	}
+	if err := settings.SetCommands(event, commands); err != nil {
+		return err
+	}
+	cfg.Hooks = &settings
+
+	if err := manager.ReplaceConfig(cfg); err != nil {
+		return err
+	}
+	if err := manager.Save(); err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), message)
+	logging.Infof("更新 hook: event=%s commands=%v", event, commands)
+	return nil
+}
+
+// runHook 执行事件对应的 hook，返回首个失败
+func runHook(cmd *cobra.Command, manager *config.Manager, payload hooks.Payload) error {
+	cfg, err := manager.Config()
+	if err != nil {
+		return err
+	}
+	return hooks.NewRunner(cfg.Hooks, cmd.OutOrStdout(), cmd.ErrOrStderr()).Run(cmd.Context(), payload)
+}
+
+// runPostHook 执行 post_* hook，失败时仅输出警告，不影响已完成的操作
+func runPostHook(cmd *cobra.Command, manager *config.Manager, payload hooks.Payload) {
+	if err := runHook(cmd, manager, payload); err != nil {
+		fmt.Fprintf(cmd.ErrOrStderr(), "%s %v\n", display.ColorWarning.Sprint("⚠"), err)
+		logging.Warnf("hook 执行失败: %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+	"github.com/codex-switch/codex-switch/internal/logging"
+	"github.com/codex-switch/codex-switch/internal/remote"
+
+	"github.com/spf13/cobra"
+)
+
+var identityRotate bool
+
+func init() {
+	identityCmd := &cobra.Command{
+		Use:   "identity",
+		Short: "管理本机 X25519 身份，用于解密按接收者公钥加密的团队快照",
+	}
+
+	initCmd := &cobra.Command{
+		Use:   "init",
+		Short: "生成本机身份私钥并输出公钥，供团队成员执行 ckm remote recipients add",
+		Args:  cobra.NoArgs,
+		RunE:  runIdentityInit,
+	}
+	initCmd.Flags().BoolVar(&identityRotate, "rotate", false, "已存在身份时重新生成 (旧身份将无法再解密团队快照)")
+
+	showCmd := &cobra.Command{
+		Use:   "show",
+		Short: "输出本机身份公钥",
+		Args:  cobra.NoArgs,
+		RunE:  runIdentityShow,
+	}
+
+	identityCmd.AddCommand(initCmd, showCmd)
+	RootCommand().AddCommand(identityCmd)
+}
+
+func runIdentityInit(cmd *cobra.Command, _ []string) error {
+	return updateRemoteSettings(cmd, func(settings *config.RemoteSettings) error {
+		if strings.TrimSpace(settings.Identity) != "" && !identityRotate {
+			return errors.New("已存在身份私钥，如需重新生成请使用 --rotate")
+		}
+		encoded, err := remote.GenerateIdentity()
+		if err != nil {
+			return err
+		}
+		priv, err := remote.ParseIdentity(encoded)
+		if err != nil {
+			return err
+		}
+		settings.Identity = encoded
+		fmt.Fprintln(cmd.OutOrStdout(), "✓ 已生成身份私钥，公钥:")
+		fmt.Fprintln(cmd.OutOrStdout(), remote.EncodeRecipient(priv.PublicKey()))
+		fmt.Fprintln(cmd.OutOrStdout(), "将公钥发给团队成员，由其执行 ckm remote recipients add <公钥> 后重新推送")
+		logging.Warnf("生成身份私钥, rotate=%t", identityRotate)
+		return nil
+	})
+}
+
+func runIdentityShow(cmd *cobra.Command, _ []string) error {
+	manager, err := mustLoadManager(cmd)
+	if err != nil {
+		return err
+	}
+	cfg, err := manager.Config()
+	if err != nil {
+		return err
+	}
+	if cfg.Remote == nil || strings.TrimSpace(cfg.Remote.Identity) == "" {
+		return errors.New("尚未创建身份，请执行 ckm identity init")
+	}
+	priv, err := remote.ParseIdentity(cfg.Remote.Identity)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), remote.EncodeRecipient(priv.PublicKey()))
+	return nil
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/codex-switch/codex-switch/internal/config"
@@ -17,9 +19,14 @@ import (
 )
 
 var (
-	importInput  string
-	importFormat string
-	importMerge  bool
+	importInput      string
+	importFormat     string
+	importMerge      bool
+	importStrategy   string
+	importKeepActive bool
+	importDryRun     bool
+	importForce      bool
+	importAllowHooks bool
 )
 
 func init() {
@@ -32,6 +39,11 @@ func init() {
 	importCmd.Flags().StringVar(&importInput, "input", "", "待导入的配置文件路径")
 	importCmd.Flags().StringVar(&importFormat, "format", "", "配置格式，默认根据扩展名推断")
 	importCmd.Flags().BoolVar(&importMerge, "merge", false, "是否与现有配置合并")
+	importCmd.Flags().StringVar(&importStrategy, "strategy", mergeTheirs, "合并冲突策略: ours/theirs/newest")
+	importCmd.Flags().BoolVar(&importKeepActive, "keep-active", false, "合并时保持本地激活 Key 不变")
+	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "仅输出合并报告，不写入配置")
+	importCmd.Flags().BoolVar(&importForce, "force", false, "忽略校验错误强制导入")
+	importCmd.Flags().BoolVar(&importAllowHooks, "allow-hooks", false, "覆盖导入时同时导入 hooks 与 exec 凭据命令(仅在确认来源可信时使用)")
 
 	RootCommand().AddCommand(importCmd)
 }
@@ -40,6 +52,13 @@ func runImport(cmd *cobra.Command, _ []string) error {
 	if strings.TrimSpace(importInput) == "" {
 		return errors.New("请通过 --input 指定配置文件")
 	}
+	if !importMerge {
+		for _, name := range []string{"dry-run", "strategy", "keep-active"} {
+			if cmd.Flags().Changed(name) {
+				return fmt.Errorf("--%s 仅适用于 --merge 模式", name)
+			}
+		}
+	}
 
 	data, err := os.ReadFile(importInput)
 	if err != nil {
@@ -61,16 +80,44 @@ func runImport(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 
+	current, err := manager.Config()
+	if err != nil {
+		return err
+	}
+	if err := restoreRedacted(current, cfg); err != nil {
+		return err
+	}
+	if !importMerge && !importAllowHooks {
+		if dropped := dropImportedExecutables(current, cfg); len(dropped) > 0 {
+			fmt.Fprintf(cmd.ErrOrStderr(), "⚠ 已忽略导入文件中的 %s，沿用本地配置；确认来源可信后可使用 --allow-hooks 导入\n", strings.Join(dropped, "、"))
+			logging.Warnf("导入时忽略可执行命令: %v", dropped)
+		}
+	}
+
+	issues := config.Validate(cfg)
+	printIssues(cmd.ErrOrStderr(), issues)
+	if config.HasErrors(issues) {
+		if !importForce {
+			return errors.New("导入文件校验未通过，修正后重试或使用 --force 强制导入")
+		}
+		logging.Warnf("忽略校验错误强制导入: %s", importInput)
+	}
+
 	if importMerge {
-		current, err := manager.Config()
+		opts := mergeOptions{Strategy: importStrategy, KeepActive: importKeepActive}
+		merged, report, err := mergeConfig(current, cfg, opts)
 		if err != nil {
 			return err
 		}
-		merged := mergeConfig(current, cfg)
+		report.Print(cmd.OutOrStdout())
+		if importDryRun {
+			fmt.Fprintln(cmd.OutOrStdout(), "(dry-run) 未写入任何变更")
+			return nil
+		}
 		if err := manager.ReplaceConfig(merged); err != nil {
 			return err
 		}
-		logging.Infof("合并导入配置: %s", importInput)
+		logging.Infof("合并导入配置: %s 策略: %s %s", importInput, opts.Strategy, report.Summary())
 	} else {
 		if err := manager.ReplaceConfig(cfg); err != nil {
 			return err
@@ -84,6 +131,7 @@ func runImport(cmd *cobra.Command, _ []string) error {
 
 	fmt.Fprintf(cmd.OutOrStdout(), "✓ 已导入配置，共 %d 个 Key\n", len(cfg.Keys))
 	logging.Infof("导入完成，共 %d 个 Key", len(cfg.Keys))
+	autoPush(cmd, manager)
 	return nil
 }
 
@@ -119,34 +167,159 @@ func inferFormat(path string) string {
 	}
 }
 
-func mergeConfig(base *config.Config, incoming *config.Config) *config.Config {
-	result := *base
-	result.Keys = append([]config.APIKey(nil), base.Keys...)
+// restoreRedacted 处理脱敏导出的配置：占位符密钥沿用本地同名 Key 的真实值，
+// env: 占位符在本地缺失时从环境变量解析；未包含远程配置时保留本地远程配置，
+// 旧格式的单一 remote 字段替换本地的 origin 远程，其余命名远程保持不变。
+func restoreRedacted(current *config.Config, incoming *config.Config) error {
+	byName := make(map[string]config.APIKey, len(current.Keys))
+	byID := make(map[string]config.APIKey, len(current.Keys))
+	for _, k := range current.Keys {
+		byName[strings.ToLower(k.Name)] = k
+		byID[k.ID] = k
+	}
 
-	byID := make(map[string]int)
-	byName := make(map[string]int)
-	for i, k := range result.Keys {
-		byID[k.ID] = i
-		byName[strings.ToLower(k.Name)] = i
+	var unresolved []string
+	for i := range incoming.Keys {
+		key := &incoming.Keys[i]
+		if !config.IsSecretPlaceholder(key.APIKey) {
+			continue
+		}
+		local, ok := byName[strings.ToLower(key.Name)]
+		if !ok && key.ID != "" {
+			local, ok = byID[key.ID]
+		}
+		if ok {
+			key.APIKey = local.APIKey
+			if strings.TrimSpace(key.RawConfig) == "" {
+				key.RawConfig = local.RawConfig
+			}
+			continue
+		}
+		if resolved, ok := config.ResolveEnvPlaceholder(key.APIKey); ok {
+			key.APIKey = resolved
+			continue
+		}
+		unresolved = append(unresolved, "Key "+key.Name)
 	}
 
-	for _, newKey := range incoming.Keys {
-		if idx, ok := byID[newKey.ID]; ok && newKey.ID != "" {
-			result.Keys[idx] = newKey
+	localRemotes := configRemotes(current)
+	incomingRemotes := configRemotes(incoming)
+	names := make([]string, 0, len(incomingRemotes))
+	for name := range incomingRemotes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		unresolved = append(unresolved, restoreRemoteSecrets(name, incomingRemotes[name], localRemotes[name])...)
+	}
+	if len(unresolved) > 0 {
+		return fmt.Errorf("以下密钥为占位符且无法从本地或环境变量恢复: %s", strings.Join(unresolved, ", "))
+	}
+
+	if incoming.Remote == nil && len(incoming.Remotes) == 0 {
+		if current.Remote != nil {
+			remoteCopy := *current.Remote
+			incoming.Remote = &remoteCopy
+		}
+		incoming.Remotes = cloneRemotes(current.Remotes)
+		incoming.DefaultRemote = current.DefaultRemote
+		if settings, ok := incoming.Remotes[current.RemoteName()]; ok {
+			incoming.Remote = settings
+		}
+		return nil
+	}
+	if len(incoming.Remotes) == 0 && len(current.Remotes) > 0 {
+		imported := incoming.Remote
+		incoming.Remotes = cloneRemotes(current.Remotes)
+		incoming.Remotes[config.DefaultRemoteName] = imported
+		incoming.DefaultRemote = current.DefaultRemote
+		incoming.Remote = incoming.Remotes[incoming.DefaultRemote]
+		if incoming.Remote == nil {
+			incoming.DefaultRemote = config.DefaultRemoteName
+			incoming.Remote = imported
+		}
+	}
+	return nil
+}
+
+// dropImportedExecutables 将导入配置中的 hooks 与 exec 凭据命令替换为本地配置，
+// 避免导入他人分享的文件时植入会被 ckm 自动执行的命令。本地没有同名远程时
+// 凭据改为从环境变量读取。返回被忽略的项目。
+func dropImportedExecutables(current *config.Config, incoming *config.Config) []string {
+	var dropped []string
+	if incoming.Hooks != nil && !reflect.DeepEqual(incoming.Hooks, current.Hooks) {
+		dropped = append(dropped, "hooks")
+	}
+	incoming.Hooks = current.Hooks
+
+	localRemotes := configRemotes(current)
+	remotes := configRemotes(incoming)
+	names := make([]string, 0, len(remotes))
+	for name := range remotes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		settings := remotes[name]
+		if settings.CredentialStore != config.CredentialStoreExec && settings.CredentialHelper == "" {
 			continue
 		}
-		if idx, ok := byName[strings.ToLower(newKey.Name)]; ok && newKey.Name != "" {
-			result.Keys[idx] = newKey
+		local := localRemotes[name]
+		if local != nil && local.CredentialStore == settings.CredentialStore && local.CredentialHelper == settings.CredentialHelper {
 			continue
 		}
-		result.Keys = append(result.Keys, newKey)
+		dropped = append(dropped, fmt.Sprintf("远程 %s 的凭据命令", name))
+		if local != nil {
+			settings.CredentialStore = local.CredentialStore
+			settings.CredentialHelper = local.CredentialHelper
+		} else {
+			settings.CredentialStore = config.CredentialStoreEnv
+			settings.CredentialHelper = ""
+		}
 	}
+	return dropped
+}
 
-	if incoming.ActiveKeyID != "" {
-		result.ActiveKeyID = incoming.ActiveKeyID
+// restoreRemoteSecrets 将远程配置中的占位符替换为本地同名远程的真实值，
+// 返回既无本地值也无法从环境变量解析的字段
+func restoreRemoteSecrets(name string, settings *config.RemoteSettings, localSettings *config.RemoteSettings) []string {
+	var local config.RemoteSettings
+	if localSettings != nil {
+		local = *localSettings
+	}
+	var unresolved []string
+	for _, field := range []struct {
+		label string
+		value *string
+		local string
+	}{
+		{"key_id", &settings.KeyID, local.KeyID},
+		{"application_key", &settings.ApplicationKey, local.ApplicationKey},
+		{"sync_token", &settings.SyncToken, local.SyncToken},
+		{"password", &settings.Password, local.Password},
+		{"bearer_token", &settings.BearerToken, local.BearerToken},
+		{"signing_key", &settings.SigningKey, local.SigningKey},
+		{"identity", &settings.Identity, local.Identity},
+	} {
+		value, ok := restoreSecretValue(*field.value, field.local)
+		if !ok {
+			unresolved = append(unresolved, fmt.Sprintf("远程 %s 的 %s(%s)", name, field.label, *field.value))
+		}
+		*field.value = value
+	}
+	return unresolved
+}
+
+// restoreSecretValue 若导入值为占位符，则优先沿用本地值，其次解析环境变量，均无法恢复时返回 false
+func restoreSecretValue(value string, local string) (string, bool) {
+	if !config.IsSecretPlaceholder(value) {
+		return value, true
+	}
+	if strings.TrimSpace(local) != "" {
+		return local, true
 	}
-	if incoming.Version != "" {
-		result.Version = incoming.Version
+	if resolved, ok := config.ResolveEnvPlaceholder(value); ok {
+		return resolved, true
 	}
-	return &result
+	return "", false
 }
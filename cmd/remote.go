@@ -1,19 +1,26 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
+	"crypto/ecdh"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 	"unicode"
 
 	"github.com/codex-switch/codex-switch/internal/config"
+	"github.com/codex-switch/codex-switch/internal/display"
+	"github.com/codex-switch/codex-switch/internal/hooks"
 	"github.com/codex-switch/codex-switch/internal/logging"
 	"github.com/codex-switch/codex-switch/internal/remote"
-	b2 "github.com/codex-switch/codex-switch/internal/remote/b2"
 
 	"github.com/spf13/cobra"
 )
@@ -26,6 +33,30 @@ var (
 	remotePushProfile   string
 	remotePullProfile   string
 	remoteDeleteProfile string
+	remoteNoEncrypt     bool
+	remoteProvider      string
+	remoteEndpoint      string
+	remoteRegion        string
+	remotePathStyle     bool
+	remoteURL           string
+	remoteUser          string
+	remotePassword      string
+	remoteBearerToken   string
+	remoteDirectory     string
+	remoteGitRepo       string
+	remoteGitBranch     string
+	remoteAllowPlain    bool
+	remoteHistoryLimit  int
+	remotePullVersion   string
+	remotePushForce     bool
+	remotePullForce     bool
+	remoteSkipVerify    bool
+	remoteIncludeTags   []string
+	remoteExcludeTags   []string
+	remoteAutoPush      bool
+	remoteStore         string
+	remoteName          string
+	remotePushAll       bool
 )
 
 func init() {
@@ -33,18 +64,15 @@ func init() {
 		Use:   "remote",
 		Short: "远程配置同步管理",
 	}
+	remoteCmd.PersistentFlags().BoolVar(&remoteSkipVerify, "insecure-skip-verify", false, "跳过快照签名校验(仅在确认来源可信时使用)")
+	remoteCmd.PersistentFlags().StringVar(&remoteName, "remote", "", "操作指定名称的远程，默认使用默认远程")
 
 	initCmd := &cobra.Command{
 		Use:   "init",
-		Short: "配置 Backblaze B2 同步",
+		Short: "配置远程同步 (Backblaze B2 / S3 兼容存储 / WebDAV / 本地目录 / Git)",
 		RunE:  runRemoteInit,
 	}
-	initCmd.Flags().StringVar(&remoteKeyID, "key-id", "", "Backblaze B2 Key ID")
-	initCmd.Flags().StringVar(&remoteAppKey, "app-key", "", "Backblaze B2 Application Key")
-	initCmd.Flags().StringVar(&remoteBucketName, "bucket", "", "Backblaze B2 存储桶名称")
-	initCmd.Flags().StringVar(&remoteInitProfile, "profile", "default", "远程配置档案名，用于区分不同机器/环境")
-	initCmd.Flags().StringVar(&remoteInitProfile, "storage-key", "default", "(已弃用) 远程存储标识")
-	_ = initCmd.Flags().MarkHidden("storage-key")
+	registerRemoteInitFlags(initCmd)
 
 	pushCmd := &cobra.Command{
 		Use:   "push",
@@ -52,6 +80,8 @@ func init() {
 		RunE:  runRemotePush,
 	}
 	pushCmd.Flags().StringVar(&remotePushProfile, "profile", "", "指定要上传的配置档案名")
+	pushCmd.Flags().BoolVar(&remotePushForce, "force", false, "即使远端在上次同步后已被更新也强制覆盖")
+	pushCmd.Flags().BoolVar(&remotePushAll, "all", false, "同时推送到全部已配置的远程")
 	pushCmd.Flags().StringVar(&remotePushProfile, "storage-key", "", "(已弃用) 存储标识")
 	_ = pushCmd.Flags().MarkHidden("storage-key")
 
@@ -61,36 +91,64 @@ func init() {
 		RunE:  runRemotePull,
 	}
 	pullCmd.Flags().StringVar(&remotePullProfile, "profile", "", "指定要拉取的配置档案名")
+	pullCmd.Flags().BoolVar(&remotePullForce, "force", false, "本地有未同步的修改时不输出警告")
+	pullCmd.Flags().StringVar(&remotePullVersion, "version", "", "拉取指定的历史版本，版本 ID 可通过 ckm remote history 查看")
 	pullCmd.Flags().StringVar(&remotePullProfile, "storage-key", "", "(已弃用) 存储标识")
 	_ = pullCmd.Flags().MarkHidden("storage-key")
 
 	deleteCmd := &cobra.Command{
 		Use:   "delete",
-		Short: "删除远程快照并清理本地备份",
+		Short: "删除远程快照及其历史版本并清理本地备份",
 		RunE:  runRemoteDelete,
 	}
 	deleteCmd.Flags().StringVar(&remoteDeleteProfile, "profile", "", "指定要删除的配置档案名")
 	deleteCmd.Flags().StringVar(&remoteDeleteProfile, "storage-key", "", "(已弃用) 存储标识")
 	_ = deleteCmd.Flags().MarkHidden("storage-key")
 
-	remoteCmd.AddCommand(initCmd, pushCmd, pullCmd, deleteCmd)
+	remoteCmd.AddCommand(initCmd, pushCmd, pullCmd, deleteCmd, newRemoteSyncCommand(), newRemoteHistoryCommand(), newRemoteTokenCommand(), newRemoteSigningCommand(), newRemoteFlushCommand(), newRemoteRecipientsCommand())
+	remoteCmd.AddCommand(newRemoteProfileCommands()...)
 	RootCommand().AddCommand(remoteCmd)
 }
 
+// registerRemoteInitFlags 注册 remote init 与 remotes add 共用的存储参数
+func registerRemoteInitFlags(c *cobra.Command) {
+	c.Flags().StringVar(&remoteProvider, "provider", "", "远程存储类型: b2/s3/webdav/dir/git，默认沿用现有配置或 b2")
+	c.Flags().StringVar(&remoteKeyID, "key-id", "", "B2 Key ID 或 S3 Access Key ID")
+	c.Flags().StringVar(&remoteAppKey, "app-key", "", "B2 Application Key 或 S3 Secret Access Key")
+	c.Flags().StringVar(&remoteStore, "store", "", "B2/S3 凭据保存位置: config(配置文件)/env(环境变量 CKM_<REMOTE>_KEY_ID、CKM_<REMOTE>_APP_KEY 或 CKM_B2_KEY_ID、CKM_B2_APP_KEY)/file(0600 凭据文件)/exec:<命令>")
+	c.Flags().StringVar(&remoteBucketName, "bucket", "", "存储桶名称")
+	c.Flags().StringVar(&remoteEndpoint, "endpoint", "", "S3 兼容服务地址，如 https://minio.example.com；B2 时为授权接口地址，默认 https://api.backblazeb2.com")
+	c.Flags().StringVar(&remoteRegion, "region", "", "S3 区域，默认 us-east-1")
+	c.Flags().BoolVar(&remotePathStyle, "path-style", false, "S3 使用 path-style 寻址 (MinIO 等自建服务通常需要)")
+	c.Flags().StringVar(&remoteURL, "url", "", "WebDAV 快照目录地址，如 https://cloud.example.com/remote.php/dav/files/alice/ckm/")
+	c.Flags().StringVar(&remoteUser, "user", "", "WebDAV 用户名 (Basic 鉴权)")
+	c.Flags().StringVar(&remotePassword, "password", "", "WebDAV 密码或应用专用密码")
+	c.Flags().StringVar(&remoteBearerToken, "bearer-token", "", "WebDAV Bearer 令牌，设置后优先于用户名密码")
+	c.Flags().StringVar(&remoteDirectory, "dir", "", "dir 存储使用的快照目录，如 Syncthing 同步目录或 NAS 挂载点")
+	c.Flags().StringVar(&remoteGitRepo, "repo", "", "git 存储使用的仓库地址或本地路径")
+	c.Flags().StringVar(&remoteGitBranch, "branch", "", "git 存储使用的分支，默认 ckm")
+	c.Flags().BoolVar(&remoteAllowPlain, "allow-plaintext", false, "允许 git 存储提交未加密的快照")
+	c.Flags().StringVar(&remoteInitProfile, "profile", "default", "远程配置档案名，用于区分不同机器/环境")
+	c.Flags().StringSliceVar(&remoteIncludeTags, "include-tag", nil, "仅同步带有这些标签的 Key (可重复或逗号分隔，传入空值清除)")
+	c.Flags().StringSliceVar(&remoteExcludeTags, "exclude-tag", nil, "不同步带有这些标签的 Key (可重复或逗号分隔，传入空值清除)")
+	c.Flags().StringVar(&remoteInitProfile, "storage-key", "default", "(已弃用) 远程存储标识")
+	_ = c.Flags().MarkHidden("storage-key")
+	c.Flags().BoolVar(&remoteNoEncrypt, "no-encrypt", false, "上传明文快照(不推荐)，仅用于兼容旧版本客户端")
+	c.Flags().IntVar(&remoteHistoryLimit, "history-limit", 0, "每个配置档案保留的历史版本数，默认 20，-1 表示不清理")
+	c.Flags().BoolVar(&remoteAutoPush, "auto-push", false, "add/update/remove/import 成功后自动推送快照 (--auto-push=false 关闭)")
+}
+
 // runRemoteInit 负责校验凭据并写入远程存储配置。
 func runRemoteInit(cmd *cobra.Command, _ []string) error {
-	if strings.TrimSpace(remoteKeyID) == "" || strings.TrimSpace(remoteAppKey) == "" {
-		return errors.New("必须提供 key-id 与 app-key")
-	}
-	if strings.TrimSpace(remoteBucketName) == "" {
-		return errors.New("必须提供 bucket 名称")
-	}
-
 	manager, err := mustLoadManager(cmd)
 	if err != nil {
 		return err
 	}
+	return initRemote(cmd, manager)
+}
 
+// initRemote 将 init 参数写入当前选择的远程，确认存储可用后保存配置
+func initRemote(cmd *cobra.Command, manager *config.Manager) error {
 	cfg, err := manager.Config()
 	if err != nil {
 		return err
@@ -100,35 +158,36 @@ func runRemoteInit(cmd *cobra.Command, _ []string) error {
 	if settings == nil {
 		return errors.New("配置缺失远程字段，请重新初始化配置")
 	}
-	keyID := strings.TrimSpace(remoteKeyID)
-	if keyID == "" {
-		keyID = strings.TrimSpace(settings.KeyID)
+
+	if provider := strings.ToLower(strings.TrimSpace(remoteProvider)); provider != "" {
+		settings.Provider = provider
 	}
-	appKey := strings.TrimSpace(remoteAppKey)
-	if appKey == "" {
-		appKey = strings.TrimSpace(settings.ApplicationKey)
+	settings.Provider = providerOf(settings)
+	previousStore := settings.CredentialStore
+	if cmd.Flags().Lookup("no-encrypt").Changed {
+		settings.DisableEncryption = remoteNoEncrypt
 	}
-	if keyID == "" || appKey == "" {
-		return errors.New("必须提供 key-id 与 app-key")
+	if err := applyRemoteInitFlags(cmd, settings); err != nil {
+		return err
 	}
-
-	settings.Provider = "b2"
-	settings.KeyID = keyID
-	settings.ApplicationKey = appKey
-	bucket := strings.TrimSpace(remoteBucketName)
-	if bucket == "" {
-		bucket = strings.TrimSpace(settings.BucketName)
+	if cmd.Flags().Lookup("history-limit").Changed {
+		settings.HistoryLimit = remoteHistoryLimit
+	}
+	if cmd.Flags().Lookup("auto-push").Changed {
+		settings.AutoPush = remoteAutoPush
+	}
+	if cmd.Flags().Lookup("include-tag").Changed {
+		settings.IncludeTags = normalizeTags(strings.Join(remoteIncludeTags, ","))
 	}
-	if bucket == "" {
-		return errors.New("必须提供 bucket 名称")
+	if cmd.Flags().Lookup("exclude-tag").Changed {
+		settings.ExcludeTags = normalizeTags(strings.Join(remoteExcludeTags, ","))
 	}
 
-	settings.BucketName = bucket
 	profile := normalizeProfile(remoteInitProfile, "default")
 	settings.ObjectKey = profile
 	settings.Enabled = true
 
-	client, err := b2.NewClient(settings)
+	backend, err := newRemoteBackend(settings)
 	if err != nil {
 		return err
 	}
@@ -136,7 +195,7 @@ func runRemoteInit(cmd *cobra.Command, _ []string) error {
 	ctx, cancel := context.WithTimeout(cmd.Context(), 30*time.Second)
 	defer cancel()
 
-	if err := client.Prepare(ctx); err != nil {
+	if err := backend.Prepare(ctx); err != nil {
 		return err
 	}
 
@@ -147,15 +206,159 @@ func runRemoteInit(cmd *cobra.Command, _ []string) error {
 	if err := manager.Save(); err != nil {
 		return err
 	}
+	// 凭据已迁出凭据文件时删除旧文件，避免残留明文
+	if previousStore == config.CredentialStoreFile && settings.CredentialStore != config.CredentialStoreFile {
+		if err := os.Remove(credentialsPath(settings)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			logging.Warnf("删除旧凭据文件失败: %v", err)
+		}
+	}
 
-	fmt.Fprintf(cmd.OutOrStdout(), "✓ 已完成远程配置，目标桶: %s\n", settings.BucketName)
+	fmt.Fprintf(cmd.OutOrStdout(), "✓ 已完成远程配置，存储: %s，位置: %s\n", providerLabel(settings), remoteLocation(settings))
 	fmt.Fprintf(cmd.OutOrStdout(), "接下来可执行: ckm remote push --profile %s\n", profile)
 	fmt.Fprintf(cmd.OutOrStdout(), "其他机器执行: ckm remote pull --profile %s\n", profile)
-	logging.Infof("初始化远程同步: bucket=%s profile=%s", settings.BucketName, profile)
+	logging.Infof("初始化远程同步: provider=%s location=%s profile=%s", settings.Provider, remoteLocation(settings), profile)
+	return nil
+}
+
+// applyRemoteInitFlags 将 init 参数写入远程配置，未提供的参数沿用已有值。
+func applyRemoteInitFlags(cmd *cobra.Command, settings *config.RemoteSettings) error {
+	switch settings.Provider {
+	case remote.ProviderB2, remote.ProviderS3:
+		if err := storeRemoteCredentials(cmd, settings); err != nil {
+			return err
+		}
+		if bucket := strings.TrimSpace(remoteBucketName); bucket != "" && bucket != settings.BucketName {
+			settings.BucketName = bucket
+			settings.BucketID = ""
+		}
+		setIfProvided(&settings.Endpoint, remoteEndpoint)
+		if settings.Provider == remote.ProviderS3 {
+			setIfProvided(&settings.Region, remoteRegion)
+			if cmd.Flags().Lookup("path-style").Changed {
+				settings.PathStyle = remotePathStyle
+			}
+		}
+		if strings.TrimSpace(settings.BucketName) == "" {
+			return errors.New("必须提供 bucket 名称")
+		}
+	case remote.ProviderWebDAV:
+		setIfProvided(&settings.URL, remoteURL)
+		setIfProvided(&settings.Username, remoteUser)
+		setIfProvided(&settings.Password, remotePassword)
+		setIfProvided(&settings.BearerToken, remoteBearerToken)
+		if strings.TrimSpace(settings.URL) == "" {
+			return errors.New("必须通过 --url 提供 WebDAV 地址")
+		}
+		if strings.TrimSpace(settings.BearerToken) == "" && strings.TrimSpace(settings.Username) == "" {
+			return errors.New("必须提供 --user/--password 或 --bearer-token")
+		}
+	case remote.ProviderDir:
+		if dir := strings.TrimSpace(remoteDirectory); dir != "" {
+			abs, err := resolvePath(dir)
+			if err != nil {
+				return fmt.Errorf("解析快照目录失败: %w", err)
+			}
+			settings.Directory = abs
+		}
+		if strings.TrimSpace(settings.Directory) == "" {
+			return errors.New("必须通过 --dir 提供快照目录")
+		}
+	case remote.ProviderGit:
+		if repo := strings.TrimSpace(remoteGitRepo); repo != "" {
+			// 本地路径转换为绝对路径，远程地址保持原样
+			if abs, err := resolvePath(repo); err == nil {
+				if _, statErr := os.Stat(abs); statErr == nil {
+					repo = abs
+				}
+			}
+			settings.Repo = repo
+		}
+		setIfProvided(&settings.Branch, remoteGitBranch)
+		if cmd.Flags().Lookup("allow-plaintext").Changed {
+			settings.AllowPlaintext = remoteAllowPlain
+		}
+		if strings.TrimSpace(settings.Repo) == "" {
+			return errors.New("必须通过 --repo 提供 Git 仓库")
+		}
+		if settings.DisableEncryption && !settings.AllowPlaintext {
+			return errors.New("Git 存储默认拒绝提交明文快照，如确需关闭加密请同时指定 --allow-plaintext")
+		}
+	default:
+		return fmt.Errorf("不支持的远程存储类型: %s", settings.Provider)
+	}
+	return nil
+}
+
+// storeRemoteCredentials 合并 --key-id/--app-key 与已保存的凭据，按 --store 写入对应位置，
+// 切换存储位置时已有凭据随之迁移，最后确认能够解析出完整凭据。
+func storeRemoteCredentials(cmd *cobra.Command, settings *config.RemoteSettings) error {
+	path := credentialsPath(settings)
+	creds, _ := config.ResolveRemoteCredentials(settings, path)
+	setIfProvided(&creds.KeyID, remoteKeyID)
+	setIfProvided(&creds.ApplicationKey, remoteAppKey)
+
+	if cmd.Flags().Lookup("store").Changed {
+		store, helper, err := config.ParseCredentialStore(remoteStore)
+		if err != nil {
+			return err
+		}
+		settings.CredentialStore = store
+		settings.CredentialHelper = helper
+	}
+
+	switch settings.CredentialStore {
+	case config.CredentialStoreFile:
+		if err := config.SaveCredentialsFile(path, creds); err != nil {
+			return fmt.Errorf("写入凭据文件失败: %w", err)
+		}
+		settings.KeyID, settings.ApplicationKey = "", ""
+	case config.CredentialStoreEnv, config.CredentialStoreExec:
+		if remoteKeyID != "" || remoteAppKey != "" {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%s 凭据存储方式为 %s，--key-id/--app-key 不会被保存\n", display.ColorWarning.Sprint("⚠"), settings.CredentialStore)
+		}
+		settings.KeyID, settings.ApplicationKey = "", ""
+		resolved, err := config.ResolveRemoteCredentials(settings, path)
+		if err != nil {
+			return err
+		}
+		creds = resolved
+	default:
+		settings.KeyID, settings.ApplicationKey = creds.KeyID, creds.ApplicationKey
+	}
+
+	if !creds.Complete() {
+		return errors.New("必须提供 key-id 与 app-key")
+	}
+	settings.SetAccessCredentials(creds)
 	return nil
 }
 
-// runRemotePush 将当前配置快照保存为 JSON 并同步到 B2。
+// uploadSnapshot 先写入不可变的历史版本，再更新最新快照，避免失败时丢失唯一副本；
+// 完成后按保留数量清理旧版本，返回本次的版本 ID。
+func uploadSnapshot(ctx context.Context, backend remote.Backend, settings *config.RemoteSettings, profile string, data []byte) (string, error) {
+	versionID := remote.NewVersionID(time.Now())
+	if err := backend.Upload(ctx, remote.VersionObjectName(profile, versionID), data); err != nil {
+		return "", err
+	}
+	if err := backend.Upload(ctx, buildRemoteObjectName(settings, profile), data); err != nil {
+		return "", err
+	}
+	if removed, err := remote.PruneVersions(ctx, backend, profile, settings.HistoryLimit); err != nil {
+		logging.Warnf("清理历史版本失败: %v", err)
+	} else if len(removed) > 0 {
+		logging.Infof("已清理 %d 个历史版本: %s", len(removed), strings.Join(removed, ", "))
+	}
+	return versionID, nil
+}
+
+// setIfProvided 仅在参数非空时覆盖目标字段
+func setIfProvided(target *string, value string) {
+	if trimmed := strings.TrimSpace(value); trimmed != "" {
+		*target = trimmed
+	}
+}
+
+// runRemotePush 将当前配置快照加密后同步到远程存储。
 func runRemotePush(cmd *cobra.Command, _ []string) error {
 	manager, err := mustLoadManager(cmd)
 	if err != nil {
@@ -167,6 +370,13 @@ func runRemotePush(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 
+	if remotePushAll {
+		if cmd.Flags().Lookup("remote").Changed {
+			return errors.New("--all 与 --remote 不能同时使用")
+		}
+		return pushAllRemotes(cmd, manager, cfg)
+	}
+
 	settings := cfg.Remote
 	if settings == nil || !settings.Enabled {
 		return errors.New("未配置远程同步，请先执行 ckm remote init")
@@ -177,49 +387,168 @@ func runRemotePush(cmd *cobra.Command, _ []string) error {
 		return errors.New("未指定有效的配置档案名")
 	}
 
-	objectName := buildRemoteObjectName(settings, profile)
+	ctx, cancel := context.WithTimeout(cmd.Context(), 60*time.Second)
+	defer cancel()
 
-	snapshot := remote.BuildSnapshot(cfg)
-	data, err := snapshot.Marshal()
+	result, err := pushProfile(ctx, cmd.ErrOrStderr(), manager, cfg, profile, remotePushForce)
 	if err != nil {
+		if errors.Is(err, errRemoteAhead) {
+			cmd.SilenceUsage = true
+		}
 		return err
 	}
 
-	localPath := buildSnapshotPath(manager.ConfigPath(), profile)
-	if err := remote.SaveSnapshotFile(localPath, snapshot); err != nil {
-		return err
-	}
+	fmt.Fprintf(cmd.OutOrStdout(), "✓ 已上传快照至 %s 对象: %s (版本 %s)\n", providerLabel(settings), result.objectName, result.versionID)
+	fmt.Fprintf(cmd.OutOrStdout(), "本地快照路径: %s\n", result.localPath)
+	logging.Infof("推送远程快照: object=%s version=%s profile=%s", result.objectName, result.versionID, profile)
+	return nil
+}
 
-	client, err := b2.NewClient(settings)
-	if err != nil {
-		return err
+// pushAllRemotes 并发推送到全部已启用的远程，逐个输出结果；
+// 各远程默认推送其自身的配置档案，--profile 可统一指定。
+func pushAllRemotes(cmd *cobra.Command, manager *config.Manager, cfg *config.Config) error {
+	var names []string
+	for _, name := range cfg.RemoteNames() {
+		if settings := cfg.Remotes[name]; settings != nil && settings.Enabled {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return errors.New("未配置远程同步，请先执行 ckm remote init")
 	}
 
-	ctx, cancel := context.WithTimeout(cmd.Context(), 60*time.Second)
+	ctx, cancel := context.WithTimeout(cmd.Context(), 120*time.Second)
 	defer cancel()
 
-	if err := client.Prepare(ctx); err != nil {
-		return err
+	// 各远程的差异提示写入独立缓冲区，避免并发输出交错
+	results := make([]pushResult, len(names))
+	errs := make([]error, len(names))
+	diffs := make([]bytes.Buffer, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		view := *cfg
+		if err := view.SelectRemote(name); err != nil {
+			return err
+		}
+		profile := normalizeProfile(remotePushProfile, view.Remote.ObjectKey)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = uploadProfile(ctx, &diffs[i], manager.ConfigPath(), &view, profile, remotePushForce)
+		}()
+	}
+	wg.Wait()
+
+	out := cmd.OutOrStdout()
+	failed := 0
+	for i, name := range names {
+		settings := cfg.Remotes[name]
+		if errs[i] != nil {
+			failed++
+			cmd.ErrOrStderr().Write(diffs[i].Bytes())
+			fmt.Fprintf(out, "%s %s: 推送失败: %v\n", display.ColorWarning.Sprint("⚠"), name, errs[i])
+			logging.Warnf("推送远程快照失败: remote=%s err=%v", name, errs[i])
+			continue
+		}
+		markPushed(settings, results[i])
+		fmt.Fprintf(out, "✓ %s (%s): 已上传对象 %s (版本 %s)\n", name, providerLabel(settings), results[i].objectName, results[i].versionID)
+		logging.Infof("推送远程快照: remote=%s object=%s version=%s profile=%s", name, results[i].objectName, results[i].versionID, results[i].profile)
 	}
-	if err := client.Upload(ctx, objectName, data); err != nil {
-		return err
+
+	if failed < len(names) {
+		if err := manager.ReplaceConfig(cfg); err != nil {
+			return err
+		}
+		if err := manager.Save(); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d/%d 个远程未能推送", failed, len(names))
 	}
+	return nil
+}
 
-	settings.ObjectKey = profile
-	settings.Enabled = true
-	settings.LastSync = time.Now().UTC()
+// pushResult 描述一次成功推送的结果
+type pushResult struct {
+	profile    string
+	objectName string
+	versionID  string
+	localPath  string
+	hash       string
+}
 
+// pushProfile 构建快照并上传到 profile，成功后更新本地同步基准与配置。
+// force 为 false 时先确认远端自上次同步后未被更新，差异输出到 out。
+func pushProfile(ctx context.Context, out io.Writer, manager *config.Manager, cfg *config.Config, profile string, force bool) (pushResult, error) {
+	result, err := uploadProfile(ctx, out, manager.ConfigPath(), cfg, profile, force)
+	if err != nil {
+		return pushResult{}, err
+	}
+	markPushed(cfg.Remote, result)
 	if err := manager.ReplaceConfig(cfg); err != nil {
-		return err
+		return pushResult{}, err
 	}
 	if err := manager.Save(); err != nil {
-		return err
+		return pushResult{}, err
 	}
+	return result, nil
+}
 
-	fmt.Fprintf(cmd.OutOrStdout(), "✓ 已上传快照至 B2 对象: %s\n", objectName)
-	fmt.Fprintf(cmd.OutOrStdout(), "本地快照路径: %s\n", localPath)
-	logging.Infof("推送远程快照: object=%s profile=%s", objectName, profile)
-	return nil
+// markPushed 记录推送成功后的同步状态，由调用方负责保存配置
+func markPushed(settings *config.RemoteSettings, result pushResult) {
+	settings.ObjectKey = result.profile
+	settings.Enabled = true
+	settings.LastSync = time.Now().UTC()
+	settings.LastSyncHash = result.hash
+}
+
+// uploadProfile 构建 cfg.Remote 对应远程的快照并上传，成功后写入本地同步基准；
+// 不修改配置，可对不同远程并发调用。
+func uploadProfile(ctx context.Context, out io.Writer, cfgPath string, cfg *config.Config, profile string, force bool) (pushResult, error) {
+	settings := cfg.Remote
+	objectName := buildRemoteObjectName(settings, profile)
+
+	snapshot := remote.BuildSnapshot(cfg)
+	codec, err := profileCodec(settings, profile)
+	if err != nil {
+		return pushResult{}, err
+	}
+	data, err := codec.Encode(snapshot)
+	if err != nil {
+		return pushResult{}, err
+	}
+
+	backend, err := newRemoteBackend(settings)
+	if err != nil {
+		return pushResult{}, err
+	}
+	if err := backend.Prepare(ctx); err != nil {
+		return pushResult{}, err
+	}
+	if !force {
+		if err := ensureRemoteUnchanged(ctx, out, backend, settings, objectName, remote.SyncableKeys(cfg.Keys, settings)); err != nil {
+			return pushResult{}, err
+		}
+	}
+	versionID, err := uploadSnapshot(ctx, backend, settings, profile, data)
+	if err != nil {
+		return pushResult{}, err
+	}
+
+	// 上传成功后再更新本地快照，使其始终代表最近一次同步的内容
+	localPath := buildSnapshotPath(cfgPath, settings, profile)
+	if err := codec.SaveSnapshotFile(localPath, snapshot); err != nil {
+		return pushResult{}, err
+	}
+	return pushResult{
+		profile:    profile,
+		objectName: objectName,
+		versionID:  versionID,
+		localPath:  localPath,
+		hash:       snapshot.ContentHash(),
+	}, nil
 }
 
 // runRemotePull 下载远端快照并覆盖本地配置，同时生成备份。
@@ -245,8 +574,11 @@ func runRemotePull(cmd *cobra.Command, _ []string) error {
 	}
 
 	objectName := buildRemoteObjectName(settings, profile)
+	if version := strings.TrimSpace(remotePullVersion); version != "" && version != "latest" {
+		objectName = remote.VersionObjectName(profile, version)
+	}
 
-	client, err := b2.NewClient(settings)
+	backend, err := newRemoteBackend(settings)
 	if err != nil {
 		return err
 	}
@@ -254,29 +586,38 @@ func runRemotePull(cmd *cobra.Command, _ []string) error {
 	ctx, cancel := context.WithTimeout(cmd.Context(), 60*time.Second)
 	defer cancel()
 
-	if err := client.Prepare(ctx); err != nil {
+	if err := backend.Prepare(ctx); err != nil {
 		return err
 	}
-	data, err := client.Download(ctx, objectName)
+	data, err := backend.Download(ctx, objectName)
 	if err != nil {
+		if errors.Is(err, remote.ErrNotFound) && objectName != buildRemoteObjectName(settings, profile) {
+			return fmt.Errorf("历史版本 %s 不存在，可执行 ckm remote history 查看可用版本", remotePullVersion)
+		}
 		return err
 	}
 
-	snap, err := remote.UnmarshalSnapshot(data)
+	snap, err := snapshotCodec(settings).Decode(data)
 	if err != nil {
-		return err
+		return decodeError(err)
 	}
 
-	localPath := buildSnapshotPath(manager.ConfigPath(), profile)
-	if err := remote.SaveSnapshotFile(localPath, snap); err != nil {
+	if !remotePullForce {
+		warnLocalChanges(cmd.ErrOrStderr(), settings, remote.SyncableKeys(cfg.Keys, settings), snap.Keys)
+	}
+
+	localPath := buildSnapshotPath(manager.ConfigPath(), settings, profile)
+	if err := snapshotCodec(settings).SaveSnapshotFile(localPath, snap); err != nil {
 		return err
 	}
 
-	cfg.Keys = snap.Keys
+	// 不参与同步的本地 Key 不在远端快照中，拉取时保留
+	cfg.Keys = remote.KeepLocalKeys(cfg.Keys, snap.Keys, settings)
 	cfg.ActiveKeyID = snap.ActiveKeyID
 	settings.ObjectKey = profile
 	settings.Enabled = true
 	settings.LastSync = time.Now().UTC()
+	settings.LastSyncHash = snap.ContentHash()
 
 	if err := manager.ReplaceConfig(cfg); err != nil {
 		return err
@@ -285,13 +626,19 @@ func runRemotePull(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 
-	fmt.Fprintf(cmd.OutOrStdout(), "✓ 已从 B2 拉取快照并更新本地配置\n")
+	fmt.Fprintf(cmd.OutOrStdout(), "✓ 已从 %s 拉取快照并更新本地配置\n", providerLabel(settings))
 	fmt.Fprintf(cmd.OutOrStdout(), "本地快照: %s\n", localPath)
 	logging.Infof("拉取远程快照: object=%s profile=%s", objectName, profile)
+
+	payload := hooks.Payload{Event: config.HookPostPull, Profile: profile, Remote: settings.Name()}
+	if active, err := manager.ActiveKey(); err == nil {
+		payload.Key = hooks.NewKeyInfo(active)
+	}
+	runPostHook(cmd, manager, payload)
 	return nil
 }
 
-// runRemoteDelete 删除远程 B2 快照并清理本地备份。
+// runRemoteDelete 删除远程快照并清理本地备份。
 func runRemoteDelete(cmd *cobra.Command, _ []string) error {
 	manager, err := mustLoadManager(cmd)
 	if err != nil {
@@ -315,7 +662,7 @@ func runRemoteDelete(cmd *cobra.Command, _ []string) error {
 
 	objectName := buildRemoteObjectName(settings, profile)
 
-	client, err := b2.NewClient(settings)
+	backend, err := newRemoteBackend(settings)
 	if err != nil {
 		return err
 	}
@@ -323,21 +670,23 @@ func runRemoteDelete(cmd *cobra.Command, _ []string) error {
 	ctx, cancel := context.WithTimeout(cmd.Context(), 30*time.Second)
 	defer cancel()
 
-	if err := client.Prepare(ctx); err != nil {
+	if err := backend.Prepare(ctx); err != nil {
 		return err
 	}
 
-	if err := client.Delete(ctx, objectName); err != nil {
+	versions, err := remote.DeleteProfile(ctx, backend, profile)
+	if err != nil {
 		return err
 	}
 
-	localPath := buildSnapshotPath(manager.ConfigPath(), profile)
+	localPath := buildSnapshotPath(manager.ConfigPath(), settings, profile)
 	removeLocalSnapshot(localPath)
 
 	if settings.ObjectKey == profile {
 		settings.Enabled = false
 		settings.ObjectKey = ""
 		settings.LastSync = time.Time{}
+		settings.LastSyncHash = ""
 	}
 
 	if err := manager.ReplaceConfig(cfg); err != nil {
@@ -347,11 +696,94 @@ func runRemoteDelete(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 
-	fmt.Fprintf(cmd.OutOrStdout(), "✓ 已删除远程快照并清理本地备份\n")
+	fmt.Fprintf(cmd.OutOrStdout(), "✓ 已删除远程快照及 %d 个历史版本并清理本地备份\n", versions)
 	logging.Infof("删除远程快照: object=%s profile=%s", objectName, profile)
 	return nil
 }
 
+// snapshotCodec 根据远程配置构建快照编解码器，签名密钥无效时回退到 HMAC 签名
+func snapshotCodec(settings *config.RemoteSettings) remote.Codec {
+	codec := remote.Codec{
+		SyncToken:  settings.SyncToken,
+		Plaintext:  settings.DisableEncryption,
+		SkipVerify: remoteSkipVerify,
+	}
+	if strings.TrimSpace(settings.SigningKey) != "" {
+		if priv, err := remote.ParseSigningKey(settings.SigningKey); err != nil {
+			logging.Warnf("忽略无效的签名私钥: %v", err)
+		} else {
+			codec.SigningKey = priv
+		}
+	}
+	if strings.TrimSpace(settings.Identity) != "" {
+		if priv, err := remote.ParseIdentity(settings.Identity); err != nil {
+			logging.Warnf("忽略无效的身份私钥: %v", err)
+		} else {
+			codec.Identity = priv
+		}
+	}
+	for _, encoded := range settings.TrustedSigners {
+		pub, err := remote.ParsePublicKey(encoded)
+		if err != nil {
+			logging.Warnf("忽略无效的信任公钥: %v", err)
+			continue
+		}
+		codec.TrustedKeys = append(codec.TrustedKeys, pub)
+	}
+	return codec
+}
+
+// profileCodec 返回用于上传 profile 的编解码器：档案配置了接收者时按接收者公钥加密，
+// 并自动加入本机身份，保证推送端自己也能解密。
+func profileCodec(settings *config.RemoteSettings, profile string) (remote.Codec, error) {
+	codec := snapshotCodec(settings)
+	encoded := settings.Recipients[profile]
+	if len(encoded) == 0 {
+		return codec, nil
+	}
+	if codec.Identity == nil {
+		return remote.Codec{}, fmt.Errorf("配置档案 %s 已配置接收者，请先执行 ckm identity init 创建本机身份", profile)
+	}
+	if codec.SigningKey == nil {
+		return remote.Codec{}, fmt.Errorf("配置档案 %s 已配置接收者: %w", profile, remote.ErrSigningKeyRequired)
+	}
+
+	seen := make(map[string]bool, len(encoded)+1)
+	for _, pub := range append([]*ecdh.PublicKey{codec.Identity.PublicKey()}, parseRecipients(encoded)...) {
+		fp := remote.RecipientFingerprint(pub)
+		if !seen[fp] {
+			seen[fp] = true
+			codec.Recipients = append(codec.Recipients, pub)
+		}
+	}
+	return codec, nil
+}
+
+// parseRecipients 解析接收者公钥，无效项记录警告后忽略
+func parseRecipients(encoded []string) []*ecdh.PublicKey {
+	keys := make([]*ecdh.PublicKey, 0, len(encoded))
+	for _, text := range encoded {
+		pub, err := remote.ParseRecipient(text)
+		if err != nil {
+			logging.Warnf("忽略无效的接收者公钥: %v", err)
+			continue
+		}
+		keys = append(keys, pub)
+	}
+	return keys
+}
+
+// recipientsOutdated 判断远端快照的接收者是否与当前配置不一致，需要重新加密
+func recipientsOutdated(data []byte, codec remote.Codec) bool {
+	sealed := remote.SealedRecipients(data)
+	want := make([]string, 0, len(codec.Recipients))
+	for _, pub := range codec.Recipients {
+		want = append(want, remote.RecipientFingerprint(pub))
+	}
+	sort.Strings(want)
+	return !slices.Equal(sealed, want)
+}
+
 // normalizeProfile 统一 profile 的命名，过滤非法字符。
 func normalizeProfile(input string, fallback string) string {
 	candidate := strings.TrimSpace(input)
@@ -381,16 +813,24 @@ func normalizeProfile(input string, fallback string) string {
 	return result
 }
 
-// buildRemoteObjectName 根据同步令牌与 profile 生成远端对象名。
+// buildRemoteObjectName 根据同步令牌与 profile 生成最新快照的远端对象名。
 func buildRemoteObjectName(settings *config.RemoteSettings, profile string) string {
-	return fmt.Sprintf("%s.json", profile)
+	return remote.LatestObjectName(profile)
 }
 
 // buildSnapshotPath 返回本地快照的存储路径，便于审计与备份。
-func buildSnapshotPath(cfgPath string, profile string) string {
-	base := filepath.Dir(cfgPath)
-	dir := filepath.Join(base, "snapshots")
-	return filepath.Join(dir, fmt.Sprintf("%s.json", profile))
+// 默认远程 origin 沿用 snapshots/<profile>.json，其余远程位于 snapshots/<远程名称>/ 下。
+func buildSnapshotPath(cfgPath string, settings *config.RemoteSettings, profile string) string {
+	return filepath.Join(snapshotDir(cfgPath, settings.Name()), fmt.Sprintf("%s.json", profile))
+}
+
+// snapshotDir 返回指定远程的本地快照目录
+func snapshotDir(cfgPath string, name string) string {
+	dir := filepath.Join(filepath.Dir(cfgPath), "snapshots")
+	if name == config.DefaultRemoteName {
+		return dir
+	}
+	return filepath.Join(dir, name)
 }
 
 func removeLocalSnapshot(path string) {
@@ -401,3 +841,15 @@ func removeLocalSnapshot(path string) {
 		logging.Warnf("删除本地快照失败: %v", err)
 	}
 }
+
+// decodeError 为快照解密失败补充提示：SyncToken 加密的快照需设置一致的 SyncToken，
+// 按接收者加密的快照需确认本机身份位于推送端的接收者列表中
+func decodeError(err error) error {
+	switch {
+	case errors.Is(err, remote.ErrDecrypt):
+		return fmt.Errorf("%w，请执行 ckm remote token set <TOKEN> 使用与推送端一致的 SyncToken", err)
+	case errors.Is(err, remote.ErrRecipientDecrypt), errors.Is(err, remote.ErrNotRecipient):
+		return fmt.Errorf("%w，请执行 ckm identity show 确认本机公钥，并让推送端通过 ckm remote recipients list 检查接收者列表", err)
+	}
+	return err
+}
@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+	"github.com/codex-switch/codex-switch/internal/display"
+	"github.com/codex-switch/codex-switch/internal/logging"
+	"github.com/codex-switch/codex-switch/internal/remote"
+
+	"github.com/spf13/cobra"
+)
+
+// autoPushTimeout 为自动推送的超时时间，避免网络异常时长时间阻塞命令
+const autoPushTimeout = 20 * time.Second
+
+// newRemoteFlushCommand 构建 remote flush 子命令
+func newRemoteFlushCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "flush",
+		Short: "立即重试待推送队列中自动推送失败的快照",
+		Args:  cobra.NoArgs,
+		RunE:  runRemoteFlush,
+	}
+}
+
+func runRemoteFlush(cmd *cobra.Command, _ []string) error {
+	manager, err := mustLoadManager(cmd)
+	if err != nil {
+		return err
+	}
+	box, err := remote.LoadOutbox(outboxPath(manager.ConfigPath()))
+	if err != nil {
+		return fmt.Errorf("读取待推送队列失败: %w", err)
+	}
+	out := cmd.OutOrStdout()
+	if len(box.Entries) == 0 {
+		fmt.Fprintln(out, "待推送队列为空")
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), 120*time.Second)
+	defer cancel()
+
+	failed := 0
+	for _, entry := range box.Entries {
+		cfg, err := entryConfig(manager, entry)
+		if err != nil {
+			failed++
+			fmt.Fprintf(out, "%s %s: %v\n", display.ColorWarning.Sprint("⚠"), entry.Profile, err)
+			continue
+		}
+		label := cfg.RemoteName() + "/" + entry.Profile
+		result, err := pushAndRecord(ctx, manager, cfg, entry.Profile)
+		switch {
+		case err == nil:
+			fmt.Fprintf(out, "✓ %s: 已推送 (版本 %s)\n", label, result.versionID)
+		case errors.Is(err, errRemoteAhead):
+			failed++
+			fmt.Fprintf(out, "%s %s: 远端快照已被其他设备更新，请执行 ckm remote sync --remote %s --profile %s 合并\n", display.ColorWarning.Sprint("⚠"), label, cfg.RemoteName(), entry.Profile)
+		default:
+			failed++
+			fmt.Fprintf(out, "%s %s: 推送失败: %v\n", display.ColorWarning.Sprint("⚠"), label, err)
+		}
+	}
+	if failed > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d 个配置档案未能推送", failed)
+	}
+	return nil
+}
+
+// autoPush 在修改配置的命令保存成功后调用：向每个启用 auto_push 的远程推送最新快照，
+// 每个远程输出一行状态，随后重试待推送队列中的其余记录。推送失败不影响命令结果，
+// 快照会加入待推送队列，在下次修改配置或执行 ckm remote flush 时重试。
+// 只读命令不会触发推送，避免 list、show 等命令因网络请求阻塞。
+func autoPush(cmd *cobra.Command, manager *config.Manager) {
+	current, err := manager.Config()
+	if err != nil {
+		logging.Warnf("自动推送读取配置失败: %v", err)
+		return
+	}
+	var names []string
+	for _, name := range current.RemoteNames() {
+		if settings := current.Remotes[name]; settings != nil && settings.Enabled && settings.AutoPush {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+	// 推送过程中会切换所选远程，结束后恢复，避免影响命令后续逻辑
+	defer func() {
+		if err := manager.UseRemote(current.RemoteName()); err != nil {
+			logging.Warnf("恢复所选远程失败: %v", err)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), autoPushTimeout)
+	defer cancel()
+
+	out := cmd.OutOrStdout()
+	attempted := make(map[[2]string]bool, len(names))
+	for _, name := range names {
+		cfg, err := manager.Config()
+		if err != nil {
+			logging.Warnf("自动推送读取配置失败: %v", err)
+			return
+		}
+		if err := cfg.SelectRemote(name); err != nil {
+			logging.Warnf("自动推送选择远程失败: %v", err)
+			continue
+		}
+		profile := normalizeProfile("", cfg.Remote.ObjectKey)
+		label := providerLabel(cfg.Remote)
+		if len(names) > 1 {
+			label = name + " (" + label + ")"
+		}
+		attempted[[2]string{name, profile}] = true
+		result, err := pushAndRecord(ctx, manager, cfg, profile)
+		switch {
+		case err == nil:
+			fmt.Fprintf(out, "↑ 已自动推送至 %s (版本 %s)\n", label, result.versionID)
+		case errors.Is(err, errRemoteAhead):
+			fmt.Fprintf(out, "%s %s 的远端快照已被其他设备更新，未自动推送，请执行 ckm remote sync --remote %s 合并\n", display.ColorWarning.Sprint("⚠"), label, name)
+		default:
+			fmt.Fprintf(out, "%s 自动推送至 %s 失败，已加入待推送队列，可执行 ckm remote flush 重试: %v\n", display.ColorWarning.Sprint("⚠"), label, err)
+		}
+	}
+	retryQueued(ctx, cmd, manager, attempted)
+}
+
+// entryConfig 返回选中待推送记录所属远程的配置，旧版本记录推送到默认远程
+func entryConfig(manager *config.Manager, entry remote.OutboxEntry) (*config.Config, error) {
+	cfg, err := manager.Config()
+	if err != nil {
+		return nil, err
+	}
+	name := entry.Remote
+	if name == "" {
+		name = cfg.DefaultRemote
+	}
+	if err := cfg.SelectRemote(name); err != nil {
+		return nil, err
+	}
+	if !cfg.Remote.Enabled {
+		return nil, fmt.Errorf("远程 %s 未启用同步，请先执行 ckm remote init --remote %s", name, name)
+	}
+	return cfg, nil
+}
+
+// pushAndRecord 将 profile 推送到 cfg 所选的远程并更新待推送队列：成功或远端已更新时移除记录，
+// 其余失败加入队列。远端已更新需要人工合并，重试无意义，因此不入队。
+func pushAndRecord(ctx context.Context, manager *config.Manager, cfg *config.Config, profile string) (pushResult, error) {
+	name := cfg.RemoteName()
+	result, err := pushProfile(ctx, io.Discard, manager, cfg, profile, false)
+
+	path := outboxPath(manager.ConfigPath())
+	box, loadErr := remote.LoadOutbox(path)
+	if loadErr != nil {
+		logging.Warnf("读取待推送队列失败: %v", loadErr)
+		return result, err
+	}
+	if err == nil || errors.Is(err, errRemoteAhead) {
+		box.Remove(name, profile)
+		// 旧版本记录未保存远程名称，推送到默认远程成功后一并移除
+		if name == cfg.DefaultRemote {
+			box.Remove("", profile)
+		}
+	} else {
+		box.Queue(name, profile, err)
+	}
+	if saveErr := box.Save(path); saveErr != nil {
+		logging.Warnf("写入待推送队列失败: %v", saveErr)
+	}
+
+	if err != nil {
+		logging.Warnf("自动推送失败: remote=%s profile=%s err=%v", name, profile, err)
+	} else {
+		logging.Infof("自动推送远程快照: remote=%s profile=%s version=%s", name, profile, result.versionID)
+	}
+	return result, err
+}
+
+// retryQueued 在自动推送后重试待推送队列中其余的记录，仅处理仍启用 auto_push 的远程；
+// attempted 为本次已推送过的记录，不再重复尝试。与自动推送共用超时，避免长时间阻塞命令。
+func retryQueued(ctx context.Context, cmd *cobra.Command, manager *config.Manager, attempted map[[2]string]bool) {
+	box, err := remote.LoadOutbox(outboxPath(manager.ConfigPath()))
+	if err != nil || len(box.Entries) == 0 {
+		return
+	}
+
+	pushed, pending := 0, 0
+	var lastErr error
+	for _, entry := range box.Entries {
+		if attempted[[2]string{entry.Remote, entry.Profile}] {
+			continue
+		}
+		cfg, err := entryConfig(manager, entry)
+		if err != nil || !cfg.Remote.AutoPush {
+			continue
+		}
+		if _, err := pushAndRecord(ctx, manager, cfg, entry.Profile); err != nil {
+			lastErr = err
+			pending++
+			continue
+		}
+		pushed++
+	}
+
+	out := cmd.ErrOrStderr()
+	if pushed > 0 {
+		fmt.Fprintf(out, "↑ 已推送此前失败的 %d 个快照\n", pushed)
+	}
+	if lastErr != nil {
+		fmt.Fprintf(out, "%s 仍有 %d 个快照待推送，可执行 ckm remote flush 查看: %v\n", display.ColorWarning.Sprint("⚠"), pending, lastErr)
+	}
+}
+
+// outboxPath 返回待推送队列文件路径，与配置文件位于同一目录
+func outboxPath(cfgPath string) string {
+	return filepath.Join(filepath.Dir(cfgPath), "outbox.json")
+}
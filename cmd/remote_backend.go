@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+	"github.com/codex-switch/codex-switch/internal/remote"
+	b2 "github.com/codex-switch/codex-switch/internal/remote/b2"
+	dirremote "github.com/codex-switch/codex-switch/internal/remote/dir"
+	gitremote "github.com/codex-switch/codex-switch/internal/remote/git"
+	s3 "github.com/codex-switch/codex-switch/internal/remote/s3"
+	webdav "github.com/codex-switch/codex-switch/internal/remote/webdav"
+
+	"github.com/spf13/viper"
+)
+
+// newRemoteBackend 根据 Provider 创建对应的远程存储实现，未设置时按 B2 处理以兼容旧配置
+func newRemoteBackend(settings *config.RemoteSettings) (remote.Backend, error) {
+	if settings == nil {
+		return nil, fmt.Errorf("远程配置为空")
+	}
+	switch providerOf(settings) {
+	case remote.ProviderB2:
+		if err := resolveAccessCredentials(settings); err != nil {
+			return nil, err
+		}
+		return b2.NewClient(settings)
+	case remote.ProviderS3:
+		if err := resolveAccessCredentials(settings); err != nil {
+			return nil, err
+		}
+		return s3.NewClient(settings)
+	case remote.ProviderWebDAV:
+		return webdav.NewClient(settings)
+	case remote.ProviderDir:
+		return dirremote.NewBackend(settings)
+	case remote.ProviderGit:
+		return gitremote.NewBackend(settings, gitWorkDir(settings))
+	default:
+		return nil, fmt.Errorf("不支持的远程存储类型: %s", settings.Provider)
+	}
+}
+
+// resolveAccessCredentials 按 credential_store 解析 B2/S3 凭据并交给存储后端使用，
+// 解析结果仅保存在内存中，不会随配置写回磁盘
+func resolveAccessCredentials(settings *config.RemoteSettings) error {
+	creds, err := config.ResolveRemoteCredentials(settings, credentialsPath(settings))
+	if err != nil {
+		return err
+	}
+	settings.SetAccessCredentials(creds)
+	return nil
+}
+
+// credentialsPath 返回远程对应的凭据文件路径
+func credentialsPath(settings *config.RemoteSettings) string {
+	return config.CredentialsPath(viper.ConfigFileUsed(), settings.Name())
+}
+
+// providerOf 返回归一化后的 Provider 名称
+func providerOf(settings *config.RemoteSettings) string {
+	provider := strings.ToLower(strings.TrimSpace(settings.Provider))
+	if provider == "" {
+		return remote.ProviderB2
+	}
+	return provider
+}
+
+// providerLabel 返回用于终端提示的存储名称
+func providerLabel(settings *config.RemoteSettings) string {
+	switch providerOf(settings) {
+	case remote.ProviderB2:
+		return "B2"
+	case remote.ProviderS3:
+		return "S3"
+	case remote.ProviderWebDAV:
+		return "WebDAV"
+	case remote.ProviderDir:
+		return "本地目录"
+	case remote.ProviderGit:
+		return "Git"
+	default:
+		return settings.Provider
+	}
+}
+
+// remoteLocation 返回远程存储位置的描述，用于终端提示
+func remoteLocation(settings *config.RemoteSettings) string {
+	switch providerOf(settings) {
+	case remote.ProviderWebDAV:
+		return settings.URL
+	case remote.ProviderDir:
+		return settings.Directory
+	case remote.ProviderGit:
+		branch := settings.Branch
+		if branch == "" {
+			branch = gitremote.DefaultBranch
+		}
+		return fmt.Sprintf("%s (%s)", settings.Repo, branch)
+	default:
+		return settings.BucketName
+	}
+}
+
+// gitWorkDir 返回 Git 后端的本地工作副本目录，按仓库地址区分
+func gitWorkDir(settings *config.RemoteSettings) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(settings.Repo)))
+	base := filepath.Dir(viper.ConfigFileUsed())
+	return filepath.Join(base, "remote-git", hex.EncodeToString(sum[:8]))
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+	"github.com/codex-switch/codex-switch/internal/display"
+	"github.com/codex-switch/codex-switch/internal/remote"
+)
+
+// errRemoteAhead 表示远端在上次同步后已被其他设备更新
+var errRemoteAhead = errors.New("远端快照在上次同步后已被其他设备更新，请先执行 ckm remote sync 合并，或使用 --force 覆盖")
+
+// ensureRemoteUnchanged 推送前下载远端快照，确认其自上次同步后未被其他设备更新。
+//
+// 已记录 LastSyncHash 时比较内容摘要，旧配置则比较快照生成时间与 LastSync；
+// 远端内容与本地一致或远端不存在时直接通过。
+func ensureRemoteUnchanged(ctx context.Context, out io.Writer, backend remote.Backend, settings *config.RemoteSettings, objectName string, localKeys []config.APIKey) error {
+	data, err := backend.Download(ctx, objectName)
+	if errors.Is(err, remote.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("读取远端快照失败: %w", err)
+	}
+	snap, err := snapshotCodec(settings).Decode(data)
+	if err != nil {
+		return fmt.Errorf("无法解析远端快照，确认无误后可使用 --force 覆盖: %w", err)
+	}
+
+	remoteHash := snap.ContentHash()
+	if remoteHash == remote.KeysHash(localKeys) {
+		return nil
+	}
+	changed := snap.GeneratedAt.After(settings.LastSync)
+	if settings.LastSyncHash != "" {
+		changed = remoteHash != settings.LastSyncHash
+	}
+	if !changed {
+		return nil
+	}
+
+	when := snap.GeneratedAt.Local().Format("2006-01-02 15:04:05")
+	if snap.Host != "" {
+		when += " @ " + snap.Host
+	}
+	fmt.Fprintf(out, "%s 远端快照已更新 (%s)，与本地的差异:\n", display.ColorWarning.Sprint("⚠"), when)
+	printKeyDiff(out, localKeys, snap.Keys)
+	return errRemoteAhead
+}
+
+// warnLocalChanges 拉取前检查本地自上次同步后是否有将被覆盖的修改，有则输出警告与差异，
+// 返回是否输出了警告；拉取仍会继续，需要保留本地修改时应改用 ckm remote sync
+func warnLocalChanges(out io.Writer, settings *config.RemoteSettings, localKeys []config.APIKey, remoteKeys []config.APIKey) bool {
+	if settings.LastSyncHash == "" {
+		return false
+	}
+	localHash := remote.KeysHash(localKeys)
+	if localHash == settings.LastSyncHash || localHash == remote.KeysHash(remoteKeys) {
+		return false
+	}
+	fmt.Fprintf(out, "%s 本地配置在上次同步后已修改，以下差异将被远端覆盖 (如需保留请改用 ckm remote sync 合并):\n", display.ColorWarning.Sprint("⚠"))
+	printKeyDiff(out, localKeys, remoteKeys)
+	return true
+}
+
+// printKeyDiff 输出本地与远端 Key 列表的差异摘要
+func printKeyDiff(out io.Writer, localKeys []config.APIKey, remoteKeys []config.APIKey) {
+	onlyLocal, onlyRemote, changed := remote.DiffKeys(localKeys, remoteKeys)
+	lines := []struct {
+		label string
+		names []string
+	}{
+		{"仅本地存在", onlyLocal},
+		{"仅远端存在", onlyRemote},
+		{"内容不同", changed},
+	}
+	for _, line := range lines {
+		if len(line.names) > 0 {
+			fmt.Fprintf(out, "  %s: %s\n", line.label, strings.Join(line.names, ", "))
+		}
+	}
+}
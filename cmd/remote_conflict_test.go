@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+	"github.com/codex-switch/codex-switch/internal/remote"
+	dirremote "github.com/codex-switch/codex-switch/internal/remote/dir"
+)
+
+// TestEnsureRemoteUnchanged 验证推送前能识别远端在上次同步后的更新
+func TestEnsureRemoteUnchanged(t *testing.T) {
+	ctx := context.Background()
+	settings := &config.RemoteSettings{Provider: remote.ProviderDir, Directory: t.TempDir(), SyncToken: "token-for-tests-0123456789"}
+	backend, err := dirremote.NewBackend(settings)
+	if err != nil {
+		t.Fatalf("创建后端失败: %v", err)
+	}
+
+	synced := []config.APIKey{{ID: "1", Name: "main", APIKey: "sk-main"}}
+	local := append([]config.APIKey{}, synced...)
+	local = append(local, config.APIKey{ID: "2", Name: "laptop", APIKey: "sk-laptop"})
+
+	// 远端不存在时直接通过
+	if err := ensureRemoteUnchanged(ctx, &bytes.Buffer{}, backend, settings, "default.json", local); err != nil {
+		t.Fatalf("远端不存在时不应拒绝: %v", err)
+	}
+
+	upload := func(keys []config.APIKey) {
+		data, err := snapshotCodec(settings).Encode(remote.BuildSnapshot(&config.Config{Keys: keys}))
+		if err != nil {
+			t.Fatalf("加密失败: %v", err)
+		}
+		if err := backend.Upload(ctx, "default.json", data); err != nil {
+			t.Fatalf("上传失败: %v", err)
+		}
+	}
+
+	upload(synced)
+	settings.LastSyncHash = remote.KeysHash(synced)
+	if err := ensureRemoteUnchanged(ctx, &bytes.Buffer{}, backend, settings, "default.json", local); err != nil {
+		t.Fatalf("远端未变化时不应拒绝: %v", err)
+	}
+
+	// 其他设备推送了新的 Key
+	upload(append(append([]config.APIKey{}, synced...), config.APIKey{ID: "2", Name: "desktop", APIKey: "sk-desktop"}))
+	var out bytes.Buffer
+	err = ensureRemoteUnchanged(ctx, &out, backend, settings, "default.json", local)
+	if !errors.Is(err, errRemoteAhead) {
+		t.Fatalf("期望 errRemoteAhead，实际 %v", err)
+	}
+	if !strings.Contains(out.String(), "laptop") || !strings.Contains(out.String(), "desktop") {
+		t.Fatalf("差异摘要缺少 Key 名称: %s", out.String())
+	}
+
+	// 未记录摘要的旧配置按生成时间判断
+	settings.LastSyncHash = ""
+	settings.LastSync = time.Now().Add(time.Hour)
+	if err := ensureRemoteUnchanged(ctx, &bytes.Buffer{}, backend, settings, "default.json", local); err != nil {
+		t.Fatalf("远端早于上次同步时不应拒绝: %v", err)
+	}
+}
+
+// TestWarnLocalChanges 验证拉取前能识别并警告本地未上传的修改
+func TestWarnLocalChanges(t *testing.T) {
+	synced := []config.APIKey{{ID: "1", Name: "main", APIKey: "sk-main", Active: true}}
+	settings := &config.RemoteSettings{LastSyncHash: remote.KeysHash(synced)}
+	remoteKeys := []config.APIKey{{ID: "1", Name: "main", APIKey: "sk-rotated"}}
+
+	// 仅切换激活 Key 或使用时间变化不算修改
+	switched := []config.APIKey{{ID: "1", Name: "main", APIKey: "sk-main", LastUsed: time.Now()}}
+	if warned := warnLocalChanges(&bytes.Buffer{}, settings, switched, remoteKeys); warned {
+		t.Fatalf("本地未修改时不应警告")
+	}
+
+	var out bytes.Buffer
+	edited := []config.APIKey{{ID: "1", Name: "main", APIKey: "sk-local-edit"}}
+	if warned := warnLocalChanges(&out, settings, edited, remoteKeys); !warned || !strings.Contains(out.String(), "main") {
+		t.Fatalf("本地修改将被覆盖时应输出警告与差异: %q", out.String())
+	}
+}
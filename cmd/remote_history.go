@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/codex-switch/codex-switch/internal/logging"
+	"github.com/codex-switch/codex-switch/internal/remote"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	remoteHistoryProfile string
+	remoteHistoryMax     int
+)
+
+// newRemoteHistoryCommand 构建 remote history 子命令，列出远端保存的历史版本
+func newRemoteHistoryCommand() *cobra.Command {
+	historyCmd := &cobra.Command{
+		Use:   "history",
+		Short: "查看远程快照的历史版本，配合 ckm remote pull --version 恢复",
+		RunE:  runRemoteHistory,
+	}
+	historyCmd.Flags().StringVar(&remoteHistoryProfile, "profile", "", "指定配置档案名")
+	historyCmd.Flags().IntVar(&remoteHistoryMax, "limit", 20, "最多显示的版本数，0 表示全部")
+	return historyCmd
+}
+
+// historyEntry 为单个版本的展示信息，快照无法解密时 Host 与 Keys 为空
+type historyEntry struct {
+	Version remote.Version
+	Host    string
+	Keys    int
+	Err     error
+}
+
+// runRemoteHistory 列出历史版本，并解密每个版本以展示生成主机与 Key 数量
+func runRemoteHistory(cmd *cobra.Command, _ []string) error {
+	manager, err := mustLoadManager(cmd)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := manager.Config()
+	if err != nil {
+		return err
+	}
+
+	settings := cfg.Remote
+	if settings == nil || !settings.Enabled {
+		return errors.New("未配置远程同步，请先执行 ckm remote init")
+	}
+
+	profile := normalizeProfile(remoteHistoryProfile, settings.ObjectKey)
+	if profile == "" {
+		return errors.New("未指定有效的配置档案名")
+	}
+
+	backend, err := newRemoteBackend(settings)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), 120*time.Second)
+	defer cancel()
+
+	if err := backend.Prepare(ctx); err != nil {
+		return err
+	}
+	versions, err := remote.ListVersions(ctx, backend, profile)
+	if err != nil {
+		return err
+	}
+	if len(versions) == 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "配置档案 %s 暂无历史版本\n", profile)
+		return nil
+	}
+	if remoteHistoryMax > 0 && len(versions) > remoteHistoryMax {
+		versions = versions[:remoteHistoryMax]
+	}
+
+	codec := snapshotCodec(settings)
+	entries := make([]historyEntry, 0, len(versions))
+	for _, v := range versions {
+		entry := historyEntry{Version: v}
+		data, err := backend.Download(ctx, v.Object)
+		if err == nil {
+			var snap *remote.Snapshot
+			if snap, err = codec.Decode(data); err == nil {
+				entry.Host = snap.Host
+				entry.Keys = len(snap.Keys)
+			}
+		}
+		if err != nil {
+			logging.Warnf("读取历史版本 %s 失败: %v", v.ID, err)
+			entry.Err = err
+		}
+		entries = append(entries, entry)
+	}
+
+	printHistory(cmd.OutOrStdout(), entries)
+	fmt.Fprintf(cmd.OutOrStdout(), "\n恢复指定版本: ckm remote pull --profile %s --version <版本>\n", profile)
+	return nil
+}
+
+// printHistory 以对齐的表格输出历史版本
+func printHistory(out io.Writer, entries []historyEntry) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "版本\t时间\t主机\tKey 数量\t大小")
+	for _, e := range entries {
+		host, keys := e.Host, strconv.Itoa(e.Keys)
+		if e.Err != nil {
+			host, keys = "(无法读取)", "-"
+		}
+		if host == "" {
+			host = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d B\n",
+			e.Version.ID,
+			e.Version.UpdatedAt.Local().Format("2006-01-02 15:04:05"),
+			host, keys, e.Version.Size)
+	}
+	w.Flush()
+}
@@ -0,0 +1,279 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+	"github.com/codex-switch/codex-switch/internal/logging"
+	"github.com/codex-switch/codex-switch/internal/remote"
+
+	"github.com/spf13/cobra"
+)
+
+// ckm remotes 管理多个命名远程；remote list/rename 已用于管理远端配置档案，因此单独成组
+func init() {
+	remotesCmd := &cobra.Command{
+		Use:   "remotes",
+		Short: "管理多个命名远程",
+	}
+
+	addCmd := &cobra.Command{
+		Use:   "add NAME",
+		Short: "新增命名远程，参数与 ckm remote init 相同",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runRemoteNameAdd,
+	}
+	registerRemoteInitFlags(addCmd)
+
+	removeCmd := &cobra.Command{
+		Use:   "remove NAME",
+		Short: "删除命名远程的本地配置，不会删除远端快照",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runRemoteNameRemove,
+	}
+
+	renameCmd := &cobra.Command{
+		Use:   "rename OLD NEW",
+		Short: "重命名远程",
+		Args:  cobra.ExactArgs(2),
+		RunE:  runRemoteNameRename,
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "列出已配置的远程",
+		Args:  cobra.NoArgs,
+		RunE:  runRemoteNameList,
+	}
+
+	defaultCmd := &cobra.Command{
+		Use:   "default NAME",
+		Short: "设置未指定 --remote 时使用的默认远程",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runRemoteNameDefault,
+	}
+
+	remotesCmd.AddCommand(addCmd, removeCmd, renameCmd, listCmd, defaultCmd)
+	RootCommand().AddCommand(remotesCmd)
+}
+
+func runRemoteNameAdd(cmd *cobra.Command, args []string) error {
+	manager, err := mustLoadManager(cmd)
+	if err != nil {
+		return err
+	}
+	cfg, err := manager.Config()
+	if err != nil {
+		return err
+	}
+	name := args[0]
+	if err := cfg.AddRemote(name, nil); err != nil {
+		return err
+	}
+	// 仅更新内存中的配置，存储确认可用后由 initRemote 统一保存
+	if err := manager.ReplaceConfig(cfg); err != nil {
+		return err
+	}
+	if err := manager.UseRemote(name); err != nil {
+		return err
+	}
+	if err := initRemote(cmd, manager); err != nil {
+		return err
+	}
+
+	if cfg.DefaultRemote == name {
+		fmt.Fprintf(cmd.OutOrStdout(), "已将 %s 设为默认远程\n", name)
+	} else {
+		fmt.Fprintf(cmd.OutOrStdout(), "使用 --remote %s 操作该远程，或执行 ckm remote push --all 推送到全部远程\n", name)
+	}
+	logging.Infof("新增远程: name=%s", name)
+	return nil
+}
+
+func runRemoteNameRemove(cmd *cobra.Command, args []string) error {
+	manager, err := mustLoadManager(cmd)
+	if err != nil {
+		return err
+	}
+	cfg, err := manager.Config()
+	if err != nil {
+		return err
+	}
+	name := args[0]
+	if err := cfg.RemoveRemote(name); err != nil {
+		return err
+	}
+	if err := manager.ReplaceConfig(cfg); err != nil {
+		return err
+	}
+	if err := manager.Save(); err != nil {
+		return err
+	}
+
+	// 默认远程 origin 的快照与其他文件位于同一目录，删除后保留作为备份
+	cfgPath := manager.ConfigPath()
+	if name != config.DefaultRemoteName {
+		if err := os.RemoveAll(snapshotDir(cfgPath, name)); err != nil {
+			logging.Warnf("删除本地快照失败: %v", err)
+		}
+		if err := os.RemoveAll(config.TeamOverlayDir(cfgPath, name)); err != nil {
+			logging.Warnf("删除团队配置缓存失败: %v", err)
+		}
+	}
+	if err := os.Remove(config.CredentialsPath(cfgPath, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		logging.Warnf("删除凭据文件失败: %v", err)
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "✓ 已删除远程 %s，远端快照保持不变\n", name)
+	logging.Infof("删除远程: name=%s", name)
+	return nil
+}
+
+func runRemoteNameRename(cmd *cobra.Command, args []string) error {
+	manager, err := mustLoadManager(cmd)
+	if err != nil {
+		return err
+	}
+	cfg, err := manager.Config()
+	if err != nil {
+		return err
+	}
+	oldName, newName := args[0], args[1]
+	if err := cfg.RenameRemote(oldName, newName); err != nil {
+		return err
+	}
+	if err := manager.ReplaceConfig(cfg); err != nil {
+		return err
+	}
+	if err := manager.Save(); err != nil {
+		return err
+	}
+
+	// 本地同步基准、团队配置缓存与凭据文件按远程名称存放，随之改名
+	cfgPath := manager.ConfigPath()
+	if err := moveSnapshots(snapshotDir(cfgPath, oldName), snapshotDir(cfgPath, newName)); err != nil {
+		logging.Warnf("移动本地快照失败: %v", err)
+	}
+	if err := moveSnapshots(config.TeamOverlayDir(cfgPath, oldName), config.TeamOverlayDir(cfgPath, newName)); err != nil {
+		logging.Warnf("移动团队配置缓存失败: %v", err)
+	}
+	oldCreds := config.CredentialsPath(cfgPath, oldName)
+	if _, err := os.Stat(oldCreds); err == nil {
+		if err := os.Rename(oldCreds, config.CredentialsPath(cfgPath, newName)); err != nil {
+			logging.Warnf("重命名凭据文件失败: %v", err)
+		}
+	}
+	// 待推送队列按远程名称记录，不改写时重试会因找不到旧远程而一直失败
+	if err := renameOutboxRemote(cfgPath, oldName, newName); err != nil {
+		logging.Warnf("更新待推送队列失败: %v", err)
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "✓ 已将远程 %s 重命名为 %s\n", oldName, newName)
+	settings := cfg.Remotes[newName]
+	if provider := providerOf(settings); provider == remote.ProviderB2 || provider == remote.ProviderS3 {
+		oldKeyVar, oldAppVar := config.RemoteEnvNames(oldName)
+		newKeyVar, newAppVar := config.RemoteEnvNames(newName)
+		fmt.Fprintf(out, "注意: 环境变量 %s/%s 不再适用于该远程，请改用 %s/%s\n", oldKeyVar, oldAppVar, newKeyVar, newAppVar)
+		if oldName == config.DefaultRemoteName && settings.CredentialStore != config.CredentialStoreEnv {
+			fmt.Fprintf(out, "注意: 通用的 %s/%s 仅用于 %s 远程，也不再适用\n", config.EnvRemoteKeyID, config.EnvRemoteAppKey, config.DefaultRemoteName)
+		}
+	}
+	logging.Infof("重命名远程: %s -> %s", oldName, newName)
+	return nil
+}
+
+func runRemoteNameList(cmd *cobra.Command, _ []string) error {
+	manager, err := mustLoadManager(cmd)
+	if err != nil {
+		return err
+	}
+	cfg, err := manager.Config()
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "名称\t存储\t位置\t档案\t状态\t最后同步")
+	for _, name := range cfg.RemoteNames() {
+		settings := cfg.Remotes[name]
+		marker := ""
+		if name == cfg.DefaultRemote {
+			marker = " *"
+		}
+		location, profile, status, synced := "-", "-", "未配置", "-"
+		if settings.Enabled {
+			location = remoteLocation(settings)
+			profile = normalizeProfile("", settings.ObjectKey)
+			status = "已启用"
+			if !settings.LastSync.IsZero() {
+				synced = settings.LastSync.Local().Format("2006-01-02 15:04:05")
+			}
+		}
+		fmt.Fprintf(w, "%s%s\t%s\t%s\t%s\t%s\t%s\n", name, marker, providerLabel(settings), location, profile, status, synced)
+	}
+	w.Flush()
+	fmt.Fprintln(out, "\n* 为默认远程")
+	return nil
+}
+
+func runRemoteNameDefault(cmd *cobra.Command, args []string) error {
+	manager, err := mustLoadManager(cmd)
+	if err != nil {
+		return err
+	}
+	cfg, err := manager.Config()
+	if err != nil {
+		return err
+	}
+	name := args[0]
+	if err := cfg.SetDefaultRemote(name); err != nil {
+		return err
+	}
+	if err := manager.ReplaceConfig(cfg); err != nil {
+		return err
+	}
+	if err := manager.Save(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "✓ 默认远程已设为 %s\n", name)
+	logging.Infof("设置默认远程: %s", name)
+	return nil
+}
+
+// moveSnapshots 将 src 目录下的本地快照或团队配置缓存移动到 dst，src 为空目录时一并删除
+func moveSnapshots(src, dst string) error {
+	files, err := filepath.Glob(filepath.Join(src, "*.json"))
+	if err != nil || len(files) == 0 {
+		return err
+	}
+	if err := os.MkdirAll(dst, 0o700); err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := os.Rename(file, filepath.Join(dst, filepath.Base(file))); err != nil {
+			return err
+		}
+	}
+	_ = os.Remove(src)
+	return nil
+}
+
+// renameOutboxRemote 将待推送队列中旧远程名称的记录改记到新名称
+func renameOutboxRemote(cfgPath, oldName, newName string) error {
+	path := outboxPath(cfgPath)
+	box, err := remote.LoadOutbox(path)
+	if err != nil {
+		return err
+	}
+	if box.RenameRemote(oldName, newName) == 0 {
+		return nil
+	}
+	return box.Save(path)
+}
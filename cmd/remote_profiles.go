@@ -0,0 +1,269 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+	"github.com/codex-switch/codex-switch/internal/logging"
+	"github.com/codex-switch/codex-switch/internal/remote"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	remoteListNoKeys  bool
+	remoteCopyForce   bool
+	remoteRenameForce bool
+)
+
+// newRemoteProfileCommands 构建 remote list/copy/rename 子命令
+func newRemoteProfileCommands() []*cobra.Command {
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "列出远端存储中的全部配置档案",
+		Args:  cobra.NoArgs,
+		RunE:  runRemoteList,
+	}
+	listCmd.Flags().BoolVar(&remoteListNoKeys, "no-keys", false, "不下载快照统计 Key 数量，适合档案较多时快速列出")
+
+	copyCmd := &cobra.Command{
+		Use:   "copy SRC DST",
+		Short: "复制配置档案(含历史版本)",
+		Args:  cobra.ExactArgs(2),
+		RunE:  runRemoteCopy,
+	}
+	copyCmd.Flags().BoolVar(&remoteCopyForce, "force", false, "目标档案已存在时覆盖")
+
+	renameCmd := &cobra.Command{
+		Use:   "rename SRC DST",
+		Short: "重命名配置档案(含历史版本)",
+		Args:  cobra.ExactArgs(2),
+		RunE:  runRemoteRename,
+	}
+	renameCmd.Flags().BoolVar(&remoteRenameForce, "force", false, "目标档案已存在时覆盖")
+
+	return []*cobra.Command{listCmd, copyCmd, renameCmd}
+}
+
+func runRemoteList(cmd *cobra.Command, _ []string) error {
+	_, settings, backend, err := openRemoteBackend(cmd)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), 120*time.Second)
+	defer cancel()
+
+	if err := backend.Prepare(ctx); err != nil {
+		return err
+	}
+	profiles, err := remote.ListProfiles(ctx, backend)
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	if len(profiles) == 0 {
+		fmt.Fprintf(out, "%s 中暂无配置档案\n", providerLabel(settings))
+		return nil
+	}
+
+	codec := snapshotCodec(settings)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "档案\t对象\t大小\t上传时间\tKey 数量\t历史版本")
+	for _, p := range profiles {
+		marker := ""
+		if p.Name == settings.ObjectKey {
+			marker = " *"
+		}
+		object, size, updated, keys := "-", "-", "-", "-"
+		if p.Object != "" {
+			object = p.Object
+			size = fmt.Sprintf("%d B", p.Size)
+			if !p.UpdatedAt.IsZero() {
+				updated = p.UpdatedAt.Local().Format("2006-01-02 15:04:05")
+			}
+			if !remoteListNoKeys {
+				keys = profileKeyCount(ctx, backend, codec, p.Object)
+			}
+		}
+		fmt.Fprintf(w, "%s%s\t%s\t%s\t%s\t%s\t%d\n", p.Name, marker, object, size, updated, keys, p.Versions)
+	}
+	w.Flush()
+	fmt.Fprintln(out, "\n* 为当前使用的配置档案")
+	return nil
+}
+
+// profileKeyCount 下载并解析快照以统计 Key 数量，无法读取时返回提示文本
+func profileKeyCount(ctx context.Context, backend remote.Backend, codec remote.Codec, object string) string {
+	data, err := backend.Download(ctx, object)
+	if err != nil {
+		logging.Debugf("读取 %s 失败: %v", object, err)
+		return "(无法读取)"
+	}
+	snap, err := codec.Decode(data)
+	if err != nil {
+		logging.Debugf("解析 %s 失败: %v", object, err)
+		if errors.Is(err, remote.ErrDecrypt) || errors.Is(err, remote.ErrRecipientDecrypt) || errors.Is(err, remote.ErrNotRecipient) || errors.Is(err, remote.ErrNoIdentity) {
+			return "(无法解密)"
+		}
+		return "(无法校验)"
+	}
+	return strconv.Itoa(len(snap.Keys))
+}
+
+func runRemoteCopy(cmd *cobra.Command, args []string) error {
+	return copyRemoteProfile(cmd, args[0], args[1], remoteCopyForce, false)
+}
+
+func runRemoteRename(cmd *cobra.Command, args []string) error {
+	return copyRemoteProfile(cmd, args[0], args[1], remoteRenameForce, true)
+}
+
+// copyRemoteProfile 复制档案，move 为 true 时复制完成后删除源档案并同步本地状态
+func copyRemoteProfile(cmd *cobra.Command, srcArg, dstArg string, force bool, move bool) error {
+	manager, settings, backend, err := openRemoteBackend(cmd)
+	if err != nil {
+		return err
+	}
+	src := normalizeProfile(srcArg, "")
+	dst := normalizeProfile(dstArg, "")
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), 120*time.Second)
+	defer cancel()
+
+	if err := backend.Prepare(ctx); err != nil {
+		return err
+	}
+	copied, err := remote.CopyProfile(ctx, backend, src, dst, force)
+	if err != nil {
+		if errors.Is(err, remote.ErrProfileExists) {
+			return fmt.Errorf("%w，使用 --force 覆盖", err)
+		}
+		return err
+	}
+
+	if !move {
+		if err := carryProfileSettings(manager, src, dst, false); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "✓ 已复制配置档案 %s → %s (%d 个对象)\n", src, dst, copied)
+		logging.Infof("复制远程配置档案: %s -> %s objects=%d", src, dst, copied)
+		return nil
+	}
+
+	if _, err := remote.DeleteProfile(ctx, backend, src); err != nil {
+		return fmt.Errorf("已复制到 %s，但删除源档案失败: %w", dst, err)
+	}
+
+	// 本地的同步基准随档案一起改名
+	oldBase := buildSnapshotPath(manager.ConfigPath(), settings, src)
+	if _, err := os.Stat(oldBase); err == nil {
+		if err := os.Rename(oldBase, buildSnapshotPath(manager.ConfigPath(), settings, dst)); err != nil {
+			logging.Warnf("重命名本地快照失败: %v", err)
+		}
+	}
+	if err := carryProfileSettings(manager, src, dst, true); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "✓ 已重命名配置档案 %s → %s (%d 个对象)\n", src, dst, copied)
+	logging.Infof("重命名远程配置档案: %s -> %s objects=%d", src, dst, copied)
+	return nil
+}
+
+// carryProfileSettings 将按档案名记录的本地设置带到 dst：接收者列表随档案复制，
+// 否则下次推送 dst 时会退回 SyncToken 加密，团队成员无法再解密。move 为 true 时
+// 同时改名当前档案、团队订阅及其缓存，并移除 src 的设置
+func carryProfileSettings(manager *config.Manager, src, dst string, move bool) error {
+	cfg, err := manager.Config()
+	if err != nil {
+		return err
+	}
+	settings := cfg.Remote
+	if settings == nil {
+		return errors.New("配置缺失远程字段，请重新初始化配置")
+	}
+
+	if recipients, ok := settings.Recipients[src]; ok {
+		if settings.Recipients == nil {
+			settings.Recipients = make(map[string][]string)
+		}
+		settings.Recipients[dst] = slices.Clone(recipients)
+	} else {
+		delete(settings.Recipients, dst)
+	}
+
+	if move {
+		delete(settings.Recipients, src)
+		if settings.ObjectKey == src {
+			settings.ObjectKey = dst
+		}
+		if index := slices.Index(settings.TeamProfiles, src); index >= 0 {
+			if slices.Contains(settings.TeamProfiles, dst) {
+				settings.TeamProfiles = slices.Delete(settings.TeamProfiles, index, index+1)
+			} else {
+				settings.TeamProfiles[index] = dst
+			}
+			moveTeamOverlay(manager.ConfigPath(), settings.Name(), src, dst)
+			// 团队 Key 的 ID 包含档案名，激活的团队 Key 随之改名
+			oldPrefix := config.TeamKeyID(src, "")
+			if strings.HasPrefix(cfg.ActiveKeyID, oldPrefix) {
+				cfg.ActiveKeyID = config.TeamKeyID(dst, strings.TrimPrefix(cfg.ActiveKeyID, oldPrefix))
+			}
+		}
+	}
+
+	if err := manager.ReplaceConfig(cfg); err != nil {
+		return err
+	}
+	return manager.Save()
+}
+
+// moveTeamOverlay 将团队配置缓存改名为 dst，失败时仅记录日志，可执行 ckm team refresh 重新获取
+func moveTeamOverlay(cfgPath, remoteName, src, dst string) {
+	oldPath := config.TeamOverlayPath(cfgPath, remoteName, src)
+	overlay, err := config.LoadTeamOverlay(oldPath)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logging.Warnf("读取团队配置缓存失败: %v", err)
+		}
+		return
+	}
+	overlay.Profile = dst
+	if err := overlay.Save(config.TeamOverlayPath(cfgPath, remoteName, dst)); err != nil {
+		logging.Warnf("写入团队配置缓存失败: %v", err)
+		return
+	}
+	if err := os.Remove(oldPath); err != nil {
+		logging.Warnf("删除旧团队配置缓存失败: %v", err)
+	}
+}
+
+// openRemoteBackend 加载配置并创建远程存储后端
+func openRemoteBackend(cmd *cobra.Command) (*config.Manager, *config.RemoteSettings, remote.Backend, error) {
+	manager, err := mustLoadManager(cmd)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	cfg, err := manager.Config()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	settings := cfg.Remote
+	if settings == nil || !settings.Enabled {
+		return nil, nil, nil, errors.New("未配置远程同步，请先执行 ckm remote init")
+	}
+	backend, err := newRemoteBackend(settings)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return manager, settings, backend, nil
+}
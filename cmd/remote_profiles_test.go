@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+)
+
+// TestCarryProfileSettings 验证复制与重命名档案时接收者列表与团队订阅随档案迁移
+func TestCarryProfileSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	manager, err := config.NewDefaultManager(path)
+	if err != nil {
+		t.Fatalf("创建管理器失败: %v", err)
+	}
+	if _, err := manager.Load(); err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	cfg, _ := manager.Config()
+	cfg.Remote.ObjectKey = "alpha"
+	cfg.Remote.Recipients = map[string][]string{"alpha": {"x25519:alice"}}
+	cfg.Remote.TeamProfiles = []string{"alpha"}
+	if err := manager.ReplaceConfig(cfg); err != nil {
+		t.Fatalf("替换配置失败: %v", err)
+	}
+	overlay := &config.TeamOverlay{Profile: "alpha", Keys: []config.APIKey{{ID: "1", Name: "团队", APIKey: "sk-team"}}}
+	if err := overlay.Save(config.TeamOverlayPath(path, config.DefaultRemoteName, "alpha")); err != nil {
+		t.Fatalf("写入团队缓存失败: %v", err)
+	}
+
+	if err := carryProfileSettings(manager, "alpha", "beta", false); err != nil {
+		t.Fatalf("复制设置失败: %v", err)
+	}
+	cfg, _ = manager.Config()
+	if !slices.Equal(cfg.Remote.Recipients["beta"], []string{"x25519:alice"}) || len(cfg.Remote.Recipients["alpha"]) != 1 {
+		t.Fatalf("复制档案应复制接收者列表: %#v", cfg.Remote.Recipients)
+	}
+
+	if err := carryProfileSettings(manager, "alpha", "gamma", true); err != nil {
+		t.Fatalf("迁移设置失败: %v", err)
+	}
+	cfg, _ = manager.Config()
+	if _, ok := cfg.Remote.Recipients["alpha"]; ok || len(cfg.Remote.Recipients["gamma"]) != 1 {
+		t.Fatalf("重命名档案应迁移接收者列表: %#v", cfg.Remote.Recipients)
+	}
+	if cfg.Remote.ObjectKey != "gamma" || !slices.Equal(cfg.Remote.TeamProfiles, []string{"gamma"}) {
+		t.Fatalf("当前档案与团队订阅应随之改名: %s %v", cfg.Remote.ObjectKey, cfg.Remote.TeamProfiles)
+	}
+	moved, err := config.LoadTeamOverlay(config.TeamOverlayPath(path, config.DefaultRemoteName, "gamma"))
+	if err != nil || moved.Profile != "gamma" {
+		t.Fatalf("团队缓存应随之改名: %+v err=%v", moved, err)
+	}
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+	"github.com/codex-switch/codex-switch/internal/logging"
+	"github.com/codex-switch/codex-switch/internal/remote"
+
+	"github.com/spf13/cobra"
+)
+
+var remoteRecipientsProfile string
+
+// newRemoteRecipientsCommand 构建 remote recipients 子命令，管理配置档案的接收者公钥
+func newRemoteRecipientsCommand() *cobra.Command {
+	recipientsCmd := &cobra.Command{
+		Use:   "recipients",
+		Short: "管理配置档案的接收者公钥，配置后快照按各成员公钥加密，无需共享 SyncToken",
+	}
+	recipientsCmd.PersistentFlags().StringVar(&remoteRecipientsProfile, "profile", "", "配置档案名，默认为当前档案")
+
+	addCmd := &cobra.Command{
+		Use:   "add PUBLIC_KEY",
+		Short: "添加接收者公钥 (由对方执行 ckm identity show 获得)",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runRemoteRecipientsAdd,
+	}
+
+	removeCmd := &cobra.Command{
+		Use:   "remove PUBLIC_KEY",
+		Short: "移除接收者公钥，下次推送时重新加密",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runRemoteRecipientsRemove,
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "列出配置档案的接收者公钥",
+		Args:  cobra.NoArgs,
+		RunE:  runRemoteRecipientsList,
+	}
+
+	recipientsCmd.AddCommand(addCmd, removeCmd, listCmd)
+	return recipientsCmd
+}
+
+func runRemoteRecipientsAdd(cmd *cobra.Command, args []string) error {
+	pub, err := remote.ParseRecipient(args[0])
+	if err != nil {
+		return err
+	}
+	encoded := remote.EncodeRecipient(pub)
+	return updateRemoteSettings(cmd, func(settings *config.RemoteSettings) error {
+		// 接收者公钥是公开的，快照来源只能依靠 ed25519 签名确认
+		if strings.TrimSpace(settings.SigningKey) == "" {
+			return remote.ErrSigningKeyRequired
+		}
+		profile := normalizeProfile(remoteRecipientsProfile, settings.ObjectKey)
+		for _, existing := range settings.Recipients[profile] {
+			if existing == encoded {
+				fmt.Fprintln(cmd.OutOrStdout(), "该公钥已在接收者列表中")
+				return nil
+			}
+		}
+		if settings.Recipients == nil {
+			settings.Recipients = make(map[string][]string)
+		}
+		settings.Recipients[profile] = append(settings.Recipients[profile], encoded)
+		fmt.Fprintf(cmd.OutOrStdout(), "✓ 已为配置档案 %s 添加接收者，执行 ckm remote push 后生效\n", profile)
+		if strings.TrimSpace(settings.Identity) == "" {
+			fmt.Fprintln(cmd.OutOrStdout(), "本机尚未创建身份，推送前请执行 ckm identity init")
+		}
+		logging.Infof("添加快照接收者: profile=%s key=%s", profile, encoded)
+		return nil
+	})
+}
+
+func runRemoteRecipientsRemove(cmd *cobra.Command, args []string) error {
+	target := strings.TrimSpace(args[0])
+	if pub, err := remote.ParseRecipient(target); err == nil {
+		target = remote.EncodeRecipient(pub)
+	}
+	return updateRemoteSettings(cmd, func(settings *config.RemoteSettings) error {
+		profile := normalizeProfile(remoteRecipientsProfile, settings.ObjectKey)
+		current := settings.Recipients[profile]
+		kept := make([]string, 0, len(current))
+		for _, existing := range current {
+			if existing != target {
+				kept = append(kept, existing)
+			}
+		}
+		if len(kept) == len(current) {
+			return errors.New("接收者列表中不存在该公钥")
+		}
+		if len(kept) == 0 {
+			delete(settings.Recipients, profile)
+		} else {
+			settings.Recipients[profile] = kept
+		}
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "✓ 已从配置档案 %s 移除接收者\n", profile)
+		if len(kept) == 0 {
+			fmt.Fprintln(out, "接收者列表已清空，下次推送将恢复使用 SyncToken 加密")
+		} else {
+			fmt.Fprintln(out, "下次推送或同步时将使用新的数据密钥重新加密")
+		}
+		fmt.Fprintln(out, "注意: 已上传的历史版本仍可被该成员解密，必要时请同时轮换快照中的 API Key")
+		logging.Infof("移除快照接收者: profile=%s key=%s", profile, target)
+		return nil
+	})
+}
+
+func runRemoteRecipientsList(cmd *cobra.Command, _ []string) error {
+	manager, err := mustLoadManager(cmd)
+	if err != nil {
+		return err
+	}
+	cfg, err := manager.Config()
+	if err != nil {
+		return err
+	}
+	settings := cfg.Remote
+	if settings == nil {
+		settings = &config.RemoteSettings{}
+	}
+	profile := normalizeProfile(remoteRecipientsProfile, settings.ObjectKey)
+
+	out := cmd.OutOrStdout()
+	recipients := settings.Recipients[profile]
+	if len(recipients) == 0 {
+		fmt.Fprintf(out, "配置档案 %s 未配置接收者，快照使用 SyncToken 加密\n", profile)
+		return nil
+	}
+	fmt.Fprintf(out, "配置档案 %s 的接收者:\n", profile)
+	if strings.TrimSpace(settings.Identity) != "" {
+		if priv, err := remote.ParseIdentity(settings.Identity); err == nil {
+			fmt.Fprintf(out, "  %s (本机，自动加入)\n", remote.EncodeRecipient(priv.PublicKey()))
+		}
+	}
+	for _, pub := range recipients {
+		fmt.Fprintf(out, "  %s\n", pub)
+	}
+	return nil
+}
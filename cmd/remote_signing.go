@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+	"github.com/codex-switch/codex-switch/internal/logging"
+	"github.com/codex-switch/codex-switch/internal/remote"
+
+	"github.com/spf13/cobra"
+)
+
+var remoteSigningRotate bool
+
+// newRemoteSigningCommand 构建 remote signing 子命令，管理 ed25519 快照签名密钥
+func newRemoteSigningCommand() *cobra.Command {
+	signingCmd := &cobra.Command{
+		Use:   "signing",
+		Short: "管理快照的 ed25519 签名密钥与信任列表 (默认使用 SyncToken 派生的 HMAC 签名)",
+	}
+
+	initCmd := &cobra.Command{
+		Use:   "init",
+		Short: "生成本机签名私钥并输出公钥，供其他机器执行 ckm remote signing trust",
+		RunE:  runRemoteSigningInit,
+	}
+	initCmd.Flags().BoolVar(&remoteSigningRotate, "rotate", false, "已存在私钥时重新生成")
+
+	showCmd := &cobra.Command{
+		Use:   "show",
+		Short: "输出本机签名公钥与信任列表",
+		RunE:  runRemoteSigningShow,
+	}
+
+	trustCmd := &cobra.Command{
+		Use:   "trust PUBLIC_KEY",
+		Short: "信任指定公钥签名的快照",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runRemoteSigningTrust,
+	}
+
+	untrustCmd := &cobra.Command{
+		Use:   "untrust PUBLIC_KEY",
+		Short: "从信任列表移除公钥",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runRemoteSigningUntrust,
+	}
+
+	signingCmd.AddCommand(initCmd, showCmd, trustCmd, untrustCmd)
+	return signingCmd
+}
+
+func runRemoteSigningInit(cmd *cobra.Command, _ []string) error {
+	return updateRemoteSettings(cmd, func(settings *config.RemoteSettings) error {
+		if strings.TrimSpace(settings.SigningKey) != "" && !remoteSigningRotate {
+			return errors.New("已存在签名私钥，如需重新生成请使用 --rotate")
+		}
+		seed, err := remote.GenerateSigningKey()
+		if err != nil {
+			return err
+		}
+		priv, err := remote.ParseSigningKey(seed)
+		if err != nil {
+			return err
+		}
+		settings.SigningKey = seed
+		fmt.Fprintln(cmd.OutOrStdout(), "✓ 已生成签名私钥，公钥:")
+		fmt.Fprintln(cmd.OutOrStdout(), remote.EncodePublicKey(priv))
+		fmt.Fprintln(cmd.OutOrStdout(), "在其他机器执行 ckm remote signing trust <公钥> 以接受本机推送的快照")
+		logging.Warnf("生成快照签名私钥, rotate=%t", remoteSigningRotate)
+		return nil
+	})
+}
+
+func runRemoteSigningShow(cmd *cobra.Command, _ []string) error {
+	manager, err := mustLoadManager(cmd)
+	if err != nil {
+		return err
+	}
+	cfg, err := manager.Config()
+	if err != nil {
+		return err
+	}
+	settings := cfg.Remote
+	if settings == nil {
+		settings = &config.RemoteSettings{}
+	}
+
+	out := cmd.OutOrStdout()
+	if strings.TrimSpace(settings.SigningKey) == "" {
+		fmt.Fprintln(out, "签名方式: HMAC-SHA256 (由 SyncToken 派生)")
+	} else {
+		priv, err := remote.ParseSigningKey(settings.SigningKey)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, "签名方式: ed25519")
+		fmt.Fprintf(out, "本机公钥: %s\n", remote.EncodePublicKey(priv))
+	}
+	if len(settings.TrustedSigners) == 0 {
+		fmt.Fprintln(out, "信任列表: (空)")
+		return nil
+	}
+	fmt.Fprintln(out, "信任列表:")
+	for _, pub := range settings.TrustedSigners {
+		fmt.Fprintf(out, "  %s\n", pub)
+	}
+	return nil
+}
+
+func runRemoteSigningTrust(cmd *cobra.Command, args []string) error {
+	pub := strings.TrimSpace(args[0])
+	if _, err := remote.ParsePublicKey(pub); err != nil {
+		return err
+	}
+	return updateRemoteSettings(cmd, func(settings *config.RemoteSettings) error {
+		for _, existing := range settings.TrustedSigners {
+			if existing == pub {
+				fmt.Fprintln(cmd.OutOrStdout(), "该公钥已在信任列表中")
+				return nil
+			}
+		}
+		settings.TrustedSigners = append(settings.TrustedSigners, pub)
+		fmt.Fprintln(cmd.OutOrStdout(), "✓ 已信任公钥")
+		logging.Infof("信任快照签名公钥: %s", pub)
+		return nil
+	})
+}
+
+func runRemoteSigningUntrust(cmd *cobra.Command, args []string) error {
+	pub := strings.TrimSpace(args[0])
+	return updateRemoteSettings(cmd, func(settings *config.RemoteSettings) error {
+		kept := settings.TrustedSigners[:0]
+		for _, existing := range settings.TrustedSigners {
+			if existing != pub {
+				kept = append(kept, existing)
+			}
+		}
+		if len(kept) == len(settings.TrustedSigners) {
+			return errors.New("信任列表中不存在该公钥")
+		}
+		settings.TrustedSigners = kept
+		fmt.Fprintln(cmd.OutOrStdout(), "✓ 已移除公钥")
+		logging.Infof("移除快照签名公钥: %s", pub)
+		return nil
+	})
+}
+
+// updateRemoteSettings 加载配置后修改远程设置并保存
+func updateRemoteSettings(cmd *cobra.Command, apply func(settings *config.RemoteSettings) error) error {
+	manager, err := mustLoadManager(cmd)
+	if err != nil {
+		return err
+	}
+	cfg, err := manager.Config()
+	if err != nil {
+		return err
+	}
+	if cfg.Remote == nil {
+		return errors.New("配置缺失远程字段，请重新初始化配置")
+	}
+	if err := apply(cfg.Remote); err != nil {
+		return err
+	}
+	if err := manager.ReplaceConfig(cfg); err != nil {
+		return err
+	}
+	return manager.Save()
+}
@@ -0,0 +1,279 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+	"github.com/codex-switch/codex-switch/internal/display"
+	"github.com/codex-switch/codex-switch/internal/logging"
+	"github.com/codex-switch/codex-switch/internal/remote"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	remoteSyncProfile string
+	remoteSyncPrefer  string
+	remoteSyncDryRun  bool
+)
+
+// newRemoteSyncCommand 构建 remote sync 子命令
+func newRemoteSyncCommand() *cobra.Command {
+	syncCmd := &cobra.Command{
+		Use:   "sync",
+		Short: "以上次同步的快照为基准，三方合并本地与远端的修改",
+		Long: `以 ~/.codex-switch/snapshots/<profile>.json 中上次同步的快照为合并基准，
+分别计算本地与远端新增、修改、删除的 Key 并自动合并互不冲突的修改。
+存在冲突时逐个询问，或通过 --prefer local|remote 统一处理。
+合并结果写入本地配置并上传到远端，激活的 Key 由各机器自行维护，不参与同步。`,
+		RunE: runRemoteSync,
+	}
+	syncCmd.Flags().StringVar(&remoteSyncProfile, "profile", "", "指定要同步的配置档案名")
+	syncCmd.Flags().StringVar(&remoteSyncPrefer, "prefer", "", "冲突处理策略: local 保留本地 / remote 采用远端，未指定时交互询问")
+	syncCmd.Flags().BoolVar(&remoteSyncDryRun, "dry-run", false, "仅输出合并计划，不修改本地与远端")
+	return syncCmd
+}
+
+// runRemoteSync 执行三方合并：本地配置、远端最新快照与上次同步的基准快照。
+func runRemoteSync(cmd *cobra.Command, _ []string) error {
+	prefer := strings.ToLower(strings.TrimSpace(remoteSyncPrefer))
+	if prefer != "" && prefer != remote.SideLocal && prefer != remote.SideRemote {
+		return fmt.Errorf("不支持的冲突处理策略: %s (可选 local/remote)", remoteSyncPrefer)
+	}
+
+	manager, err := mustLoadManager(cmd)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := manager.Config()
+	if err != nil {
+		return err
+	}
+
+	settings := cfg.Remote
+	if settings == nil || !settings.Enabled {
+		return errors.New("未配置远程同步，请先执行 ckm remote init")
+	}
+
+	profile := normalizeProfile(remoteSyncProfile, settings.ObjectKey)
+	if profile == "" {
+		return errors.New("未指定有效的配置档案名")
+	}
+
+	codec := snapshotCodec(settings)
+	uploadCodec, err := profileCodec(settings, profile)
+	if err != nil {
+		return err
+	}
+	basePath := buildSnapshotPath(manager.ConfigPath(), settings, profile)
+	var baseKeys []config.APIKey
+	base, err := codec.LoadSnapshotFile(basePath)
+	switch {
+	case err == nil:
+		baseKeys = base.Keys
+	case errors.Is(err, os.ErrNotExist):
+		fmt.Fprintln(cmd.OutOrStdout(), "未找到上次同步的快照，将按首次同步合并两侧的 Key")
+	case errors.Is(err, remote.ErrUnsigned):
+		// 旧版本生成的本地快照没有签名，无法作为可信基准
+		fmt.Fprintln(cmd.OutOrStdout(), "上次同步的快照未签名，将按首次同步合并两侧的 Key")
+	default:
+		return fmt.Errorf("读取同步基准失败: %w", err)
+	}
+
+	backend, err := newRemoteBackend(settings)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), 120*time.Second)
+	defer cancel()
+
+	if err := backend.Prepare(ctx); err != nil {
+		return err
+	}
+
+	remoteKeys := baseKeys
+	remoteMissing := false
+	data, err := backend.Download(ctx, buildRemoteObjectName(settings, profile))
+	switch {
+	case errors.Is(err, remote.ErrNotFound):
+		// 远端尚无快照时视为远端没有任何修改，仅上传本地结果
+		remoteMissing = true
+	case err != nil:
+		return err
+	default:
+		snap, err := codec.Decode(data)
+		if err != nil {
+			return decodeError(err)
+		}
+		remoteKeys = snap.Keys
+	}
+
+	result := remote.MergeKeys(baseKeys, remote.SyncableKeys(cfg.Keys, settings), remoteKeys)
+	out := cmd.OutOrStdout()
+	printSyncChanges(out, result)
+
+	if len(result.Conflicts) > 0 {
+		if prefer != "" {
+			for _, c := range result.Conflicts {
+				c.Resolve(prefer)
+			}
+		} else if !remoteSyncDryRun {
+			if err := promptSyncConflicts(cmd.InOrStdin(), out, result.Conflicts); err != nil {
+				return err
+			}
+		}
+	}
+
+	if remoteSyncDryRun {
+		for _, c := range result.Conflicts {
+			printSyncConflict(out, c)
+		}
+		fmt.Fprintln(out, "(dry-run) 未修改本地配置与远端快照")
+		return nil
+	}
+
+	cfg.Keys = remote.KeepLocalKeys(cfg.Keys, result.Keys(), settings)
+	settings.ObjectKey = profile
+	settings.LastSync = time.Now().UTC()
+	if err := manager.ReplaceConfig(cfg); err != nil {
+		return err
+	}
+	if err := manager.Save(); err != nil {
+		return err
+	}
+
+	merged, err := manager.Config()
+	if err != nil {
+		return err
+	}
+	snapshot := remote.BuildSnapshot(merged)
+
+	needUpload := remoteMissing || len(result.Conflicts) > 0 || len(result.ChangesFrom(remote.SideLocal)) > 0
+	if !needUpload && recipientsOutdated(data, uploadCodec) {
+		fmt.Fprintln(out, "接收者列表已变更，将重新加密远端快照")
+		needUpload = true
+	}
+	if needUpload {
+		payload, err := uploadCodec.Encode(snapshot)
+		if err != nil {
+			return err
+		}
+		versionID, err := uploadSnapshot(ctx, backend, settings, profile, payload)
+		if err != nil {
+			return fmt.Errorf("本地配置已更新，但上传合并结果失败: %w", err)
+		}
+		fmt.Fprintf(out, "✓ 已上传合并结果至 %s (版本 %s)\n", providerLabel(settings), versionID)
+	}
+
+	// 合并结果作为下次同步的基准
+	if err := codec.SaveSnapshotFile(basePath, snapshot); err != nil {
+		return err
+	}
+	settings.LastSyncHash = snapshot.ContentHash()
+	if err := manager.ReplaceConfig(cfg); err != nil {
+		return err
+	}
+	if err := manager.Save(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "✓ 同步完成，当前共 %d 个 Key\n", len(merged.Keys))
+	logging.Infof("三方同步: profile=%s changes=%d conflicts=%d uploaded=%v", profile, len(result.Changes), len(result.Conflicts), needUpload)
+	return nil
+}
+
+// printSyncChanges 输出两侧自动合并的变更
+func printSyncChanges(out io.Writer, result *remote.SyncResult) {
+	actions := map[string]string{
+		remote.ChangeAdded:   "新增",
+		remote.ChangeUpdated: "修改",
+		remote.ChangeDeleted: "删除",
+	}
+	sections := []struct {
+		side  string
+		title string
+	}{
+		{remote.SideRemote, "远端变更 (将应用到本地)"},
+		{remote.SideLocal, "本地变更 (将上传到远端)"},
+	}
+	for _, section := range sections {
+		changes := result.ChangesFrom(section.side)
+		if len(changes) == 0 {
+			continue
+		}
+		fmt.Fprintln(out, section.title+":")
+		for _, c := range changes {
+			fmt.Fprintf(out, "  %s %s\n", actions[c.Action], c.Name)
+		}
+	}
+	if len(result.Changes) == 0 && len(result.Conflicts) == 0 {
+		fmt.Fprintln(out, "本地与远端均无变更")
+	}
+}
+
+// printSyncConflict 输出冲突详情，API Key 会被脱敏
+func printSyncConflict(out io.Writer, c *remote.SyncConflict) {
+	fmt.Fprintf(out, "%s 冲突: %s\n", display.ColorWarning.Sprint("⚠"), c.Name)
+	switch {
+	case c.Local == nil:
+		fmt.Fprintln(out, "  本地已删除，远端有修改")
+	case c.Remote == nil:
+		fmt.Fprintln(out, "  远端已删除，本地有修改")
+	case len(c.Fields) == 0:
+		fmt.Fprintln(out, "  两侧新增了同名但内容不同的 Key")
+	default:
+		for _, field := range c.Fields {
+			fmt.Fprintf(out, "  %s: 本地=%s 远端=%s\n", field,
+				conflictValue(field, remote.FieldValue(c.Local, field)),
+				conflictValue(field, remote.FieldValue(c.Remote, field)))
+		}
+	}
+}
+
+// conflictValue 格式化冲突字段的值，避免在终端输出完整密钥
+func conflictValue(field string, value string) string {
+	switch {
+	case value == "":
+		return "(空)"
+	case field == "api_key":
+		return display.MaskAPIKey(value)
+	case field == "raw_config" && len(value) > 40:
+		return fmt.Sprintf("(%d 字节)", len(value))
+	default:
+		return value
+	}
+}
+
+// promptSyncConflicts 逐个询问冲突的处理方式
+func promptSyncConflicts(in io.Reader, out io.Writer, conflicts []*remote.SyncConflict) error {
+	reader := bufio.NewReader(in)
+	for _, c := range conflicts {
+		printSyncConflict(out, c)
+		for !c.Resolved() {
+			fmt.Fprint(out, "  选择 [l] 保留本地 / [r] 采用远端 / [q] 取消同步: ")
+			answer, err := reader.ReadString('\n')
+			switch strings.ToLower(strings.TrimSpace(answer)) {
+			case "l", "local":
+				c.Resolve(remote.SideLocal)
+			case "r", "remote":
+				c.Resolve(remote.SideRemote)
+			case "q", "quit":
+				return errors.New("已取消同步，本地与远端均未修改")
+			default:
+				if err != nil {
+					return errors.New("存在未解决的冲突，可使用 --prefer local|remote 自动处理")
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+	"github.com/codex-switch/codex-switch/internal/logging"
+
+	"github.com/spf13/cobra"
+)
+
+// minSyncTokenLength 为 SyncToken 的最小长度，避免使用过弱的口令派生密钥
+const minSyncTokenLength = 16
+
+var remoteTokenRotate bool
+
+// newRemoteTokenCommand 构建 remote token 子命令，用于在多台机器间共享 SyncToken
+func newRemoteTokenCommand() *cobra.Command {
+	tokenCmd := &cobra.Command{
+		Use:   "token",
+		Short: "查看或设置用于加密远程快照的 SyncToken",
+	}
+
+	showCmd := &cobra.Command{
+		Use:   "show",
+		Short: "输出当前 SyncToken，复制到其他机器执行 ckm remote token set",
+		RunE:  runRemoteTokenShow,
+	}
+
+	setCmd := &cobra.Command{
+		Use:   "set [TOKEN]",
+		Short: "设置 SyncToken，使本机可以解密其他机器推送的快照",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  runRemoteTokenSet,
+	}
+	setCmd.Flags().BoolVar(&remoteTokenRotate, "rotate", false, "生成新的随机 SyncToken")
+
+	tokenCmd.AddCommand(showCmd, setCmd)
+	return tokenCmd
+}
+
+func runRemoteTokenShow(cmd *cobra.Command, _ []string) error {
+	manager, err := mustLoadManager(cmd)
+	if err != nil {
+		return err
+	}
+	cfg, err := manager.Config()
+	if err != nil {
+		return err
+	}
+	if cfg.Remote == nil || strings.TrimSpace(cfg.Remote.SyncToken) == "" {
+		return errors.New("尚未生成 SyncToken")
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), cfg.Remote.SyncToken)
+	logging.Infof("输出 SyncToken")
+	return nil
+}
+
+func runRemoteTokenSet(cmd *cobra.Command, args []string) error {
+	var token string
+	switch {
+	case remoteTokenRotate && len(args) > 0:
+		return errors.New("--rotate 与 TOKEN 参数不能同时使用")
+	case remoteTokenRotate:
+		token = config.GenerateSyncToken()
+	case len(args) == 1:
+		token = strings.TrimSpace(args[0])
+	default:
+		return errors.New("请提供 TOKEN 参数或使用 --rotate")
+	}
+	if len(token) < minSyncTokenLength {
+		return fmt.Errorf("SyncToken 长度不能少于 %d 个字符", minSyncTokenLength)
+	}
+
+	manager, err := mustLoadManager(cmd)
+	if err != nil {
+		return err
+	}
+	cfg, err := manager.Config()
+	if err != nil {
+		return err
+	}
+	if cfg.Remote == nil {
+		return errors.New("配置缺失远程字段，请重新初始化配置")
+	}
+
+	cfg.Remote.SyncToken = token
+	if err := manager.ReplaceConfig(cfg); err != nil {
+		return err
+	}
+	if err := manager.Save(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(cmd.OutOrStdout(), "✓ 已更新 SyncToken")
+	if remoteTokenRotate {
+		fmt.Fprintln(cmd.OutOrStdout(), "新 token 需要同步到其他机器，旧 token 加密的远程快照将无法再解密，请重新执行 ckm remote push")
+	}
+	logging.Warnf("更新 SyncToken, rotate=%t", remoteTokenRotate)
+	return nil
+}
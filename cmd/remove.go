@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/codex-switch/codex-switch/internal/config"
+	"github.com/codex-switch/codex-switch/internal/hooks"
 	"github.com/codex-switch/codex-switch/internal/logging"
 
 	"github.com/fatih/color"
@@ -68,5 +70,7 @@ func runRemove(cmd *cobra.Command, args []string) error {
 		color.New(color.FgCyan, color.Bold).Sprint(key.Name),
 		color.New(color.FgHiBlack).Sprint(key.ID))
 	logging.Warnf("删除 Key: %s (%s)", key.Name, key.ID)
+	runPostHook(cmd, manager, hooks.Payload{Event: config.HookPostRemove, Key: hooks.NewKeyInfo(key)})
+	autoPush(cmd, manager)
 	return nil
 }
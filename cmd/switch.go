@@ -5,13 +5,15 @@ import (
 	"strings"
 
 	"github.com/codex-switch/codex-switch/internal/config"
-	codex "github.com/codex-switch/codex-switch/internal/integration/codex"
+	"github.com/codex-switch/codex-switch/internal/hooks"
 	"github.com/codex-switch/codex-switch/internal/logging"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
+var switchDryRun bool
+
 func init() {
 	switchCmd := &cobra.Command{
 		Use:   "switch <ID|NAME>",
@@ -19,6 +21,7 @@ func init() {
 		Args:  cobra.ExactArgs(1),
 		RunE:  runSwitch,
 	}
+	switchCmd.Flags().BoolVar(&switchDryRun, "dry-run", false, "仅预览各集成目标将写入的内容，不做任何修改")
 
 	RootCommand().AddCommand(switchCmd)
 }
@@ -31,14 +34,39 @@ func runSwitch(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	var key config.APIKey
-	key, err = manager.GetKey(target)
+	key, err := lookupKey(manager, target)
+	if err != nil {
+		return err
+	}
+	if switchDryRun {
+		return previewTargets(cmd, manager, key)
+	}
+	return switchToKey(cmd, manager, key)
+}
+
+// lookupKey 按 ID 或名称查找 Key
+func lookupKey(manager *config.Manager, ref string) (config.APIKey, error) {
+	key, err := manager.GetKey(ref)
 	if err != nil {
-		key, err = manager.GetKeyByName(target)
+		key, err = manager.GetKeyByName(ref)
 		if err != nil {
-			return fmt.Errorf("未找到 ID 或名称为 %s 的 Key", target)
+			return config.APIKey{}, fmt.Errorf("未找到 ID 或名称为 %s 的 Key", ref)
 		}
 	}
+	return key, nil
+}
+
+// switchToKey 激活 key 并同步全部已启用的集成目标，输出切换结果。
+// pre_switch hook 失败时不做任何修改，post_switch hook 失败仅输出警告。
+func switchToKey(cmd *cobra.Command, manager *config.Manager, key config.APIKey) error {
+	payload := hooks.Payload{Event: config.HookPreSwitch, Key: hooks.NewKeyInfo(key)}
+	if previous, err := manager.ActiveKey(); err == nil {
+		payload.Previous = hooks.NewKeyInfo(previous)
+	}
+	if err := runHook(cmd, manager, payload); err != nil {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("已取消切换: %w", err)
+	}
 
 	if err := manager.SetActiveKey(key.ID); err != nil {
 		return err
@@ -55,22 +83,27 @@ func runSwitch(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	configurator, err := codex.NewConfigurator("", "")
+	cfg, err := manager.Config()
 	if err != nil {
-		return fmt.Errorf("初始化 Codex 配置失败: %w", err)
-	}
-	if err := configurator.Apply(activeKey); err != nil {
-		return fmt.Errorf("同步 Codex 配置失败: %w", err)
+		return err
 	}
+	results := applyTargets(cfg, activeKey)
 
 	success := color.New(color.FgGreen, color.Bold).Sprint("✓")
 	nameText := color.New(color.FgCyan, color.Bold).Sprint(key.Name)
 	idText := color.New(color.FgHiBlack).Sprint(key.ID)
 	fmt.Fprintf(cmd.OutOrStdout(), "%s 已切换到: %s %s\n", success, nameText, idText)
 	fmt.Fprintf(cmd.OutOrStdout(), "  类型: %s\n", color.New(color.FgMagenta).Sprint(strings.ToUpper(key.Type)))
-	fmt.Fprintf(cmd.OutOrStdout(), "  Codex 配置: %s\n", color.New(color.FgGreen).Sprint("已同步"))
+	failed := printTargetResults(cmd.OutOrStdout(), results)
 
 	logging.Infof("切换 Key 至 %s (%s)", key.Name, key.ID)
 
+	payload.Event = config.HookPostSwitch
+	runPostHook(cmd, manager, payload)
+
+	if failed > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d 个集成目标同步失败", failed)
+	}
 	return nil
 }
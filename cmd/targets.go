@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+	"github.com/codex-switch/codex-switch/internal/display"
+	"github.com/codex-switch/codex-switch/internal/integration"
+	_ "github.com/codex-switch/codex-switch/internal/integration/aider"
+	_ "github.com/codex-switch/codex-switch/internal/integration/codex"
+	"github.com/codex-switch/codex-switch/internal/logging"
+
+	"github.com/spf13/cobra"
+)
+
+var targetsKey string
+
+func init() {
+	targetsCmd := &cobra.Command{
+		Use:   "targets",
+		Short: "管理 ckm switch 时同步配置的工具(集成目标)",
+	}
+	targetsCmd.PersistentFlags().StringVar(&targetsKey, "key", "", "仅针对指定 ID 或名称的 Key，默认修改全局配置")
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "列出全部集成目标及其启用状态",
+		Args:  cobra.NoArgs,
+		RunE:  runTargetsList,
+	}
+	enableCmd := &cobra.Command{
+		Use:   "enable NAME",
+		Short: "启用集成目标",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runTargetsEnable,
+	}
+	disableCmd := &cobra.Command{
+		Use:   "disable NAME",
+		Short: "停用集成目标",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runTargetsDisable,
+	}
+
+	targetsCmd.AddCommand(listCmd, enableCmd, disableCmd)
+	RootCommand().AddCommand(targetsCmd)
+}
+
+func runTargetsList(cmd *cobra.Command, _ []string) error {
+	manager, err := mustLoadManager(cmd)
+	if err != nil {
+		return err
+	}
+	cfg, err := manager.Config()
+	if err != nil {
+		return err
+	}
+	var key config.APIKey
+	scope := "全局"
+	if targetsKey != "" {
+		if key, err = lookupKey(manager, strings.TrimSpace(targetsKey)); err != nil {
+			return err
+		}
+		scope = key.Name
+		if len(key.Targets) == 0 {
+			scope += " (沿用全局配置)"
+		}
+	}
+	enabled := integration.EnabledTargets(cfg, key)
+
+	out := cmd.OutOrStdout()
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "名称\t已安装\t已启用")
+	for _, name := range integration.Names() {
+		detected := "-"
+		if target, err := integration.Lookup(name); err == nil && target.Detect() {
+			detected = "是"
+		}
+		state := "否"
+		if slices.Contains(enabled, name) {
+			state = display.ColorSuccess.Sprint("是")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", name, detected, state)
+	}
+	w.Flush()
+	fmt.Fprintf(out, "\n启用范围: %s\n", scope)
+	return nil
+}
+
+func runTargetsEnable(cmd *cobra.Command, args []string) error {
+	name := strings.TrimSpace(args[0])
+	return updateTargets(cmd, name, func(enabled []string) ([]string, error) {
+		if slices.Contains(enabled, name) {
+			return nil, fmt.Errorf("集成目标 %s 已启用", name)
+		}
+		return append(enabled, name), nil
+	}, "✓ 已启用集成目标 "+name)
+}
+
+func runTargetsDisable(cmd *cobra.Command, args []string) error {
+	name := strings.TrimSpace(args[0])
+	return updateTargets(cmd, name, func(enabled []string) ([]string, error) {
+		index := slices.Index(enabled, name)
+		if index < 0 {
+			return nil, fmt.Errorf("集成目标 %s 未启用", name)
+		}
+		// 列表为空时会回退为默认目标，因此不允许停用最后一个
+		if len(enabled) == 1 {
+			return nil, errors.New("至少需要保留一个启用的集成目标")
+		}
+		return slices.Delete(enabled, index, index+1), nil
+	}, "✓ 已停用集成目标 "+name)
+}
+
+// updateTargets 修改启用的目标列表并保存：指定 --key 时写入该 Key，否则写入全局配置
+func updateTargets(cmd *cobra.Command, name string, apply func([]string) ([]string, error), message string) error {
+	if !slices.Contains(integration.Names(), name) {
+		return fmt.Errorf("未知的集成目标 %s，可选 %s", name, strings.Join(integration.Names(), "/"))
+	}
+	manager, err := mustLoadManager(cmd)
+	if err != nil {
+		return err
+	}
+	cfg, err := manager.Config()
+	if err != nil {
+		return err
+	}
+
+	if targetsKey != "" {
+		key, err := lookupKey(manager, strings.TrimSpace(targetsKey))
+		if err != nil {
+			return err
+		}
+		targets, err := apply(integration.EnabledTargets(cfg, key))
+		if err != nil {
+			return err
+		}
+		key.Targets = targets
+		if err := manager.UpdateKey(key); err != nil {
+			return err
+		}
+		message += fmt.Sprintf(" (Key: %s)", key.Name)
+		logging.Infof("更新集成目标: key=%s targets=%v", key.ID, targets)
+	} else {
+		targets, err := apply(integration.EnabledTargets(cfg, config.APIKey{}))
+		if err != nil {
+			return err
+		}
+		cfg.Targets = targets
+		if err := manager.ReplaceConfig(cfg); err != nil {
+			return err
+		}
+		logging.Infof("更新集成目标: targets=%v", targets)
+	}
+
+	if err := manager.Save(); err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), message)
+	return nil
+}
+
+// targetResult 为单个集成目标的同步结果
+type targetResult struct {
+	name string
+	err  error
+}
+
+// applyTargets 将 key 写入全部已启用的目标，单个目标失败不影响其他目标
+func applyTargets(cfg *config.Config, key config.APIKey) []targetResult {
+	var results []targetResult
+	for _, name := range integration.EnabledTargets(cfg, key) {
+		target, err := integration.Lookup(name)
+		if err == nil {
+			err = target.Apply(key)
+		}
+		if err != nil {
+			logging.Warnf("同步集成目标失败: target=%s err=%v", name, err)
+		}
+		results = append(results, targetResult{name: name, err: err})
+	}
+	return results
+}
+
+// printTargetResults 输出各目标的同步结果，返回失败数量
+func printTargetResults(out io.Writer, results []targetResult) int {
+	failed := 0
+	for _, result := range results {
+		if result.err != nil {
+			failed++
+			fmt.Fprintf(out, "  %s 配置: %s %v\n", result.name, display.ColorError.Sprint("同步失败"), result.err)
+			continue
+		}
+		fmt.Fprintf(out, "  %s 配置: %s\n", result.name, display.ColorSuccess.Sprint("已同步"))
+	}
+	return failed
+}
+
+// previewTargets 输出切换到 key 时各目标将写入的内容，不做任何修改
+func previewTargets(cmd *cobra.Command, manager *config.Manager, key config.APIKey) error {
+	cfg, err := manager.Config()
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "切换到 %s 时将做出以下修改 (预览，未写入任何文件):\n", key.Name)
+	for _, name := range integration.EnabledTargets(cfg, key) {
+		target, err := integration.Lookup(name)
+		if err != nil {
+			return err
+		}
+		changes, err := target.Plan(key)
+		if err != nil {
+			return fmt.Errorf("生成 %s 预览失败: %w", name, err)
+		}
+		for _, change := range changes {
+			fmt.Fprintf(out, "\n[%s] %s\n  %s\n", name, change.Path, change.Summary)
+			fmt.Fprintln(out, strings.Repeat("-", 40))
+			fmt.Fprint(out, change.Preview)
+			if !strings.HasSuffix(change.Preview, "\n") {
+				fmt.Fprintln(out)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+	"github.com/codex-switch/codex-switch/internal/display"
+	"github.com/codex-switch/codex-switch/internal/logging"
+	"github.com/codex-switch/codex-switch/internal/remote"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	teamCmd := &cobra.Command{
+		Use:   "team",
+		Short: "订阅远端的团队配置档案，其 Key 以只读方式叠加在本地 Key 之上",
+	}
+
+	subscribeCmd := &cobra.Command{
+		Use:   "subscribe <PROFILE>",
+		Short: "订阅团队配置档案并下载其 Key",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runTeamSubscribe,
+	}
+	unsubscribeCmd := &cobra.Command{
+		Use:   "unsubscribe <PROFILE>",
+		Short: "取消订阅团队配置档案并删除本地缓存",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runTeamUnsubscribe,
+	}
+	refreshCmd := &cobra.Command{
+		Use:   "refresh [PROFILE...]",
+		Short: "从远端重新获取团队配置，未指定时刷新全部订阅",
+		RunE:  runTeamRefresh,
+	}
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "列出已订阅的团队配置档案",
+		Args:  cobra.NoArgs,
+		RunE:  runTeamList,
+	}
+
+	teamCmd.AddCommand(subscribeCmd, unsubscribeCmd, refreshCmd, listCmd)
+	RootCommand().AddCommand(teamCmd)
+}
+
+func runTeamSubscribe(cmd *cobra.Command, args []string) error {
+	manager, settings, backend, err := openRemoteBackend(cmd)
+	if err != nil {
+		return err
+	}
+	profile := normalizeProfile(args[0], "")
+	if profile == normalizeProfile("", settings.ObjectKey) {
+		return fmt.Errorf("%s 为当前同步的个人配置档案，不能作为团队配置订阅", profile)
+	}
+	if slices.Contains(settings.TeamProfiles, profile) {
+		return fmt.Errorf("已订阅团队配置 %s，可执行 ckm team refresh 更新", profile)
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), 60*time.Second)
+	defer cancel()
+
+	if err := backend.Prepare(ctx); err != nil {
+		return err
+	}
+	overlay, err := fetchTeamOverlay(ctx, backend, settings, profile)
+	if err != nil {
+		return err
+	}
+	if err := overlay.Save(config.TeamOverlayPath(manager.ConfigPath(), settings.Name(), profile)); err != nil {
+		return fmt.Errorf("写入团队配置缓存失败: %w", err)
+	}
+	if err := updateRemoteSettings(cmd, func(s *config.RemoteSettings) error {
+		s.TeamProfiles = append(s.TeamProfiles, profile)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "✓ 已订阅团队配置 %s，共 %d 个只读 Key\n", profile, len(overlay.Keys))
+	fmt.Fprintf(cmd.OutOrStdout(), "  可通过 ckm switch %s 切换，ckm team refresh 更新\n", config.TeamKeyID(profile, "<ID>"))
+	logging.Infof("订阅团队配置: profile=%s keys=%d", profile, len(overlay.Keys))
+	return nil
+}
+
+func runTeamUnsubscribe(cmd *cobra.Command, args []string) error {
+	manager, err := mustLoadManager(cmd)
+	if err != nil {
+		return err
+	}
+	cfg, err := manager.Config()
+	if err != nil {
+		return err
+	}
+	profile := normalizeProfile(args[0], "")
+	if cfg.Remote == nil || !slices.Contains(cfg.Remote.TeamProfiles, profile) {
+		return fmt.Errorf("未订阅团队配置 %s", profile)
+	}
+
+	if err := updateRemoteSettings(cmd, func(s *config.RemoteSettings) error {
+		s.TeamProfiles = slices.DeleteFunc(s.TeamProfiles, func(p string) bool { return p == profile })
+		return nil
+	}); err != nil {
+		return err
+	}
+	if err := os.Remove(config.TeamOverlayPath(manager.ConfigPath(), cfg.Remote.Name(), profile)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		logging.Warnf("删除团队配置缓存失败: %v", err)
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "✓ 已取消订阅团队配置 %s\n", profile)
+	logging.Infof("取消订阅团队配置: profile=%s", profile)
+	return nil
+}
+
+func runTeamRefresh(cmd *cobra.Command, args []string) error {
+	manager, settings, backend, err := openRemoteBackend(cmd)
+	if err != nil {
+		return err
+	}
+	profiles := settings.TeamProfiles
+	if len(args) > 0 {
+		profiles = nil
+		for _, arg := range args {
+			profile := normalizeProfile(arg, "")
+			if !slices.Contains(settings.TeamProfiles, profile) {
+				return fmt.Errorf("未订阅团队配置 %s，请先执行 ckm team subscribe %s", profile, profile)
+			}
+			profiles = append(profiles, profile)
+		}
+	}
+	out := cmd.OutOrStdout()
+	if len(profiles) == 0 {
+		fmt.Fprintln(out, "尚未订阅团队配置，可执行 ckm team subscribe <PROFILE>")
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), 120*time.Second)
+	defer cancel()
+
+	if err := backend.Prepare(ctx); err != nil {
+		return err
+	}
+
+	// 刷新失败时保留原有缓存，避免网络异常导致团队 Key 消失
+	failed := 0
+	for _, profile := range profiles {
+		overlay, err := fetchTeamOverlay(ctx, backend, settings, profile)
+		if err == nil {
+			err = overlay.Save(config.TeamOverlayPath(manager.ConfigPath(), settings.Name(), profile))
+		}
+		if err != nil {
+			failed++
+			fmt.Fprintf(out, "%s %s: 刷新失败: %v\n", display.ColorWarning.Sprint("⚠"), profile, err)
+			continue
+		}
+		if err := manager.SetTeamOverlay(overlay); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "✓ %s: %d 个 Key\n", profile, len(overlay.Keys))
+		logging.Infof("刷新团队配置: profile=%s keys=%d", profile, len(overlay.Keys))
+	}
+
+	// 激活的团队 Key 被移除时 Manager 会回退到本地 Key，需要写回配置
+	if err := manager.Save(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d 个团队配置未能刷新", failed)
+	}
+	return nil
+}
+
+func runTeamList(cmd *cobra.Command, _ []string) error {
+	manager, err := mustLoadManager(cmd)
+	if err != nil {
+		return err
+	}
+	cfg, err := manager.Config()
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	if cfg.Remote == nil || len(cfg.Remote.TeamProfiles) == 0 {
+		fmt.Fprintln(out, "尚未订阅团队配置，可执行 ckm team subscribe <PROFILE>")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "档案\tKey 数量\t最后刷新")
+	for _, profile := range cfg.Remote.TeamProfiles {
+		keys, refreshed := "-", "(未缓存)"
+		if overlay, err := config.LoadTeamOverlay(config.TeamOverlayPath(manager.ConfigPath(), cfg.Remote.Name(), profile)); err == nil {
+			keys = fmt.Sprintf("%d", len(overlay.Keys))
+			refreshed = overlay.RefreshedAt.Local().Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", profile, keys, refreshed)
+	}
+	return w.Flush()
+}
+
+// fetchTeamOverlay 下载并解析团队配置档案的最新快照
+func fetchTeamOverlay(ctx context.Context, backend remote.Backend, settings *config.RemoteSettings, profile string) (*config.TeamOverlay, error) {
+	data, err := backend.Download(ctx, remote.LatestObjectName(profile))
+	if err != nil {
+		if errors.Is(err, remote.ErrNotFound) {
+			return nil, fmt.Errorf("远端不存在配置档案 %s，可执行 ckm remote list 查看", profile)
+		}
+		return nil, err
+	}
+	snap, err := snapshotCodec(settings).Decode(data)
+	if err != nil {
+		if errors.Is(err, remote.ErrDecrypt) || errors.Is(err, remote.ErrRecipientDecrypt) || errors.Is(err, remote.ErrNotRecipient) {
+			return nil, fmt.Errorf("%w，团队配置需使用相同的 SyncToken 或将本机身份加入接收者列表", err)
+		}
+		return nil, err
+	}
+	return &config.TeamOverlay{
+		Profile:     profile,
+		RefreshedAt: time.Now().UTC(),
+		Keys:        snap.Keys,
+	}, nil
+}
@@ -17,6 +17,7 @@ var (
 	updateAPIKey     string
 	updateTags       string
 	updateConfigPath string
+	updateLocalOnly  bool
 )
 
 func init() {
@@ -32,6 +33,7 @@ func init() {
 	updateCmd.Flags().StringVar(&updateAPIKey, "set-key", "", "新的 API Key")
 	updateCmd.Flags().StringVar(&updateTags, "set-tags", "", "重置标签(逗号分隔)")
 	updateCmd.Flags().StringVar(&updateConfigPath, "set-config-file", "", "指定配置文件路径，使用文件内容完整替换 Codex config.toml")
+	updateCmd.Flags().BoolVar(&updateLocalOnly, "local-only", false, "是否仅保存在本机、不参与远程同步 (--local-only=false 恢复同步)")
 
 	RootCommand().AddCommand(updateCmd)
 }
@@ -66,6 +68,9 @@ func runUpdate(cmd *cobra.Command, _ []string) error {
 	if cmd.Flags().Lookup("set-tags").Changed {
 		updated.Tags = normalizeTags(updateTags)
 	}
+	if cmd.Flags().Lookup("local-only").Changed {
+		updated.LocalOnly = updateLocalOnly
+	}
 	if cmd.Flags().Lookup("set-config-file").Changed {
 		rawConfig, err := loadRawConfigContent(updateConfigPath)
 		if err != nil {
@@ -82,5 +87,6 @@ func runUpdate(cmd *cobra.Command, _ []string) error {
 
 	fmt.Fprintf(cmd.OutOrStdout(), "✓ 已更新 Key: %s\n", updated.Name)
 	logging.Infof("更新 Key: %s (%s)", updated.Name, updated.ID)
+	autoPush(cmd, manager)
 	return nil
 }
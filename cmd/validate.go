@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+	"github.com/codex-switch/codex-switch/internal/logging"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	validateFile   string
+	validateFormat string
+	validateOutput string
+)
+
+func init() {
+	validateCmd := &cobra.Command{
+		Use:   "validate",
+		Short: "校验配置文件，存在错误时返回非零退出码",
+		RunE:  runValidate,
+	}
+
+	validateCmd.Flags().StringVar(&validateFile, "file", "", "待校验的配置文件，默认校验当前配置")
+	validateCmd.Flags().StringVar(&validateFormat, "format", "", "配置格式，默认根据扩展名推断")
+	validateCmd.Flags().StringVar(&validateOutput, "output", "text", "输出格式: text/json")
+
+	RootCommand().AddCommand(validateCmd)
+}
+
+func runValidate(cmd *cobra.Command, _ []string) error {
+	path := strings.TrimSpace(validateFile)
+	if path == "" {
+		path = viper.ConfigFileUsed()
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	format := validateFormat
+	if format == "" {
+		format = inferFormat(path)
+	}
+	cfg, err := decodeConfig(data, format)
+	if err != nil {
+		return fmt.Errorf("解析配置失败: %w", err)
+	}
+
+	issues := config.Validate(cfg)
+	if validateOutput == "json" {
+		if issues == nil {
+			issues = []config.Issue{}
+		}
+		out, err := json.MarshalIndent(issues, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(out))
+	} else {
+		printIssues(cmd.OutOrStdout(), issues)
+		if len(issues) == 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s %s 校验通过，共 %d 个 Key\n",
+				color.New(color.FgGreen, color.Bold).Sprint("✓"), path, len(cfg.Keys))
+		}
+	}
+
+	logging.Infof("校验配置: %s 问题数: %d", path, len(issues))
+	if config.HasErrors(issues) {
+		cmd.SilenceUsage = true
+		return errors.New("配置校验未通过")
+	}
+	return nil
+}
+
+// printIssues 按级别着色输出校验问题
+func printIssues(out io.Writer, issues []config.Issue) {
+	for _, issue := range issues {
+		mark := color.New(color.FgYellow, color.Bold).Sprint("⚠")
+		if issue.Severity == config.SeverityError {
+			mark = color.New(color.FgRed, color.Bold).Sprint("✗")
+		}
+		fmt.Fprintf(out, "%s %s %s\n", mark, color.New(color.FgHiBlack).Sprint(issue.Path), issue.Message)
+	}
+}
@@ -3,12 +3,15 @@ package config
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/codex-switch/codex-switch/internal/logging"
+
 	"github.com/google/uuid"
 )
 
@@ -41,16 +44,35 @@ type APIKey struct {
 	EnvKey              string    `json:"env_key,omitempty"`
 	RequiresOpenAIAuth  *bool     `json:"requires_openai_auth,omitempty"`
 	RawConfig           string    `json:"raw_config,omitempty"`
+	// LocalOnly 为 true 时该 Key 仅保存在本机，不参与远程同步
+	LocalOnly bool `json:"local_only,omitempty"`
+	// Source 标记 Key 的来源，团队配置叠加的 Key 为 team:<profile>，本地 Key 为空
+	Source string `json:"source,omitempty"`
+	// Targets 为切换到该 Key 时同步的集成目标，为空时使用全局配置
+	Targets []string `json:"targets,omitempty"`
 }
 
 // Config 表示配置文件的顶层结构
 type Config struct {
-	Version     string          `json:"version"`
-	ActiveKeyID string          `json:"active_key_id"`
-	Keys        []APIKey        `json:"keys"`
-	LastUpdated time.Time       `json:"last_updated"`
-	NextID      int             `json:"next_id,omitempty"`
-	Remote      *RemoteSettings `json:"remote,omitempty"`
+	Version     string    `json:"version"`
+	ActiveKeyID string    `json:"active_key_id"`
+	Keys        []APIKey  `json:"keys"`
+	LastUpdated time.Time `json:"last_updated"`
+	NextID      int       `json:"next_id,omitempty"`
+	// Remote 为当前选择的远程(默认远程或 SelectRemote 指定的远程)，与 Remotes 中的对应项为同一对象。
+	// 旧版本配置只有该字段，加载时迁移为名为 origin 的远程；存在 Remotes 时不再写入配置文件。
+	Remote *RemoteSettings `json:"remote,omitempty"`
+	// Remotes 按名称保存全部远程配置
+	Remotes map[string]*RemoteSettings `json:"remotes,omitempty"`
+	// DefaultRemote 为未指定 --remote 时使用的远程名称
+	DefaultRemote string `json:"default_remote,omitempty"`
+	// Hooks 为 Key 变化时执行的用户脚本
+	Hooks *HookSettings `json:"hooks,omitempty"`
+	// Targets 为 ckm switch 同步的集成目标，为空时仅同步 Codex
+	Targets []string `json:"targets,omitempty"`
+
+	// remoteName 为 Remote 对应的远程名称，为空表示默认远程
+	remoteName string
 }
 
 // Manager 负责管理配置的读写及业务逻辑
@@ -59,6 +81,9 @@ type Manager struct {
 	mu      sync.RWMutex
 	cfg     *Config
 	loaded  bool
+	issues  []Issue
+	// overlay 为订阅的团队配置叠加的只读 Key，仅存在于内存中，不会写入配置文件
+	overlay []APIKey
 }
 
 // NewDefaultManager 根据路径创建默认文件存储的管理器
@@ -89,6 +114,12 @@ func (m *Manager) Load() (*Config, error) {
 		return nil, err
 	}
 
+	// 加载阶段仅记录问题，不阻断使用，具体修正交由后续归一化逻辑
+	m.issues = Validate(cfg)
+	for _, issue := range m.issues {
+		logging.Warnf("配置校验: %s", issue)
+	}
+
 	if cfg.Version == "" {
 		cfg.Version = defaultVersion
 	}
@@ -102,10 +133,12 @@ func (m *Manager) Load() (*Config, error) {
 	}
 	for i := range cfg.Keys {
 		setIntegrationDefaults(&cfg.Keys[i])
+		cfg.Keys[i].Source = ""
 	}
 
 	m.cfg = cfg
 	m.loaded = true
+	m.loadTeamOverlaysLocked()
 	if changed {
 		cfg.LastUpdated = time.Now().UTC()
 		_ = m.storage.Save(cfg)
@@ -113,6 +146,13 @@ func (m *Manager) Load() (*Config, error) {
 	return cfg, nil
 }
 
+// Issues 返回最近一次 Load 时发现的配置问题
+func (m *Manager) Issues() []Issue {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]Issue(nil), m.issues...)
+}
+
 // Save 将当前配置持久化到磁盘
 func (m *Manager) Save() error {
 	m.mu.Lock()
@@ -148,6 +188,7 @@ func (m *Manager) ReplaceConfig(cfg *Config) error {
 	}
 	for i := range cfg.Keys {
 		setIntegrationDefaults(&cfg.Keys[i])
+		cfg.Keys[i].Source = ""
 	}
 
 	activeID := cfg.ActiveKeyID
@@ -162,7 +203,8 @@ func (m *Manager) ReplaceConfig(cfg *Config) error {
 		}
 	}
 
-	if activeCount == 0 && len(cfg.Keys) > 0 {
+	// 激活的是团队配置中的 Key 时保持不变，由 loadTeamOverlaysLocked 校验其是否仍存在
+	if activeCount == 0 && len(cfg.Keys) > 0 && !IsTeamKeyID(activeID) {
 		cfg.Keys[0].Active = true
 		activeID = cfg.Keys[0].ID
 	}
@@ -175,6 +217,7 @@ func (m *Manager) ReplaceConfig(cfg *Config) error {
 	cfg.LastUpdated = time.Now().UTC()
 	m.cfg = cfg
 	m.loaded = true
+	m.loadTeamOverlaysLocked()
 	if changed {
 		_ = m.storage.Save(cfg)
 	}
@@ -192,9 +235,21 @@ func (m *Manager) Config() (*Config, error) {
 
 	cfgCopy := *m.cfg
 	cfgCopy.Keys = append([]APIKey(nil), m.cfg.Keys...)
+	cfgCopy.Remotes = maps.Clone(m.cfg.Remotes)
 	return &cfgCopy, nil
 }
 
+// UseRemote 选择后续 Config().Remote 对应的远程，用于 --remote 参数；不修改默认远程
+func (m *Manager) UseRemote(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.loaded {
+		return errors.New("配置尚未加载")
+	}
+	return m.cfg.SelectRemote(name)
+}
+
 // ConfigPath 返回当前存储实现使用的配置文件路径，方便外部功能定位目录。
 func (m *Manager) ConfigPath() string {
 	m.mu.RLock()
@@ -265,6 +320,9 @@ func (m *Manager) UpdateKey(updated APIKey) error {
 	if !m.loaded {
 		return errors.New("配置尚未加载")
 	}
+	if IsTeamKeyID(updated.ID) {
+		return fmt.Errorf("%w: %s", ErrReadOnlyKey, updated.ID)
+	}
 
 	idx := -1
 	for i, k := range m.cfg.Keys {
@@ -317,6 +375,9 @@ func (m *Manager) RemoveKey(id string) error {
 	if !m.loaded {
 		return errors.New("配置尚未加载")
 	}
+	if IsTeamKeyID(id) {
+		return fmt.Errorf("%w: %s", ErrReadOnlyKey, id)
+	}
 
 	idx := -1
 	for i, k := range m.cfg.Keys {
@@ -362,6 +423,16 @@ func (m *Manager) setActiveKeyLocked(id string) error {
 		}
 	}
 
+	for i, k := range m.overlay {
+		if k.ID == id {
+			found = true
+			m.overlay[i].Active = true
+			m.cfg.ActiveKeyID = id
+		} else {
+			m.overlay[i].Active = false
+		}
+	}
+
 	if !found {
 		return fmt.Errorf("未找到 ID %s", id)
 	}
@@ -377,9 +448,11 @@ func (m *Manager) GetKey(id string) (APIKey, error) {
 		return APIKey{}, errors.New("配置尚未加载")
 	}
 
-	for _, k := range m.cfg.Keys {
-		if k.ID == id {
-			return k, nil
+	for _, keys := range [][]APIKey{m.cfg.Keys, m.overlay} {
+		for _, k := range keys {
+			if k.ID == id {
+				return k, nil
+			}
 		}
 	}
 	return APIKey{}, fmt.Errorf("未找到 ID %s", id)
@@ -394,9 +467,12 @@ func (m *Manager) GetKeyByName(name string) (APIKey, error) {
 		return APIKey{}, errors.New("配置尚未加载")
 	}
 
-	for _, k := range m.cfg.Keys {
-		if strings.EqualFold(k.Name, name) {
-			return k, nil
+	// 本地 Key 优先于同名的团队 Key
+	for _, keys := range [][]APIKey{m.cfg.Keys, m.overlay} {
+		for _, k := range keys {
+			if strings.EqualFold(k.Name, name) {
+				return k, nil
+			}
 		}
 	}
 	return APIKey{}, fmt.Errorf("未找到名称 %s", name)
@@ -410,7 +486,7 @@ func (m *Manager) ActiveKey() (APIKey, error) {
 	return m.GetKey(m.cfg.ActiveKeyID)
 }
 
-// ListKeys 返回排序后的 Key 列表
+// ListKeys 返回排序后的 Key 列表，包含团队配置叠加的只读 Key
 func (m *Manager) ListKeys(sortBy string) ([]APIKey, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -419,7 +495,7 @@ func (m *Manager) ListKeys(sortBy string) ([]APIKey, error) {
 		return nil, errors.New("配置尚未加载")
 	}
 
-	items := append([]APIKey(nil), m.cfg.Keys...)
+	items := append(append([]APIKey(nil), m.cfg.Keys...), m.overlay...)
 
 	switch sortBy {
 	case "name":
@@ -453,10 +529,95 @@ func (m *Manager) TouchKey(id string) error {
 			return nil
 		}
 	}
+	// 团队 Key 的使用时间仅记录在内存中
+	for i, k := range m.overlay {
+		if k.ID == id {
+			m.overlay[i].LastUsed = time.Now().UTC()
+			return nil
+		}
+	}
 
 	return fmt.Errorf("未找到 ID %s", id)
 }
 
+// SetTeamOverlay 替换指定团队配置档案叠加的 Key，通常在刷新缓存后调用
+func (m *Manager) SetTeamOverlay(overlay *TeamOverlay) error {
+	if overlay == nil {
+		return errors.New("团队配置为空")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.loaded {
+		return errors.New("配置尚未加载")
+	}
+	m.dropTeamOverlayLocked(overlay.Profile)
+	m.overlay = append(m.overlay, overlayKeys(overlay)...)
+	m.syncOverlayActiveLocked()
+	return nil
+}
+
+// RemoveTeamOverlay 移除指定团队配置档案叠加的 Key
+func (m *Manager) RemoveTeamOverlay(profile string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.loaded {
+		return errors.New("配置尚未加载")
+	}
+	m.dropTeamOverlayLocked(profile)
+	m.syncOverlayActiveLocked()
+	return nil
+}
+
+func (m *Manager) dropTeamOverlayLocked(profile string) {
+	source := TeamSource(profile)
+	kept := m.overlay[:0]
+	for _, k := range m.overlay {
+		if k.Source != source {
+			kept = append(kept, k)
+		}
+	}
+	m.overlay = kept
+}
+
+// loadTeamOverlaysLocked 从缓存加载默认远程中已订阅的团队配置，缓存缺失时仅记录警告
+func (m *Manager) loadTeamOverlaysLocked() {
+	m.overlay = nil
+	if settings := m.cfg.Remotes[m.cfg.DefaultRemote]; settings != nil {
+		for _, profile := range settings.TeamProfiles {
+			overlay, err := LoadTeamOverlay(TeamOverlayPath(m.storage.Path(), m.cfg.DefaultRemote, profile))
+			if err != nil {
+				logging.Warnf("读取团队配置 %s 的缓存失败，可执行 ckm team refresh 重新获取: %v", profile, err)
+				continue
+			}
+			overlay.Profile = profile
+			m.overlay = append(m.overlay, overlayKeys(overlay)...)
+		}
+	}
+	m.syncOverlayActiveLocked()
+}
+
+// syncOverlayActiveLocked 同步团队 Key 的激活状态，激活的团队 Key 已不存在时改为激活首个本地 Key
+func (m *Manager) syncOverlayActiveLocked() {
+	activeID := m.cfg.ActiveKeyID
+	found := false
+	for i := range m.overlay {
+		m.overlay[i].Active = m.overlay[i].ID == activeID
+		found = found || m.overlay[i].Active
+	}
+	if found || !IsTeamKeyID(activeID) {
+		return
+	}
+
+	logging.Warnf("激活的团队 Key %s 已不存在，改为激活首个本地 Key", activeID)
+	m.cfg.ActiveKeyID = ""
+	if len(m.cfg.Keys) > 0 {
+		_ = m.setActiveKeyLocked(m.cfg.Keys[0].ID)
+	}
+}
+
 func (m *Manager) generateID() string {
 	if m.cfg.NextID <= 0 {
 		ensureNextID(m.cfg)
@@ -75,3 +75,46 @@ func TestManagerUpdate(t *testing.T) {
 		t.Fatalf("标签更新失败: %#v", got.Tags)
 	}
 }
+
+// TestValidate 验证配置校验能识别错误与警告
+func TestValidate(t *testing.T) {
+	cfg := &Config{
+		ActiveKeyID: "9",
+		Keys: []APIKey{
+			{ID: "1", Name: "main", APIKey: "sk-1", BaseURL: "https://api.openai.com/v1", Active: true},
+			{ID: "1", Name: "MAIN", APIKey: "", BaseURL: "ftp://example.com", Type: "azure", Active: true},
+		},
+	}
+
+	issues := Validate(cfg)
+	if !HasErrors(issues) {
+		t.Fatalf("期望存在错误: %v", issues)
+	}
+	if count := ErrorCount(issues); count != 4 {
+		t.Fatalf("期望 4 个错误(不含警告)，实际 %d: %v", count, issues)
+	}
+
+	want := map[string]Severity{
+		"$.keys[1].name":     SeverityError,
+		"$.keys[1].id":       SeverityError,
+		"$.keys[1].api_key":  SeverityError,
+		"$.keys[1].base_url": SeverityError,
+		"$.keys[1].type":     SeverityWarning,
+		"$.keys":             SeverityWarning,
+		"$.active_key_id":    SeverityWarning,
+	}
+	got := make(map[string]Severity, len(issues))
+	for _, issue := range issues {
+		got[issue.Path] = issue.Severity
+	}
+	for path, severity := range want {
+		if got[path] != severity {
+			t.Fatalf("路径 %s 期望 %s，实际 %q (全部: %v)", path, severity, got[path], issues)
+		}
+	}
+
+	valid := &Config{ActiveKeyID: "1", Keys: []APIKey{{ID: "1", Name: "main", APIKey: "sk-1", Active: true}}}
+	if issues := Validate(valid); len(issues) != 0 {
+		t.Fatalf("合法配置不应有问题: %v", issues)
+	}
+}
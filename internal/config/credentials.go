@@ -0,0 +1,251 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// 远程存储凭据(B2 Key / S3 Access Key)的保存位置
+const (
+	// CredentialStoreConfig 保存在 config.json 的 key_id/application_key 字段，默认方式
+	CredentialStoreConfig = "config"
+	// CredentialStoreEnv 不落盘，运行时从 CKM_<REMOTE>_KEY_ID/CKM_<REMOTE>_APP_KEY 或
+	// CKM_B2_KEY_ID/CKM_B2_APP_KEY 环境变量读取
+	CredentialStoreEnv = "env"
+	// CredentialStoreFile 保存在配置目录下权限为 0600 的 credentials.json
+	CredentialStoreFile = "file"
+	// CredentialStoreExec 运行 CredentialHelper 命令获取，适合对接 pass、1Password CLI 等工具
+	CredentialStoreExec = "exec"
+)
+
+// 远程存储凭据的通用环境变量，仅用于默认远程 origin 及 credential_store 为 env 的远程；
+// 各远程专用的环境变量见 RemoteEnvNames
+const (
+	EnvRemoteKeyID  = "CKM_B2_KEY_ID"
+	EnvRemoteAppKey = "CKM_B2_APP_KEY"
+)
+
+// credentialExecPrefix 为 --store exec:<命令> 的前缀
+const credentialExecPrefix = "exec:"
+
+// RemoteCredentials 为访问 B2/S3 存储的凭据
+type RemoteCredentials struct {
+	KeyID          string `json:"key_id"`
+	ApplicationKey string `json:"application_key"`
+}
+
+// Complete 判断凭据是否完整
+func (c RemoteCredentials) Complete() bool {
+	return strings.TrimSpace(c.KeyID) != "" && strings.TrimSpace(c.ApplicationKey) != ""
+}
+
+// fill 用 other 补齐缺失的字段
+func (c *RemoteCredentials) fill(other RemoteCredentials) {
+	if strings.TrimSpace(c.KeyID) == "" {
+		c.KeyID = strings.TrimSpace(other.KeyID)
+	}
+	if strings.TrimSpace(c.ApplicationKey) == "" {
+		c.ApplicationKey = strings.TrimSpace(other.ApplicationKey)
+	}
+}
+
+// SetAccessCredentials 记录运行时解析出的凭据，供存储后端使用；该值不会写入配置文件
+func (s *RemoteSettings) SetAccessCredentials(creds RemoteCredentials) {
+	s.resolved = &creds
+}
+
+// AccessCredentials 返回访问存储使用的凭据，未解析时使用配置文件中的字段
+func (s *RemoteSettings) AccessCredentials() RemoteCredentials {
+	if s.resolved != nil {
+		return *s.resolved
+	}
+	return RemoteCredentials{KeyID: s.KeyID, ApplicationKey: s.ApplicationKey}
+}
+
+// ParseCredentialStore 解析 --store 参数，返回存储位置与 exec 方式的辅助命令
+func ParseCredentialStore(value string) (string, string, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, credentialExecPrefix) {
+		helper := strings.TrimSpace(strings.TrimPrefix(value, credentialExecPrefix))
+		if helper == "" {
+			return "", "", errors.New("exec: 后需指定获取凭据的命令")
+		}
+		return CredentialStoreExec, helper, nil
+	}
+	switch strings.ToLower(value) {
+	case CredentialStoreConfig, CredentialStoreEnv, CredentialStoreFile:
+		return strings.ToLower(value), "", nil
+	default:
+		return "", "", fmt.Errorf("不支持的凭据存储方式: %s，可选 config/env/file/exec:<命令>", value)
+	}
+}
+
+// CredentialsPath 返回指定远程的凭据文件路径，与配置文件位于同一目录；
+// 默认远程 origin 使用 credentials.json，其余远程使用 credentials.<名称>.json
+func CredentialsPath(cfgPath string, remoteName string) string {
+	name := "credentials.json"
+	if remoteName != "" && remoteName != DefaultRemoteName {
+		name = "credentials." + remoteName + ".json"
+	}
+	return filepath.Join(filepath.Dir(cfgPath), name)
+}
+
+// LoadCredentialsFile 读取凭据文件，文件不存在时返回空凭据
+func LoadCredentialsFile(path string) (RemoteCredentials, error) {
+	var creds RemoteCredentials
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return creds, nil
+	}
+	if err != nil {
+		return creds, err
+	}
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return creds, fmt.Errorf("解析凭据文件 %s 失败: %w", path, err)
+	}
+	return creds, nil
+}
+
+// SaveCredentialsFile 以 0600 权限写入凭据文件
+func SaveCredentialsFile(path string, creds RemoteCredentials) error {
+	data, err := json.MarshalIndent(creds, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ensureConfigDir(path); err != nil {
+		return err
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// RemoteEnvNames 返回远程专用的凭据环境变量名 CKM_<REMOTE>_KEY_ID 与 CKM_<REMOTE>_APP_KEY，
+// 远程名称转换为大写，字母数字以外的字符替换为下划线
+func RemoteEnvNames(remoteName string) (string, string) {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' {
+			return r - 'a' + 'A'
+		}
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, remoteName)
+	return "CKM_" + name + "_KEY_ID", "CKM_" + name + "_APP_KEY"
+}
+
+// envCredentials 读取一组凭据环境变量
+func envCredentials(keyIDVar, appKeyVar string) RemoteCredentials {
+	return RemoteCredentials{
+		KeyID:          strings.TrimSpace(os.Getenv(keyIDVar)),
+		ApplicationKey: strings.TrimSpace(os.Getenv(appKeyVar)),
+	}
+}
+
+// ResolveRemoteCredentials 按优先级解析远程存储凭据：环境变量 > CredentialStore 指定的
+// 凭据文件或辅助命令 > 配置文件字段。环境变量中远程专用的 CKM_<REMOTE>_* 优先，通用的
+// CKM_B2_* 仅用于默认远程 origin 与 credential_store 为 env 的远程，避免多个远程误用同一组凭据。
+// env/file/exec 方式解析后仍不完整时返回带提示的错误，此时返回值中仍包含已解析出的部分凭据。
+func ResolveRemoteCredentials(settings *RemoteSettings, credentialsPath string) (RemoteCredentials, error) {
+	if settings == nil {
+		return envCredentials(EnvRemoteKeyID, EnvRemoteAppKey), nil
+	}
+	keyIDVar, appKeyVar := RemoteEnvNames(settings.Name())
+	creds := envCredentials(keyIDVar, appKeyVar)
+	if settings.CredentialStore == CredentialStoreEnv || settings.Name() == DefaultRemoteName {
+		creds.fill(envCredentials(EnvRemoteKeyID, EnvRemoteAppKey))
+	}
+	if creds.Complete() {
+		return creds, nil
+	}
+
+	switch settings.CredentialStore {
+	case CredentialStoreFile:
+		fromFile, err := LoadCredentialsFile(credentialsPath)
+		if err != nil {
+			return creds, err
+		}
+		creds.fill(fromFile)
+	case CredentialStoreExec:
+		fromHelper, err := runCredentialHelper(settings.CredentialHelper)
+		if err != nil {
+			return creds, err
+		}
+		creds.fill(fromHelper)
+	}
+	creds.fill(RemoteCredentials{KeyID: settings.KeyID, ApplicationKey: settings.ApplicationKey})
+	if creds.Complete() {
+		return creds, nil
+	}
+
+	switch settings.CredentialStore {
+	case CredentialStoreEnv:
+		return creds, fmt.Errorf("未设置环境变量 %s 与 %s (或 %s 与 %s)", keyIDVar, appKeyVar, EnvRemoteKeyID, EnvRemoteAppKey)
+	case CredentialStoreFile:
+		return creds, fmt.Errorf("凭据文件 %s 缺少 key_id 或 application_key", credentialsPath)
+	case CredentialStoreExec:
+		return creds, fmt.Errorf("凭据命令未输出 key_id 与 application_key: %s", settings.CredentialHelper)
+	}
+	return creds, nil
+}
+
+// CredentialHelperTimeout 为凭据命令的最长执行时间，留出交互式解锁所需的时间
+const CredentialHelperTimeout = 60 * time.Second
+
+// runCredentialHelper 通过系统 shell 执行凭据命令，输出为 key_id=... 与 application_key=...
+// 两行(与 git credential helper 类似)。命令的标准错误直接透传，便于交互式解锁；
+// 超过 CredentialHelperTimeout 未完成时终止命令。
+func runCredentialHelper(helper string) (RemoteCredentials, error) {
+	var creds RemoteCredentials
+	if strings.TrimSpace(helper) == "" {
+		return creds, errors.New("未配置凭据命令")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), CredentialHelperTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", helper)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", helper)
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+	// 命令启动的后台进程可能继承输出管道，超时后不再等待其关闭
+	cmd.WaitDelay = time.Second
+	out, err := cmd.Output()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return creds, fmt.Errorf("凭据命令超过 %s 未完成: %s", CredentialHelperTimeout, helper)
+	}
+	if err != nil {
+		return creds, fmt.Errorf("执行凭据命令失败: %w", err)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		name, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(name) {
+		case "key_id":
+			creds.KeyID = strings.TrimSpace(value)
+		case "application_key":
+			creds.ApplicationKey = strings.TrimSpace(value)
+		}
+	}
+	return creds, scanner.Err()
+}
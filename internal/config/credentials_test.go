@@ -0,0 +1,103 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// TestResolveRemoteCredentials 验证凭据解析优先级：环境变量 > 凭据文件/命令 > 配置字段
+func TestResolveRemoteCredentials(t *testing.T) {
+	t.Setenv(EnvRemoteKeyID, "")
+	t.Setenv(EnvRemoteAppKey, "")
+	path := filepath.Join(t.TempDir(), "credentials.json")
+
+	settings := &RemoteSettings{KeyID: "cfg-id", ApplicationKey: "cfg-key"}
+	creds, err := ResolveRemoteCredentials(settings, path)
+	if err != nil || creds.KeyID != "cfg-id" || creds.ApplicationKey != "cfg-key" {
+		t.Fatalf("应使用配置字段，got=%+v err=%v", creds, err)
+	}
+
+	if err := SaveCredentialsFile(path, RemoteCredentials{KeyID: "file-id", ApplicationKey: "file-key"}); err != nil {
+		t.Fatalf("写入凭据文件失败: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("凭据文件权限应为 0600: %v", err)
+	}
+	settings = &RemoteSettings{CredentialStore: CredentialStoreFile}
+	creds, err = ResolveRemoteCredentials(settings, path)
+	if err != nil || creds.KeyID != "file-id" {
+		t.Fatalf("应使用凭据文件，got=%+v err=%v", creds, err)
+	}
+
+	t.Setenv(EnvRemoteKeyID, "env-id")
+	creds, err = ResolveRemoteCredentials(settings, path)
+	if err != nil || creds.KeyID != "env-id" || creds.ApplicationKey != "file-key" {
+		t.Fatalf("环境变量应逐项优先，got=%+v err=%v", creds, err)
+	}
+
+	settings = &RemoteSettings{CredentialStore: CredentialStoreEnv}
+	if _, err := ResolveRemoteCredentials(settings, path); err == nil {
+		t.Fatalf("环境变量不完整时应报错")
+	}
+
+	if runtime.GOOS != "windows" {
+		t.Setenv(EnvRemoteKeyID, "")
+		settings = &RemoteSettings{CredentialStore: CredentialStoreExec, CredentialHelper: "printf 'key_id=exec-id\\napplication_key=exec-key\\n'"}
+		creds, err = ResolveRemoteCredentials(settings, path)
+		if err != nil || creds.KeyID != "exec-id" || creds.ApplicationKey != "exec-key" {
+			t.Fatalf("应使用凭据命令输出，got=%+v err=%v", creds, err)
+		}
+	}
+}
+
+// TestResolveRemoteCredentialsPerRemote 验证通用环境变量不会被其他远程误用
+func TestResolveRemoteCredentialsPerRemote(t *testing.T) {
+	t.Setenv(EnvRemoteKeyID, "personal-id")
+	t.Setenv(EnvRemoteAppKey, "personal-key")
+	dir := t.TempDir()
+
+	origin := &RemoteSettings{name: DefaultRemoteName, CredentialStore: CredentialStoreEnv}
+	team := &RemoteSettings{name: "team-b2", KeyID: "team-id", ApplicationKey: "team-key"}
+
+	creds, err := ResolveRemoteCredentials(origin, CredentialsPath(filepath.Join(dir, "config.json"), origin.Name()))
+	if err != nil || creds.KeyID != "personal-id" {
+		t.Fatalf("默认远程应使用通用环境变量，got=%+v err=%v", creds, err)
+	}
+	creds, err = ResolveRemoteCredentials(team, CredentialsPath(filepath.Join(dir, "config.json"), team.Name()))
+	if err != nil || creds.KeyID != "team-id" || creds.ApplicationKey != "team-key" {
+		t.Fatalf("其他远程不应使用通用环境变量，got=%+v err=%v", creds, err)
+	}
+
+	keyIDVar, appKeyVar := RemoteEnvNames(team.Name())
+	if keyIDVar != "CKM_TEAM_B2_KEY_ID" || appKeyVar != "CKM_TEAM_B2_APP_KEY" {
+		t.Fatalf("远程专用环境变量名不符: %s %s", keyIDVar, appKeyVar)
+	}
+	t.Setenv(keyIDVar, "team-env-id")
+	t.Setenv(appKeyVar, "team-env-key")
+	creds, err = ResolveRemoteCredentials(team, "")
+	if err != nil || creds.KeyID != "team-env-id" || creds.ApplicationKey != "team-env-key" {
+		t.Fatalf("远程专用环境变量应优先，got=%+v err=%v", creds, err)
+	}
+	creds, err = ResolveRemoteCredentials(origin, "")
+	if err != nil || creds.KeyID != "personal-id" {
+		t.Fatalf("其他远程的专用环境变量不应影响默认远程，got=%+v err=%v", creds, err)
+	}
+}
+
+// TestParseCredentialStore 验证 --store 参数解析
+func TestParseCredentialStore(t *testing.T) {
+	store, helper, err := ParseCredentialStore("exec:pass show ckm/b2")
+	if err != nil || store != CredentialStoreExec || helper != "pass show ckm/b2" {
+		t.Fatalf("exec 解析错误: %s %s %v", store, helper, err)
+	}
+	if store, _, err := ParseCredentialStore("FILE"); err != nil || store != CredentialStoreFile {
+		t.Fatalf("file 解析错误: %s %v", store, err)
+	}
+	for _, bad := range []string{"exec:", "vault"} {
+		if _, _, err := ParseCredentialStore(bad); err == nil {
+			t.Fatalf("%s 应解析失败", bad)
+		}
+	}
+}
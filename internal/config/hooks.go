@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 支持的 hook 事件
+const (
+	HookPreSwitch  = "pre_switch"
+	HookPostSwitch = "post_switch"
+	HookPostAdd    = "post_add"
+	HookPostRemove = "post_remove"
+	HookPostPull   = "post_pull"
+)
+
+// HookEvents 为全部 hook 事件，按执行场景排序
+var HookEvents = []string{HookPreSwitch, HookPostSwitch, HookPostAdd, HookPostRemove, HookPostPull}
+
+// DefaultHookTimeout 为未配置 timeout 时单个 hook 的最长执行时间
+const DefaultHookTimeout = 10 * time.Second
+
+// HookSettings 配置在 Key 变化时执行的用户脚本，每个事件可配置多个可执行文件，按顺序执行
+type HookSettings struct {
+	PreSwitch  []string `json:"pre_switch,omitempty"`
+	PostSwitch []string `json:"post_switch,omitempty"`
+	PostAdd    []string `json:"post_add,omitempty"`
+	PostRemove []string `json:"post_remove,omitempty"`
+	PostPull   []string `json:"post_pull,omitempty"`
+	// Timeout 为单个 hook 的超时秒数，0 表示使用默认值
+	Timeout int `json:"timeout,omitempty"`
+}
+
+// Commands 返回事件对应的可执行文件列表，未配置时返回 nil
+func (h *HookSettings) Commands(event string) []string {
+	if h == nil {
+		return nil
+	}
+	if list := h.list(event); list != nil {
+		return *list
+	}
+	return nil
+}
+
+// SetCommands 替换事件对应的可执行文件列表
+func (h *HookSettings) SetCommands(event string, commands []string) error {
+	list := h.list(event)
+	if list == nil {
+		return fmt.Errorf("未知的 hook 事件 %s，支持 %s", event, strings.Join(HookEvents, "/"))
+	}
+	*list = commands
+	return nil
+}
+
+// TimeoutDuration 返回单个 hook 的超时时间
+func (h *HookSettings) TimeoutDuration() time.Duration {
+	if h == nil || h.Timeout <= 0 {
+		return DefaultHookTimeout
+	}
+	return time.Duration(h.Timeout) * time.Second
+}
+
+// list 返回事件对应字段的指针，未知事件返回 nil
+func (h *HookSettings) list(event string) *[]string {
+	switch event {
+	case HookPreSwitch:
+		return &h.PreSwitch
+	case HookPostSwitch:
+		return &h.PostSwitch
+	case HookPostAdd:
+		return &h.PostAdd
+	case HookPostRemove:
+		return &h.PostRemove
+	case HookPostPull:
+		return &h.PostPull
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// teamSourcePrefix 为团队配置叠加 Key 的来源与 ID 前缀
+const teamSourcePrefix = "team:"
+
+// ErrReadOnlyKey 表示目标 Key 来自订阅的团队配置，不能在本地修改或删除
+var ErrReadOnlyKey = errors.New("该 Key 来自团队配置，只读，无法在本地修改或删除")
+
+// TeamOverlay 为订阅的团队配置档案在本地的缓存，由 ckm team refresh 更新
+type TeamOverlay struct {
+	Profile     string    `json:"profile"`
+	RefreshedAt time.Time `json:"refreshed_at"`
+	Keys        []APIKey  `json:"keys"`
+}
+
+// TeamSource 返回团队配置档案对应的 Key 来源标记
+func TeamSource(profile string) string {
+	return teamSourcePrefix + profile
+}
+
+// TeamKeyID 返回团队配置中 Key 在本地使用的 ID，形如 team:<profile>:<id>，避免与本地 ID 冲突
+func TeamKeyID(profile, id string) string {
+	return teamSourcePrefix + profile + ":" + id
+}
+
+// IsTeamKeyID 判断 ID 是否属于团队配置叠加的 Key
+func IsTeamKeyID(id string) bool {
+	return strings.HasPrefix(id, teamSourcePrefix)
+}
+
+// TeamOverlayDir 返回指定远程的团队配置缓存目录。不同远程可能订阅同名档案，
+// 默认远程 origin 沿用 team/，其余远程位于 team/<远程名称>/ 下，与本地快照目录一致
+func TeamOverlayDir(cfgPath, remoteName string) string {
+	dir := filepath.Join(filepath.Dir(cfgPath), "team")
+	if remoteName == DefaultRemoteName {
+		return dir
+	}
+	return filepath.Join(dir, remoteName)
+}
+
+// TeamOverlayPath 返回远程 remoteName 上团队配置档案 profile 的缓存文件路径
+func TeamOverlayPath(cfgPath, remoteName, profile string) string {
+	return filepath.Join(TeamOverlayDir(cfgPath, remoteName), profile+".json")
+}
+
+// LoadTeamOverlay 读取团队配置缓存
+func LoadTeamOverlay(path string) (*TeamOverlay, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var overlay TeamOverlay
+	if err := json.Unmarshal(data, &overlay); err != nil {
+		return nil, fmt.Errorf("解析团队配置缓存失败: %w", err)
+	}
+	return &overlay, nil
+}
+
+// Save 写入团队配置缓存，缓存包含密钥，权限与配置文件一致
+func (o *TeamOverlay) Save(path string) error {
+	data, err := json.MarshalIndent(o, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ensureConfigDir(path); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// overlayKeys 将团队配置中的 Key 转换为本地只读视图：重写 ID、标记来源并清除激活状态
+func overlayKeys(overlay *TeamOverlay) []APIKey {
+	keys := make([]APIKey, 0, len(overlay.Keys))
+	for _, k := range overlay.Keys {
+		k.ID = TeamKeyID(overlay.Profile, k.ID)
+		k.Source = TeamSource(overlay.Profile)
+		k.Active = false
+		k.LocalOnly = false
+		setIntegrationDefaults(&k)
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestManagerTeamOverlay 验证团队 Key 与本地 Key 合并展示、只读且不写入配置文件
+func TestManagerTeamOverlay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	manager, err := NewDefaultManager(path)
+	if err != nil {
+		t.Fatalf("创建管理器失败: %v", err)
+	}
+	if _, err := manager.Load(); err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	local, err := manager.AddKey(APIKey{Name: "个人", APIKey: "sk-local", Type: TypeOpenAI})
+	if err != nil {
+		t.Fatalf("添加 Key 失败: %v", err)
+	}
+	cfg, _ := manager.Config()
+	cfg.Remote.TeamProfiles = []string{"shared"}
+	if err := manager.ReplaceConfig(cfg); err != nil {
+		t.Fatalf("替换配置失败: %v", err)
+	}
+	if err := manager.Save(); err != nil {
+		t.Fatalf("保存配置失败: %v", err)
+	}
+
+	overlay := &TeamOverlay{Profile: "shared", Keys: []APIKey{{ID: "1", Name: "团队", APIKey: "sk-team", Type: TypeOpenAI, Active: true}}}
+	if err := overlay.Save(TeamOverlayPath(path, DefaultRemoteName, "shared")); err != nil {
+		t.Fatalf("写入团队缓存失败: %v", err)
+	}
+
+	manager, _ = NewDefaultManager(path)
+	if _, err := manager.Load(); err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	keys, err := manager.ListKeys("default")
+	if err != nil || len(keys) != 2 {
+		t.Fatalf("期待 2 个 Key，got=%d err=%v", len(keys), err)
+	}
+
+	teamID := TeamKeyID("shared", "1")
+	team, err := manager.GetKeyByName("团队")
+	if err != nil || team.ID != teamID || team.Source != "team:shared" {
+		t.Fatalf("团队 Key 不正确: %+v err=%v", team, err)
+	}
+	if team.Active {
+		t.Fatalf("团队快照中的激活状态不应生效")
+	}
+	if err := manager.UpdateKey(APIKey{ID: teamID, Name: "改名"}); !errors.Is(err, ErrReadOnlyKey) {
+		t.Fatalf("期待只读错误，got=%v", err)
+	}
+	if err := manager.RemoveKey(teamID); !errors.Is(err, ErrReadOnlyKey) {
+		t.Fatalf("期待只读错误，got=%v", err)
+	}
+
+	if err := manager.SetActiveKey(teamID); err != nil {
+		t.Fatalf("切换到团队 Key 失败: %v", err)
+	}
+	if err := manager.Save(); err != nil {
+		t.Fatalf("保存配置失败: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "sk-team") {
+		t.Fatalf("团队 Key 不应写入配置文件")
+	}
+
+	manager, _ = NewDefaultManager(path)
+	if _, err := manager.Load(); err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	active, err := manager.ActiveKey()
+	if err != nil || active.ID != teamID {
+		t.Fatalf("重新加载后应保持团队 Key 激活，got=%s err=%v", active.ID, err)
+	}
+
+	if err := manager.RemoveTeamOverlay("shared"); err != nil {
+		t.Fatalf("移除团队配置失败: %v", err)
+	}
+	active, err = manager.ActiveKey()
+	if err != nil || active.ID != local.ID {
+		t.Fatalf("团队 Key 移除后应回退到本地 Key，got=%s err=%v", active.ID, err)
+	}
+}
+
+// TestTeamOverlayPathPerRemote 验证不同远程订阅同名档案时缓存互不覆盖，origin 沿用原有路径
+func TestTeamOverlayPathPerRemote(t *testing.T) {
+	path := filepath.Join("/home/alice/.codex-switch", "config.json")
+	origin := TeamOverlayPath(path, DefaultRemoteName, "shared")
+	if origin != filepath.Join("/home/alice/.codex-switch", "team", "shared.json") {
+		t.Fatalf("origin 应沿用原有缓存路径: %s", origin)
+	}
+	work := TeamOverlayPath(path, "work", "shared")
+	if work == origin || filepath.Dir(work) != TeamOverlayDir(path, "work") {
+		t.Fatalf("其他远程的缓存应位于各自目录: %s", work)
+	}
+}
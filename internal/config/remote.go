@@ -9,8 +9,12 @@ import (
 // RemoteSettings 描述远程同步所需的完整配置
 //
 // 开发者可以通过 remote push/pull 将本地 Key 列表推送到
-// Backblaze B2 等对象存储；该结构保存所需的鉴权信息、
-// 对象定位以及同步辅助元数据。
+// Backblaze B2、S3 兼容存储等后端；该结构保存所需的鉴权信息、
+// 对象定位以及同步辅助元数据。S3 后端复用 KeyID/ApplicationKey
+// 作为 Access Key ID 与 Secret Access Key；WebDAV 后端使用 URL 指向
+// 快照目录，并通过 Username/Password 或 BearerToken 鉴权；目录后端
+// 将快照写入 Directory 指定的本地路径；Git 后端将快照提交到 Repo 的
+// Branch 分支。
 type RemoteSettings struct {
 	Provider       string    `json:"provider"`
 	BucketName     string    `json:"bucket_name"`
@@ -20,29 +24,82 @@ type RemoteSettings struct {
 	ApplicationKey string    `json:"application_key"`
 	APIURL         string    `json:"api_url,omitempty"`
 	DownloadURL    string    `json:"download_url,omitempty"`
+	Endpoint       string    `json:"endpoint,omitempty"`
+	Region         string    `json:"region,omitempty"`
+	PathStyle      bool      `json:"path_style,omitempty"`
+	URL            string    `json:"url,omitempty"`
+	Username       string    `json:"username,omitempty"`
+	Password       string    `json:"password,omitempty"`
+	BearerToken    string    `json:"bearer_token,omitempty"`
+	Directory      string    `json:"directory,omitempty"`
+	Repo           string    `json:"repo,omitempty"`
+	Branch         string    `json:"branch,omitempty"`
 	SyncToken      string    `json:"sync_token"`
 	LastSync       time.Time `json:"last_sync,omitempty"`
+	LastSyncHash   string    `json:"last_sync_hash,omitempty"`
 	Enabled        bool      `json:"enabled"`
+	// DisableEncryption 为 true 时上传明文快照，仅用于兼容旧版本客户端
+	DisableEncryption bool `json:"disable_encryption,omitempty"`
+	// AllowPlaintext 为 true 时允许 Git 后端提交未加密的快照
+	AllowPlaintext bool `json:"allow_plaintext,omitempty"`
+	// SigningKey 为 Base64 编码的 ed25519 私钥种子，为空时使用 SyncToken 派生的 HMAC 签名
+	SigningKey string `json:"signing_key,omitempty"`
+	// TrustedSigners 为接受的 ed25519 签名公钥(Base64)
+	TrustedSigners []string `json:"trusted_signers,omitempty"`
+	// HistoryLimit 为每个 profile 保留的历史版本数，0 使用默认值，负数表示不清理
+	HistoryLimit int `json:"history_limit,omitempty"`
+	// IncludeTags 非空时仅同步带有其中任一标签的 Key
+	IncludeTags []string `json:"include_tags,omitempty"`
+	// ExcludeTags 中任一标签命中的 Key 不参与同步，优先于 IncludeTags
+	ExcludeTags []string `json:"exclude_tags,omitempty"`
+	// Identity 为 Base64 编码的 X25519 身份私钥，用于解密按接收者加密的快照
+	Identity string `json:"identity,omitempty"`
+	// Recipients 按 profile 记录接收者公钥(x25519:<Base64>)，非空时快照按接收者加密
+	Recipients map[string][]string `json:"recipients,omitempty"`
+	// AutoPush 为 true 时 add/update/remove/import 成功后自动推送最新快照
+	AutoPush bool `json:"auto_push,omitempty"`
+	// TeamProfiles 为订阅的团队配置档案，其 Key 以只读方式叠加在本地 Key 之上
+	TeamProfiles []string `json:"team_profiles,omitempty"`
+	// CredentialStore 为 KeyID/ApplicationKey 的保存位置，见 CredentialStoreConfig 等常量，空值等同 config
+	CredentialStore string `json:"credential_store,omitempty"`
+	// CredentialHelper 为 exec 方式获取凭据时执行的命令
+	CredentialHelper string `json:"credential_helper,omitempty"`
+
+	// resolved 为运行时解析出的凭据，见 SetAccessCredentials
+	resolved *RemoteCredentials
+	// name 为该远程在 Config.Remotes 中的名称，加载配置时设置
+	name string
+}
+
+// Name 返回远程名称，未命名时为默认远程名称
+func (s *RemoteSettings) Name() string {
+	if s.name == "" {
+		return DefaultRemoteName
+	}
+	return s.name
 }
 
 // normalizeRemoteSettings 确保配置结构中包含有效的远程设置占位。
 //
-// 若配置文件中尚未初始化远程字段，则创建默认实例并自动生成
+// 若配置文件中尚未初始化远程字段，则创建默认远程并为每个远程自动生成
 // 用于数据加密/鉴权的 SyncToken，避免后续逻辑访问空指针。
 // 返回值表示是否修改了配置内容，便于调用方决定是否持久化。
 func normalizeRemoteSettings(cfg *Config) bool {
-	changed := false
-	if cfg.Remote == nil {
-		cfg.Remote = &RemoteSettings{}
-		changed = true
-	}
-	if cfg.Remote.SyncToken == "" {
-		cfg.Remote.SyncToken = generateSyncToken()
-		changed = true
+	changed := normalizeRemotes(cfg)
+	for _, settings := range cfg.Remotes {
+		if settings.SyncToken == "" {
+			settings.SyncToken = generateSyncToken()
+			changed = true
+		}
 	}
 	return changed
 }
 
+// GenerateSyncToken 生成新的随机 SyncToken，供轮换令牌时使用。
+func GenerateSyncToken() string {
+	return generateSyncToken()
+}
+
 // generateSyncToken 生成 32 字节随机数并使用 Base64 编码。
 func generateSyncToken() string {
 	buf := make([]byte, 32)
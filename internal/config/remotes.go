@@ -0,0 +1,178 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// DefaultRemoteName 为默认远程名称，旧版本单一 remote 字段迁移后使用该名称
+const DefaultRemoteName = "origin"
+
+// RemoteName 返回 Config.Remote 当前对应的远程名称，未通过 SelectRemote 选择时为默认远程
+func (c *Config) RemoteName() string {
+	if c.remoteName != "" {
+		return c.remoteName
+	}
+	if c.DefaultRemote != "" {
+		return c.DefaultRemote
+	}
+	return DefaultRemoteName
+}
+
+// SelectRemote 将 Config.Remote 指向指定远程，后续对 Remote 的修改会写回该远程
+func (c *Config) SelectRemote(name string) error {
+	settings, ok := c.Remotes[name]
+	if !ok || settings == nil {
+		return fmt.Errorf("未找到远程 %s，可执行 ckm remotes list 查看", name)
+	}
+	c.remoteName = name
+	c.Remote = settings
+	return nil
+}
+
+// RemoteNames 返回全部远程名称，默认远程排在首位，其余按名称排序
+func (c *Config) RemoteNames() []string {
+	names := make([]string, 0, len(c.Remotes))
+	for name := range c.Remotes {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if (names[i] == c.DefaultRemote) != (names[j] == c.DefaultRemote) {
+			return names[i] == c.DefaultRemote
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// AddRemote 新增远程，未配置的默认远程会被新远程取代为默认
+func (c *Config) AddRemote(name string, settings *RemoteSettings) error {
+	if err := ValidateRemoteName(name); err != nil {
+		return err
+	}
+	if _, ok := c.Remotes[name]; ok {
+		return fmt.Errorf("远程 %s 已存在", name)
+	}
+	if settings == nil {
+		settings = &RemoteSettings{}
+	}
+	if settings.SyncToken == "" {
+		settings.SyncToken = generateSyncToken()
+	}
+	if c.Remotes == nil {
+		c.Remotes = map[string]*RemoteSettings{}
+	}
+	settings.name = name
+	c.Remotes[name] = settings
+	if current, ok := c.Remotes[c.DefaultRemote]; !ok || current == nil || !current.Enabled {
+		c.setDefaultRemote(name)
+	}
+	return nil
+}
+
+// RemoveRemote 删除远程。默认远程仅在没有其他远程时可删除，删除后恢复为空的 origin
+func (c *Config) RemoveRemote(name string) error {
+	if _, ok := c.Remotes[name]; !ok {
+		return fmt.Errorf("未找到远程 %s", name)
+	}
+	if name == c.DefaultRemote && len(c.Remotes) > 1 {
+		return fmt.Errorf("%s 为默认远程，请先执行 ckm remotes default <NAME> 切换默认远程", name)
+	}
+	delete(c.Remotes, name)
+	if name == c.RemoteName() {
+		c.remoteName = ""
+		c.Remote = nil
+	}
+	if len(c.Remotes) == 0 {
+		c.DefaultRemote = DefaultRemoteName
+	}
+	return nil
+}
+
+// RenameRemote 重命名远程，默认远程随之更新
+func (c *Config) RenameRemote(oldName, newName string) error {
+	settings, ok := c.Remotes[oldName]
+	if !ok {
+		return fmt.Errorf("未找到远程 %s", oldName)
+	}
+	if err := ValidateRemoteName(newName); err != nil {
+		return err
+	}
+	if _, exists := c.Remotes[newName]; exists {
+		return fmt.Errorf("远程 %s 已存在", newName)
+	}
+	delete(c.Remotes, oldName)
+	settings.name = newName
+	c.Remotes[newName] = settings
+	if c.DefaultRemote == oldName {
+		c.DefaultRemote = newName
+	}
+	if c.remoteName == oldName {
+		c.remoteName = newName
+	}
+	return nil
+}
+
+// SetDefaultRemote 设置默认远程
+func (c *Config) SetDefaultRemote(name string) error {
+	if _, ok := c.Remotes[name]; !ok {
+		return fmt.Errorf("未找到远程 %s", name)
+	}
+	c.setDefaultRemote(name)
+	return nil
+}
+
+// setDefaultRemote 修改默认远程，未显式选择远程时 Remote 随之切换
+func (c *Config) setDefaultRemote(name string) {
+	c.DefaultRemote = name
+	if c.remoteName == "" {
+		c.Remote = c.Remotes[name]
+	}
+}
+
+// ValidateRemoteName 校验远程名称，仅允许字母、数字、- 与 _
+func ValidateRemoteName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("远程名称不能为空")
+	}
+	for _, r := range name {
+		if !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_') {
+			return fmt.Errorf("远程名称 %s 无效，仅允许字母、数字、- 与 _", name)
+		}
+	}
+	return nil
+}
+
+// normalizeRemotes 将旧版本的单一 remote 字段迁移为名为 origin 的远程，
+// 并将 Remote 写回当前选择的远程(调用方可能替换了 Remote 指针)，最后让 Remote
+// 指向该远程。返回值表示是否修改了配置内容。
+func normalizeRemotes(cfg *Config) bool {
+	changed := false
+	if cfg.Remotes == nil {
+		cfg.Remotes = map[string]*RemoteSettings{}
+	}
+	if cfg.DefaultRemote == "" {
+		cfg.DefaultRemote = DefaultRemoteName
+		changed = true
+	}
+	name := cfg.RemoteName()
+	if cfg.Remote != nil && cfg.Remotes[name] != cfg.Remote {
+		cfg.Remotes[name] = cfg.Remote
+		changed = true
+	}
+	if cfg.Remotes[cfg.DefaultRemote] == nil {
+		cfg.Remotes[cfg.DefaultRemote] = &RemoteSettings{}
+		changed = true
+	}
+	if cfg.Remotes[name] == nil {
+		cfg.remoteName = ""
+		name = cfg.DefaultRemote
+	}
+	for n, settings := range cfg.Remotes {
+		settings.name = n
+	}
+	cfg.Remote = cfg.Remotes[name]
+	return changed
+}
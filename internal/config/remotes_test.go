@@ -0,0 +1,109 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRemoteMigration 验证旧版本的单一 remote 字段迁移为 origin 远程
+func TestRemoteMigration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	legacy := `{"version":"1.0","keys":[],"remote":{"enabled":true,"provider":"dir","directory":"/tmp/share","sync_token":"tok-legacy"}}`
+	if err := os.WriteFile(path, []byte(legacy), 0o600); err != nil {
+		t.Fatalf("写入旧配置失败: %v", err)
+	}
+
+	manager, _ := NewDefaultManager(path)
+	if _, err := manager.Load(); err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	cfg, _ := manager.Config()
+	origin := cfg.Remotes[DefaultRemoteName]
+	if origin == nil || origin.Directory != "/tmp/share" || origin.SyncToken != "tok-legacy" {
+		t.Fatalf("旧远程未迁移为 origin: %#v", cfg.Remotes)
+	}
+	if cfg.Remote != origin || cfg.DefaultRemote != DefaultRemoteName || origin.Name() != DefaultRemoteName {
+		t.Fatalf("Remote 应指向默认远程 origin")
+	}
+
+	if err := manager.Save(); err != nil {
+		t.Fatalf("保存配置失败: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), `"remote":`) || !strings.Contains(string(data), `"remotes":`) {
+		t.Fatalf("保存后应只包含 remotes 字段: %s", data)
+	}
+}
+
+// TestConfigRemotes 验证命名远程的新增、选择、重命名与删除
+func TestConfigRemotes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	manager, _ := NewDefaultManager(path)
+	if _, err := manager.Load(); err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	cfg, _ := manager.Config()
+
+	// origin 尚未配置，新增的远程成为默认远程
+	if err := cfg.AddRemote("team", &RemoteSettings{Enabled: true, Provider: "dir"}); err != nil {
+		t.Fatalf("新增远程失败: %v", err)
+	}
+	if cfg.DefaultRemote != "team" || cfg.Remote != cfg.Remotes["team"] || cfg.Remotes["team"].SyncToken == "" {
+		t.Fatalf("新增远程应成为默认远程并生成 SyncToken: %#v", cfg)
+	}
+	if err := cfg.AddRemote("team", nil); err == nil {
+		t.Fatalf("重复的远程名称应报错")
+	}
+	if err := cfg.AddRemote("bad/name", nil); err == nil {
+		t.Fatalf("非法的远程名称应报错")
+	}
+	if err := cfg.AddRemote("personal", nil); err != nil {
+		t.Fatalf("新增远程失败: %v", err)
+	}
+	if cfg.DefaultRemote != "team" {
+		t.Fatalf("默认远程已启用时不应切换，got=%s", cfg.DefaultRemote)
+	}
+	if names := cfg.RemoteNames(); strings.Join(names, ",") != "team,origin,personal" {
+		t.Fatalf("远程排序不正确: %v", names)
+	}
+
+	if err := cfg.RenameRemote("team", "work"); err != nil {
+		t.Fatalf("重命名远程失败: %v", err)
+	}
+	if cfg.DefaultRemote != "work" || cfg.Remotes["work"].Name() != "work" {
+		t.Fatalf("重命名后默认远程应随之更新: %s", cfg.DefaultRemote)
+	}
+	if err := cfg.RemoveRemote("work"); err == nil {
+		t.Fatalf("存在其他远程时不应删除默认远程")
+	}
+	if err := cfg.RemoveRemote("origin"); err != nil {
+		t.Fatalf("删除远程失败: %v", err)
+	}
+
+	if err := manager.ReplaceConfig(cfg); err != nil {
+		t.Fatalf("替换配置失败: %v", err)
+	}
+	if err := manager.Save(); err != nil {
+		t.Fatalf("保存配置失败: %v", err)
+	}
+
+	manager, _ = NewDefaultManager(path)
+	if _, err := manager.Load(); err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	if err := manager.UseRemote("personal"); err != nil {
+		t.Fatalf("选择远程失败: %v", err)
+	}
+	if err := manager.UseRemote("missing"); err == nil {
+		t.Fatalf("选择不存在的远程应报错")
+	}
+	loaded, _ := manager.Config()
+	if len(loaded.Remotes) != 2 || loaded.DefaultRemote != "work" {
+		t.Fatalf("重新加载后的远程不正确: %v default=%s", loaded.RemoteNames(), loaded.DefaultRemote)
+	}
+	if loaded.RemoteName() != "personal" || loaded.Remote != loaded.Remotes["personal"] {
+		t.Fatalf("UseRemote 后 Remote 应指向 personal")
+	}
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"unicode"
+)
+
+// envPlaceholderPrefix 标识引用环境变量的密钥占位符，例如 env:OPENAI_API_KEY
+const envPlaceholderPrefix = "env:"
+
+// secretMask 为脱敏值中必然出现的片段，真实密钥不会包含该序列
+const secretMask = "****"
+
+// MaskSecret 对密钥做脱敏处理，结果始终包含 "****" 以便导入时识别为占位符。
+func MaskSecret(secret string) string {
+	secret = strings.TrimSpace(secret)
+	if len(secret) < 11 {
+		return secretMask
+	}
+	return secret[:4] + strings.Repeat("*", len(secret)-7) + secret[len(secret)-3:]
+}
+
+// EnvPlaceholder 返回引用指定环境变量的占位符。
+func EnvPlaceholder(name string) string {
+	return envPlaceholderPrefix + name
+}
+
+// EnvPlaceholderName 根据 Key 名称推导占位符使用的环境变量名。
+//
+// 若 Key 配置了 env_key 则直接沿用，否则生成 CKM_KEY_<NAME> 形式。
+func EnvPlaceholderName(key APIKey) string {
+	if envKey := strings.TrimSpace(key.EnvKey); envKey != "" {
+		return envKey
+	}
+	var b strings.Builder
+	for _, r := range strings.ToUpper(strings.TrimSpace(key.Name)) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	name := strings.Trim(b.String(), "_")
+	if name == "" {
+		name = "ID_" + key.ID
+	}
+	return "CKM_KEY_" + name
+}
+
+// IsSecretPlaceholder 判断值是否为脱敏结果或 env: 占位符，而非真实密钥。
+func IsSecretPlaceholder(value string) bool {
+	value = strings.TrimSpace(value)
+	return strings.HasPrefix(value, envPlaceholderPrefix) || strings.Contains(value, secretMask)
+}
+
+// ResolveEnvPlaceholder 解析 env: 占位符，环境变量未设置时返回 false。
+func ResolveEnvPlaceholder(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, envPlaceholderPrefix) {
+		return "", false
+	}
+	resolved, ok := os.LookupEnv(strings.TrimPrefix(value, envPlaceholderPrefix))
+	if !ok || strings.TrimSpace(resolved) == "" {
+		return "", false
+	}
+	return resolved, true
+}
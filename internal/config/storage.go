@@ -83,7 +83,7 @@ func (f *FileStorage) Save(cfg *Config) error {
 		return err
 	}
 
-	data, err := json.MarshalIndent(cfg, "", "  ")
+	data, err := json.MarshalIndent(persistable(cfg), "", "  ")
 	if err != nil {
 		return err
 	}
@@ -104,6 +104,16 @@ func (f *FileStorage) Save(cfg *Config) error {
 	return os.Chmod(f.path, 0o600)
 }
 
+// persistable 返回写入磁盘的配置：Remote 已包含在 Remotes 中，不重复写出
+func persistable(cfg *Config) *Config {
+	if len(cfg.Remotes) == 0 {
+		return cfg
+	}
+	out := *cfg
+	out.Remote = nil
+	return &out
+}
+
 // ensureConfigDir 确保目录存在并具有安全权限
 func ensureConfigDir(path string) error {
 	dir := filepath.Dir(path)
@@ -132,7 +142,7 @@ func (m *MemoryStorage) Load() (*Config, error) {
 
 // Save 更新内存中的配置内容
 func (m *MemoryStorage) Save(cfg *Config) error {
-	data, err := json.Marshal(cfg)
+	data, err := json.Marshal(persistable(cfg))
 	if err != nil {
 		return err
 	}
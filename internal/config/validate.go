@@ -0,0 +1,206 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Severity 表示校验问题的严重程度
+type Severity string
+
+// 校验问题级别
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Issue 描述配置中的一个问题，Path 使用 JSON 路径定位字段
+type Issue struct {
+	Severity Severity `json:"severity"`
+	Path     string   `json:"path"`
+	Message  string   `json:"message"`
+}
+
+// String 返回便于终端输出的单行描述
+func (i Issue) String() string {
+	return fmt.Sprintf("[%s] %s: %s", i.Severity, i.Path, i.Message)
+}
+
+// HasErrors 判断问题列表中是否存在错误级别的问题
+func HasErrors(issues []Issue) bool {
+	return ErrorCount(issues) > 0
+}
+
+// ErrorCount 返回错误级别问题的数量，不含警告
+func ErrorCount(issues []Issue) int {
+	count := 0
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			count++
+		}
+	}
+	return count
+}
+
+// Validate 检查配置的完整性与一致性，不会修改配置。
+//
+// 重复名称/ID、空密钥、非法 Base URL 视为错误；未知类型、多个激活 Key、
+// 激活 ID 失效以及远程配置缺项视为警告，这些问题会在 ReplaceConfig 中被自动修正。
+func Validate(cfg *Config) []Issue {
+	if cfg == nil {
+		return []Issue{{Severity: SeverityError, Path: "$", Message: "配置为空"}}
+	}
+
+	var issues []Issue
+	add := func(severity Severity, path string, format string, args ...any) {
+		issues = append(issues, Issue{Severity: severity, Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	names := make(map[string]int, len(cfg.Keys))
+	ids := make(map[string]int, len(cfg.Keys))
+	var activeIDs []string
+	// 团队配置中的 Key 不在本地列表中，由 Manager 加载缓存后校验
+	activeFound := cfg.ActiveKeyID == "" || IsTeamKeyID(cfg.ActiveKeyID)
+
+	for i, key := range cfg.Keys {
+		path := fmt.Sprintf("$.keys[%d]", i)
+
+		name := strings.TrimSpace(key.Name)
+		if name == "" {
+			add(SeverityError, path+".name", "名称不能为空")
+		} else if prev, ok := names[strings.ToLower(name)]; ok {
+			add(SeverityError, path+".name", "名称 %s 与 $.keys[%d] 重复", name, prev)
+		} else {
+			names[strings.ToLower(name)] = i
+		}
+
+		if key.ID != "" {
+			if prev, ok := ids[key.ID]; ok {
+				add(SeverityError, path+".id", "ID %s 与 $.keys[%d] 重复", key.ID, prev)
+			} else {
+				ids[key.ID] = i
+			}
+			if key.ID == cfg.ActiveKeyID {
+				activeFound = true
+			}
+		}
+
+		switch {
+		case strings.TrimSpace(key.APIKey) == "":
+			add(SeverityError, path+".api_key", "API Key 不能为空")
+		case IsSecretPlaceholder(key.APIKey):
+			add(SeverityWarning, path+".api_key", "API Key 为脱敏占位符 %s", key.APIKey)
+		}
+
+		if base := strings.TrimSpace(key.BaseURL); base != "" {
+			if msg := checkBaseURL(base); msg != "" {
+				add(SeverityError, path+".base_url", "%s: %s", msg, base)
+			}
+		}
+
+		switch strings.ToLower(strings.TrimSpace(key.Type)) {
+		case "", TypeOpenAI, TypeCRS:
+		default:
+			add(SeverityWarning, path+".type", "未知类型 %s，支持 %s/%s", key.Type, TypeOpenAI, TypeCRS)
+		}
+
+		switch strings.ToLower(strings.TrimSpace(key.WireAPI)) {
+		case "", "responses", "chat":
+		default:
+			add(SeverityWarning, path+".wire_api", "未知 wire_api %s", key.WireAPI)
+		}
+
+		if key.Active {
+			activeIDs = append(activeIDs, path)
+		}
+	}
+
+	if len(activeIDs) > 1 {
+		add(SeverityWarning, "$.keys", "存在 %d 个 active=true 的 Key (%s)，仅会保留一个", len(activeIDs), strings.Join(activeIDs, ", "))
+	}
+	if !activeFound {
+		add(SeverityWarning, "$.active_key_id", "激活 ID %s 不存在", cfg.ActiveKeyID)
+	}
+
+	remotes := map[string]*RemoteSettings{"$.remote": cfg.Remote}
+	if len(cfg.Remotes) > 0 {
+		remotes = make(map[string]*RemoteSettings, len(cfg.Remotes))
+		for name, r := range cfg.Remotes {
+			remotes["$.remotes."+name] = r
+		}
+		if _, ok := cfg.Remotes[cfg.DefaultRemote]; cfg.DefaultRemote != "" && !ok {
+			add(SeverityWarning, "$.default_remote", "默认远程 %s 不存在", cfg.DefaultRemote)
+		}
+	}
+	paths := make([]string, 0, len(remotes))
+	for path := range remotes {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if r := remotes[path]; r != nil && r.Enabled {
+			validateRemote(path, r, add)
+		}
+	}
+
+	if cfg.Hooks != nil {
+		for _, event := range HookEvents {
+			for i, command := range cfg.Hooks.Commands(event) {
+				if strings.TrimSpace(command) == "" {
+					add(SeverityWarning, fmt.Sprintf("$.hooks.%s[%d]", event, i), "hook 路径为空")
+				}
+			}
+		}
+		if cfg.Hooks.Timeout < 0 {
+			add(SeverityWarning, "$.hooks.timeout", "超时时间不能为负数，将使用默认值")
+		}
+	}
+	return issues
+}
+
+// validateRemote 校验单个已启用的远程配置，path 为其在配置中的位置
+func validateRemote(path string, r *RemoteSettings, add func(severity Severity, path string, format string, args ...any)) {
+	switch strings.ToLower(strings.TrimSpace(r.Provider)) {
+	case "webdav":
+		if msg := checkBaseURL(strings.TrimSpace(r.URL)); msg != "" {
+			add(SeverityWarning, path+".url", "WebDAV 地址无效: %s", msg)
+		}
+	case "dir":
+		if strings.TrimSpace(r.Directory) == "" {
+			add(SeverityWarning, path+".directory", "已启用目录同步但未配置目录")
+		}
+	case "git":
+		if strings.TrimSpace(r.Repo) == "" {
+			add(SeverityWarning, path+".repo", "已启用 Git 同步但未配置仓库")
+		}
+		if r.DisableEncryption && !r.AllowPlaintext {
+			add(SeverityWarning, path+".disable_encryption", "Git 后端拒绝提交明文快照，需同时设置 allow_plaintext")
+		}
+	default:
+		if strings.TrimSpace(r.BucketName) == "" {
+			add(SeverityWarning, path+".bucket_name", "已启用远程同步但未配置存储桶")
+		}
+		// 凭据保存在环境变量、凭据文件或由命令提供时，配置文件中本就为空
+		stored := r.CredentialStore == "" || r.CredentialStore == CredentialStoreConfig
+		if stored && (strings.TrimSpace(r.KeyID) == "" || strings.TrimSpace(r.ApplicationKey) == "") {
+			add(SeverityWarning, path, "已启用远程同步但缺少 key_id 或 application_key")
+		}
+	}
+}
+
+// checkBaseURL 校验 URL 是否为带主机名的 http/https 地址，合法时返回空字符串
+func checkBaseURL(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return "无法解析的 Base URL"
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return "Base URL 必须使用 http 或 https"
+	}
+	if parsed.Host == "" {
+		return "Base URL 缺少主机名"
+	}
+	return ""
+}
@@ -41,6 +41,7 @@ func PrintKeyTable(out io.Writer, keys []config.APIKey) {
 		ColorPrimary.Sprint("名称"),
 		ColorPrimary.Sprint("ID"),
 		ColorPrimary.Sprint("类型"),
+		ColorPrimary.Sprint("来源"),
 		ColorPrimary.Sprint("标签"),
 		ColorPrimary.Sprint("最后使用"),
 	})
@@ -48,10 +49,11 @@ func PrintKeyTable(out io.Writer, keys []config.APIKey) {
 	writer.SetColumnConfigs([]prettytable.ColumnConfig{
 		{Number: 1, Align: text.AlignCenter, WidthMax: 6},
 		{Number: 2, Align: text.AlignLeft, WidthMin: 12},
-		{Number: 3, Align: text.AlignCenter, WidthMax: 6},
+		{Number: 3, Align: text.AlignCenter, WidthMax: 24},
 		{Number: 4, Align: text.AlignCenter, WidthMax: 10},
-		{Number: 5, Align: text.AlignLeft, WidthMin: 16},
+		{Number: 5, Align: text.AlignCenter, WidthMax: 20},
 		{Number: 6, Align: text.AlignLeft, WidthMin: 16},
+		{Number: 7, Align: text.AlignLeft, WidthMin: 16},
 	})
 
 	for _, key := range keys {
@@ -64,12 +66,17 @@ func PrintKeyTable(out io.Writer, keys []config.APIKey) {
 		if len(key.Tags) > 0 {
 			tagDisplay = strings.Join(key.Tags, ", ")
 		}
+		name := key.Name
+		if key.LocalOnly {
+			name += " (仅本地)"
+		}
 
 		writer.AppendRow(prettytable.Row{
 			status,
-			key.Name,
+			name,
 			key.ID,
 			strings.ToUpper(key.Type),
+			SourceLabel(key),
 			tagDisplay,
 			utils.FormatRelativeTime(key.LastUsed),
 		})
@@ -78,6 +85,14 @@ func PrintKeyTable(out io.Writer, keys []config.APIKey) {
 	writer.Render()
 }
 
+// SourceLabel 返回 Key 来源的展示文本，团队配置中的 Key 标记为只读
+func SourceLabel(key config.APIKey) string {
+	if key.Source == "" {
+		return "本地"
+	}
+	return key.Source + " (只读)"
+}
+
 // PrintKeyDetail 输出指定 Key 的详细信息
 func PrintKeyDetail(out io.Writer, key config.APIKey) {
 	fmt.Fprintln(out, ColorPrimary.Sprint("╔═══════════════════════════════════════════════════════════════════╗"))
@@ -93,6 +108,7 @@ func PrintKeyDetail(out io.Writer, key config.APIKey) {
 		state = "✓ 激活中"
 	}
 	fmt.Fprintf(out, "  状态:          %s\n", state)
+	fmt.Fprintf(out, "  来源:          %s\n", SourceLabel(key))
 
 	fmt.Fprintf(out, "\n  API 配置\n  ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
 	fmt.Fprintf(out, "  Base URL:      %s\n", key.BaseURL)
@@ -0,0 +1,165 @@
+// Package hooks 负责在 Key 变化时执行用户配置的脚本。
+//
+// hook 为可执行文件(不经过 shell)，通过环境变量与标准输入获取 Key 的非敏感元数据，
+// 标准输出与标准错误直接透传给用户。
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+	"github.com/codex-switch/codex-switch/internal/logging"
+)
+
+// KeyInfo 为传给 hook 的 Key 元数据，不包含密钥与原始配置
+type KeyInfo struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Type      string   `json:"type"`
+	BaseURL   string   `json:"base_url,omitempty"`
+	Provider  string   `json:"provider,omitempty"`
+	Tags      []string `json:"tags"`
+	Source    string   `json:"source,omitempty"`
+	LocalOnly bool     `json:"local_only,omitempty"`
+}
+
+// NewKeyInfo 提取 Key 的非敏感字段
+func NewKeyInfo(key config.APIKey) *KeyInfo {
+	tags := key.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return &KeyInfo{
+		ID:        key.ID,
+		Name:      key.Name,
+		Type:      key.Type,
+		BaseURL:   key.BaseURL,
+		Provider:  key.Provider,
+		Tags:      tags,
+		Source:    key.Source,
+		LocalOnly: key.LocalOnly,
+	}
+}
+
+// Payload 为通过标准输入传给 hook 的 JSON 内容
+type Payload struct {
+	Event string `json:"event"`
+	// Key 为事件涉及的 Key：切换的目标、新增或删除的 Key、拉取后激活的 Key
+	Key *KeyInfo `json:"key,omitempty"`
+	// Previous 为切换前激活的 Key，仅 pre_switch/post_switch 提供
+	Previous *KeyInfo `json:"previous,omitempty"`
+	// Profile 与 Remote 为拉取的配置档案与远程名称，仅 post_pull 提供
+	Profile string `json:"profile,omitempty"`
+	Remote  string `json:"remote,omitempty"`
+}
+
+// Runner 按配置执行 hook
+type Runner struct {
+	settings *config.HookSettings
+	stdout   io.Writer
+	stderr   io.Writer
+}
+
+// NewRunner 创建 Runner，hook 的输出写入 stdout 与 stderr
+func NewRunner(settings *config.HookSettings, stdout, stderr io.Writer) *Runner {
+	return &Runner{settings: settings, stdout: stdout, stderr: stderr}
+}
+
+// Run 依次执行 payload.Event 对应的全部 hook，遇到失败立即返回，未配置时直接返回 nil
+func (r *Runner) Run(ctx context.Context, payload Payload) error {
+	commands := r.settings.Commands(payload.Event)
+	if len(commands) == 0 {
+		return nil
+	}
+	input, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	env := append(os.Environ(), payloadEnv(payload)...)
+	for _, command := range commands {
+		if err := r.runOne(ctx, command, input, env); err != nil {
+			return fmt.Errorf("%s hook %s 执行失败: %w", payload.Event, command, err)
+		}
+		logging.Debugf("执行 hook: event=%s command=%s", payload.Event, command)
+	}
+	return nil
+}
+
+// runOne 执行单个 hook，超时后终止进程
+func (r *Runner) runOne(ctx context.Context, command string, input []byte, env []string) error {
+	path, err := expandPath(command)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.settings.TimeoutDuration())
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = r.stdout
+	cmd.Stderr = r.stderr
+	cmd.Env = env
+	// hook 启动的后台进程可能继承输出管道，超时后不再等待其关闭
+	cmd.WaitDelay = time.Second
+
+	err = cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("超过 %s 未完成", r.settings.TimeoutDuration())
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return fmt.Errorf("退出码 %d", exitErr.ExitCode())
+	}
+	return err
+}
+
+// payloadEnv 将 payload 转换为 CKM_ 前缀的环境变量
+func payloadEnv(payload Payload) []string {
+	env := []string{"CKM_HOOK_EVENT=" + payload.Event}
+	if key := payload.Key; key != nil {
+		env = append(env,
+			"CKM_KEY_ID="+key.ID,
+			"CKM_KEY_NAME="+key.Name,
+			"CKM_KEY_TYPE="+key.Type,
+			"CKM_KEY_BASE_URL="+key.BaseURL,
+			"CKM_KEY_TAGS="+strings.Join(key.Tags, ","),
+			"CKM_KEY_SOURCE="+key.Source,
+		)
+	}
+	if prev := payload.Previous; prev != nil {
+		env = append(env, "CKM_PREVIOUS_KEY_ID="+prev.ID, "CKM_PREVIOUS_KEY_NAME="+prev.Name)
+	}
+	if payload.Profile != "" {
+		env = append(env, "CKM_PROFILE="+payload.Profile)
+	}
+	if payload.Remote != "" {
+		env = append(env, "CKM_REMOTE="+payload.Remote)
+	}
+	return env
+}
+
+// expandPath 展开路径开头的 ~，不含路径分隔符的名称从 PATH 中查找
+func expandPath(command string) (string, error) {
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return "", errors.New("hook 路径为空")
+	}
+	if command == "~" || strings.HasPrefix(command, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, strings.TrimPrefix(command, "~")), nil
+	}
+	return command, nil
+}
@@ -0,0 +1,85 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+)
+
+// writeScript 在临时目录中创建可执行脚本
+func writeScript(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "hook.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0o700); err != nil {
+		t.Fatalf("写入脚本失败: %v", err)
+	}
+	return path
+}
+
+// TestRunnerPassesMetadata 验证 hook 通过环境变量与标准输入获得 Key 元数据且不包含密钥
+func TestRunnerPassesMetadata(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("依赖 /bin/sh")
+	}
+	out := filepath.Join(t.TempDir(), "out")
+	script := writeScript(t, `echo "$CKM_HOOK_EVENT $CKM_KEY_NAME $CKM_KEY_TAGS $CKM_PREVIOUS_KEY_NAME" > `+out+`.env
+cat > `+out+`.json
+`)
+	settings := &config.HookSettings{PostSwitch: []string{script}}
+	key := config.APIKey{ID: "2", Name: "relay", APIKey: "sk-secret", Type: config.TypeOpenAI, Tags: []string{"a", "b"}}
+	payload := Payload{Event: config.HookPostSwitch, Key: NewKeyInfo(key), Previous: NewKeyInfo(config.APIKey{ID: "1", Name: "old"})}
+
+	if err := NewRunner(settings, &bytes.Buffer{}, &bytes.Buffer{}).Run(context.Background(), payload); err != nil {
+		t.Fatalf("执行 hook 失败: %v", err)
+	}
+	env, _ := os.ReadFile(out + ".env")
+	if strings.TrimSpace(string(env)) != "post_switch relay a,b old" {
+		t.Fatalf("环境变量不正确: %q", env)
+	}
+	stdin, _ := os.ReadFile(out + ".json")
+	if strings.Contains(string(stdin), "sk-secret") {
+		t.Fatalf("标准输入不应包含密钥: %s", stdin)
+	}
+	var got Payload
+	if err := json.Unmarshal(stdin, &got); err != nil || got.Key.ID != "2" || got.Previous.Name != "old" {
+		t.Fatalf("标准输入 JSON 不正确: %s err=%v", stdin, err)
+	}
+
+	// 未配置的事件直接返回
+	if err := NewRunner(nil, nil, nil).Run(context.Background(), Payload{Event: config.HookPreSwitch}); err != nil {
+		t.Fatalf("未配置 hook 时不应报错: %v", err)
+	}
+}
+
+// TestRunnerFailures 验证非零退出码与超时均返回错误，且失败后不再执行后续 hook
+func TestRunnerFailures(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("依赖 /bin/sh")
+	}
+	marker := filepath.Join(t.TempDir(), "ran")
+	failing := writeScript(t, "exit 3\n")
+	next := writeScript(t, "touch "+marker+"\n")
+
+	settings := &config.HookSettings{PreSwitch: []string{failing, next}}
+	err := NewRunner(settings, &bytes.Buffer{}, &bytes.Buffer{}).Run(context.Background(), Payload{Event: config.HookPreSwitch})
+	if err == nil || !strings.Contains(err.Error(), "退出码 3") {
+		t.Fatalf("期待退出码错误，got=%v", err)
+	}
+	if _, statErr := os.Stat(marker); statErr == nil {
+		t.Fatalf("失败后不应继续执行后续 hook")
+	}
+
+	slow := writeScript(t, "sleep 5\n")
+	settings = &config.HookSettings{PostPull: []string{slow}, Timeout: 1}
+	err = NewRunner(settings, &bytes.Buffer{}, &bytes.Buffer{}).Run(context.Background(), Payload{Event: config.HookPostPull})
+	if err == nil || !strings.Contains(err.Error(), "超过") {
+		t.Fatalf("期待超时错误，got=%v", err)
+	}
+}
@@ -0,0 +1,239 @@
+// Package aider 将激活的 Key 同步到 aider 的 ~/.aider.conf.yml。
+//
+// 仅修改 openai-api-key、openai-api-base 与 model 三项，其他配置与注释原样保留。
+package aider
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+	"github.com/codex-switch/codex-switch/internal/integration"
+	"github.com/codex-switch/codex-switch/internal/logging"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Name 为 aider 集成目标的名称
+const Name = "aider"
+
+// aider 配置文件中由 ckm 维护的字段
+const (
+	fieldAPIKey  = "openai-api-key"
+	fieldAPIBase = "openai-api-base"
+	fieldModel   = "model"
+)
+
+var _ integration.Target = (*Configurator)(nil)
+
+func init() {
+	integration.Register(Name, func() (integration.Target, error) {
+		return NewConfigurator("")
+	})
+}
+
+// Configurator 负责根据 API Key 更新 aider 配置文件
+type Configurator struct {
+	ConfigPath string
+}
+
+// NewConfigurator 创建配置器实例，默认路径为 ~/.aider.conf.yml
+func NewConfigurator(configPath string) (*Configurator, error) {
+	if configPath == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		configPath = filepath.Join(home, ".aider.conf.yml")
+	}
+	return &Configurator{ConfigPath: configPath}, nil
+}
+
+// Name 返回目标名称
+func (c *Configurator) Name() string {
+	return Name
+}
+
+// Detect 在 PATH 中存在 aider 命令或配置文件已存在时返回 true
+func (c *Configurator) Detect() bool {
+	if _, err := exec.LookPath("aider"); err == nil {
+		return true
+	}
+	_, err := os.Stat(c.ConfigPath)
+	return err == nil
+}
+
+// Plan 返回更新后的配置文件内容，其中的密钥已脱敏
+func (c *Configurator) Plan(key config.APIKey) ([]integration.Change, error) {
+	existing, err := c.readExisting()
+	if err != nil {
+		return nil, err
+	}
+	masked := key
+	masked.APIKey = config.MaskSecret(key.APIKey)
+	content, err := render(existing, masked)
+	if err != nil {
+		return nil, err
+	}
+	summary := "更新 openai-api-key、openai-api-base 与 model，保留其他配置与注释"
+	if existing != nil {
+		summary += "，原文件备份为 " + filepath.Base(c.backupPath())
+	}
+	return []integration.Change{{Path: c.ConfigPath, Summary: summary, Preview: string(content)}}, nil
+}
+
+// Apply 更新配置文件，写入前将原文件备份为 .bak
+func (c *Configurator) Apply(key config.APIKey) error {
+	existing, err := c.readExisting()
+	if err != nil {
+		return err
+	}
+	content, err := render(existing, key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.ConfigPath), 0o755); err != nil {
+		return fmt.Errorf("创建配置目录失败: %w", err)
+	}
+	if existing != nil {
+		if err := os.WriteFile(c.backupPath(), existing, 0o600); err != nil {
+			return fmt.Errorf("备份 aider 配置失败: %w", err)
+		}
+	}
+	temp := c.ConfigPath + ".tmp"
+	if err := os.WriteFile(temp, content, 0o600); err != nil {
+		return fmt.Errorf("写入临时文件失败: %w", err)
+	}
+	if err := os.Rename(temp, c.ConfigPath); err != nil {
+		return fmt.Errorf("替换配置文件失败: %w", err)
+	}
+	logging.Infof("完成同步 aider 配置: key=%s(%s), config_path=%s", key.Name, key.ID, c.ConfigPath)
+	return nil
+}
+
+func (c *Configurator) backupPath() string {
+	return c.ConfigPath + ".bak"
+}
+
+// readExisting 读取现有配置，文件不存在时返回 nil
+func (c *Configurator) readExisting() ([]byte, error) {
+	data, err := os.ReadFile(c.ConfigPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取 aider 配置失败: %w", err)
+	}
+	return data, nil
+}
+
+// render 在现有 YAML 上修改 ckm 维护的字段并返回新内容。
+// Key 未提供 base_url 时移除 openai-api-base；无法确定模型时保留原有 model。
+func render(existing []byte, key config.APIKey) ([]byte, error) {
+	var doc yaml.Node
+	if len(bytes.TrimSpace(existing)) > 0 {
+		if err := yaml.Unmarshal(existing, &doc); err != nil {
+			return nil, fmt.Errorf("解析 aider 配置失败: %w", err)
+		}
+	}
+	// 空文件或仅包含注释的文件解析后没有内容节点，yaml.v3 会丢弃这些注释，
+	// 因此原样保留原文并在其后追加新的映射
+	var buf bytes.Buffer
+	if len(doc.Content) == 0 {
+		if text := bytes.TrimRight(existing, "\r\n\t "); len(text) > 0 {
+			buf.Write(text)
+			buf.WriteString("\n")
+		}
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, errors.New("aider 配置的顶层不是 YAML 映射")
+	}
+
+	baseURL, model := keySettings(key)
+	setField(root, fieldAPIKey, key.APIKey)
+	if baseURL != "" {
+		setField(root, fieldAPIBase, baseURL)
+	} else {
+		deleteField(root, fieldAPIBase)
+	}
+	if model != "" {
+		setField(root, fieldModel, model)
+	}
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// rawCodexConfig 为从 Key 原始 Codex 配置中读取的字段
+type rawCodexConfig struct {
+	Model          string `toml:"model"`
+	ModelProvider  string `toml:"model_provider"`
+	ModelProviders map[string]struct {
+		BaseURL string `toml:"base_url"`
+	} `toml:"model_providers"`
+}
+
+// keySettings 返回 Key 对应的 API 地址与 aider 模型名称。
+// base_url 优先使用 Key 字段，其次为原始 Codex 配置中当前提供商的地址；
+// 模型取自原始配置，使用自定义地址时按 aider 的约定加上 openai/ 前缀。
+func keySettings(key config.APIKey) (string, string) {
+	baseURL := strings.TrimSpace(key.BaseURL)
+	var raw rawCodexConfig
+	if strings.TrimSpace(key.RawConfig) != "" {
+		if err := toml.Unmarshal([]byte(key.RawConfig), &raw); err != nil {
+			logging.Debugf("解析 Key 原始配置失败，忽略模型与地址: key=%s err=%v", key.ID, err)
+		}
+	}
+	if baseURL == "" {
+		baseURL = strings.TrimSpace(raw.ModelProviders[raw.ModelProvider].BaseURL)
+	}
+	model := strings.TrimSpace(raw.Model)
+	if model != "" && baseURL != "" && !strings.Contains(model, "/") {
+		model = "openai/" + model
+	}
+	return baseURL, model
+}
+
+// setField 设置映射中的字符串字段，已存在时保留其位置与注释
+func setField(mapping *yaml.Node, name, value string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == name {
+			node := mapping.Content[i+1]
+			node.Kind = yaml.ScalarNode
+			node.Tag = "!!str"
+			node.Value = value
+			node.Content = nil
+			return
+		}
+	}
+	mapping.Content = append(mapping.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value},
+	)
+}
+
+// deleteField 删除映射中的字段
+func deleteField(mapping *yaml.Node, name string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == name {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return
+		}
+	}
+}
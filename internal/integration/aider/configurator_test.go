@@ -0,0 +1,120 @@
+package aider
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+)
+
+func TestConfiguratorApply(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".aider.conf.yml")
+	original := `# 团队共用的 aider 配置
+dark-mode: true # 终端主题
+openai-api-key: sk-old
+openai-api-base: https://old.example.com/v1
+model: gpt-4o
+`
+	if err := os.WriteFile(path, []byte(original), 0o600); err != nil {
+		t.Fatalf("写入初始配置失败: %v", err)
+	}
+
+	conf := &Configurator{ConfigPath: path}
+	key := config.APIKey{
+		ID:     "k1",
+		Name:   "测试",
+		APIKey: "sk-new",
+		RawConfig: `model_provider = "duck"
+model = "gpt-5"
+
+[model_providers.duck]
+base_url = "https://jp.duckcoding.com/v1"
+`,
+	}
+	if err := conf.Apply(key); err != nil {
+		t.Fatalf("Apply 返回错误: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("读取配置失败: %v", err)
+	}
+	content := string(data)
+	for _, want := range []string{
+		"# 团队共用的 aider 配置",
+		"dark-mode: true # 终端主题",
+		"openai-api-key: sk-new",
+		"openai-api-base: https://jp.duckcoding.com/v1",
+		"model: openai/gpt-5",
+	} {
+		if !strings.Contains(content, want) {
+			t.Fatalf("配置缺少 %q:\n%s", want, content)
+		}
+	}
+
+	backup, err := os.ReadFile(path + ".bak")
+	if err != nil || string(backup) != original {
+		t.Fatalf("备份内容不符: %v\n%s", err, backup)
+	}
+}
+
+func TestConfiguratorApplyWithoutBaseURL(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".aider.conf.yml")
+	if err := os.WriteFile(path, []byte("openai-api-base: https://old.example.com\nmodel: gpt-4o\n"), 0o600); err != nil {
+		t.Fatalf("写入初始配置失败: %v", err)
+	}
+
+	conf := &Configurator{ConfigPath: path}
+	if err := conf.Apply(config.APIKey{ID: "k1", APIKey: "sk-official"}); err != nil {
+		t.Fatalf("Apply 返回错误: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	content := string(data)
+	if strings.Contains(content, "openai-api-base") {
+		t.Fatalf("未提供地址时应移除 openai-api-base:\n%s", content)
+	}
+	if !strings.Contains(content, "model: gpt-4o") {
+		t.Fatalf("无法确定模型时应保留原有 model:\n%s", content)
+	}
+}
+
+func TestConfiguratorPlan(t *testing.T) {
+	dir := t.TempDir()
+	conf := &Configurator{ConfigPath: filepath.Join(dir, ".aider.conf.yml")}
+	key := config.APIKey{ID: "k1", APIKey: "sk-test-123456", BaseURL: "https://api.example.com/v1"}
+
+	changes, err := conf.Plan(key)
+	if err != nil {
+		t.Fatalf("Plan 返回错误: %v", err)
+	}
+	if len(changes) != 1 {
+		t.Fatalf("应返回一项修改，实际 %d", len(changes))
+	}
+	preview := changes[0].Preview
+	if strings.Contains(preview, "sk-test-123456") || !strings.Contains(preview, "https://api.example.com/v1") {
+		t.Fatalf("预览内容不符:\n%s", preview)
+	}
+	if _, err := os.Stat(conf.ConfigPath); !os.IsNotExist(err) {
+		t.Fatalf("Plan 不应写入文件")
+	}
+}
+
+func TestRenderRejectsNonMapping(t *testing.T) {
+	if _, err := render([]byte("- a\n- b\n"), config.APIKey{APIKey: "sk"}); err == nil {
+		t.Fatalf("顶层不是映射时应返回错误")
+	}
+}
+
+func TestRenderKeepsCommentOnlyFile(t *testing.T) {
+	content, err := render([]byte("# 仅有注释\n"), config.APIKey{APIKey: "sk-new"})
+	if err != nil {
+		t.Fatalf("render 返回错误: %v", err)
+	}
+	if !strings.HasPrefix(string(content), "# 仅有注释\n") || !strings.Contains(string(content), "openai-api-key: sk-new") {
+		t.Fatalf("内容不符:\n%s", content)
+	}
+}
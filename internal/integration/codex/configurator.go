@@ -37,8 +37,29 @@ func (c *Configurator) Apply(key config.APIKey) error {
 	return nil
 }
 
-// updateConfigToml 生成核心段落并与原文件内容合并
+// updateConfigToml 生成核心段落并与原文件内容合并后写入
 func (c *Configurator) updateConfigToml(key config.APIKey) error {
+	content, err := c.renderConfigToml(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.ConfigPath), 0o755); err != nil {
+		return fmt.Errorf("创建配置目录失败: %w", err)
+	}
+
+	temp := c.ConfigPath + ".tmp"
+	if err := os.WriteFile(temp, []byte(content), 0o600); err != nil {
+		return fmt.Errorf("写入临时文件失败: %w", err)
+	}
+	if err := os.Rename(temp, c.ConfigPath); err != nil {
+		return fmt.Errorf("替换配置文件失败: %w", err)
+	}
+	return nil
+}
+
+// renderConfigToml 返回写入 config.toml 的完整内容：原始配置直接使用，
+// 否则生成核心片段并保留现有文件中的 [mcp_servers] 段落
+func (c *Configurator) renderConfigToml(key config.APIKey) (string, error) {
 	if trimmed := strings.TrimSpace(key.RawConfig); trimmed != "" {
 		content := sanitizeRawConfig(key.RawConfig)
 		if strings.TrimSpace(content) == "" {
@@ -48,17 +69,7 @@ func (c *Configurator) updateConfigToml(key config.APIKey) error {
 			if !strings.HasSuffix(content, "\n") {
 				content += "\n"
 			}
-			if err := os.MkdirAll(filepath.Dir(c.ConfigPath), 0o755); err != nil {
-				return fmt.Errorf("创建配置目录失败: %w", err)
-			}
-			temp := c.ConfigPath + ".tmp"
-			if err := os.WriteFile(temp, []byte(content), 0o600); err != nil {
-				return fmt.Errorf("写入临时文件失败: %w", err)
-			}
-			if err := os.Rename(temp, c.ConfigPath); err != nil {
-				return fmt.Errorf("替换配置文件失败: %w", err)
-			}
-			return nil
+			return content, nil
 		}
 	}
 
@@ -80,7 +91,7 @@ func (c *Configurator) updateConfigToml(key config.APIKey) error {
 			logging.Debugf("未在现有配置中找到 [mcp_servers] 段落，将直接覆盖核心片段")
 		}
 	} else if !os.IsNotExist(err) {
-		return fmt.Errorf("读取配置失败: %w", err)
+		return "", fmt.Errorf("读取配置失败: %w", err)
 	}
 
 	builder := &strings.Builder{}
@@ -91,19 +102,7 @@ func (c *Configurator) updateConfigToml(key config.APIKey) error {
 		builder.WriteString(rest)
 		builder.WriteString("\n")
 	}
-
-	if err := os.MkdirAll(filepath.Dir(c.ConfigPath), 0o755); err != nil {
-		return fmt.Errorf("创建配置目录失败: %w", err)
-	}
-
-	temp := c.ConfigPath + ".tmp"
-	if err := os.WriteFile(temp, []byte(builder.String()), 0o600); err != nil {
-		return fmt.Errorf("写入临时文件失败: %w", err)
-	}
-	if err := os.Rename(temp, c.ConfigPath); err != nil {
-		return fmt.Errorf("替换配置文件失败: %w", err)
-	}
-	return nil
+	return builder.String(), nil
 }
 
 // updateAuthJSON 更新认证文件中的 API Key
@@ -458,3 +458,26 @@ func TestSanitizeRawConfig(t *testing.T) {
 		t.Fatalf("尾部应保留配置主体，实际为: %s", content)
 	}
 }
+
+func TestConfiguratorPlan(t *testing.T) {
+	dir := t.TempDir()
+	conf := &Configurator{ConfigPath: filepath.Join(dir, "config.toml"), AuthPath: filepath.Join(dir, "auth.json")}
+	key := config.APIKey{ID: "k1", Name: "测试", APIKey: "sk-test-123456", BaseURL: "https://api.openai.com/v1", Type: "openai"}
+
+	changes, err := conf.Plan(key)
+	if err != nil {
+		t.Fatalf("Plan 返回错误: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("应返回 config.toml 与 auth.json 两项修改，实际 %d", len(changes))
+	}
+	if !strings.Contains(changes[0].Preview, "https://api.openai.com/v1") {
+		t.Fatalf("config.toml 预览缺少 base_url: %s", changes[0].Preview)
+	}
+	if strings.Contains(changes[1].Preview, "sk-test-123456") {
+		t.Fatalf("auth.json 预览不应包含明文密钥: %s", changes[1].Preview)
+	}
+	if _, err := os.Stat(conf.ConfigPath); !os.IsNotExist(err) {
+		t.Fatalf("Plan 不应写入文件")
+	}
+}
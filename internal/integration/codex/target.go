@@ -0,0 +1,57 @@
+package codex
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+	"github.com/codex-switch/codex-switch/internal/integration"
+)
+
+// Name 为 Codex 集成目标的名称
+const Name = "codex"
+
+var _ integration.Target = (*Configurator)(nil)
+
+func init() {
+	integration.Register(Name, func() (integration.Target, error) {
+		return NewConfigurator("", "")
+	})
+}
+
+// Name 返回目标名称
+func (c *Configurator) Name() string {
+	return Name
+}
+
+// Detect 在 PATH 中存在 codex 命令或配置目录已存在时返回 true
+func (c *Configurator) Detect() bool {
+	if _, err := exec.LookPath("codex"); err == nil {
+		return true
+	}
+	_, err := os.Stat(filepath.Dir(c.ConfigPath))
+	return err == nil
+}
+
+// Plan 返回将写入 config.toml 与 auth.json 的内容，auth.json 中的密钥已脱敏
+func (c *Configurator) Plan(key config.APIKey) ([]integration.Change, error) {
+	content, err := c.renderConfigToml(key)
+	if err != nil {
+		return nil, err
+	}
+	auth, err := json.MarshalIndent(map[string]string{"OPENAI_API_KEY": config.MaskSecret(key.APIKey)}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	summary := "写入模型与提供商配置，保留 [mcp_servers] 段落"
+	if strings.TrimSpace(key.RawConfig) != "" {
+		summary = "使用 Key 的原始配置替换"
+	}
+	return []integration.Change{
+		{Path: c.ConfigPath, Summary: summary, Preview: content},
+		{Path: c.AuthPath, Summary: "写入 OPENAI_API_KEY", Preview: string(auth) + "\n"},
+	}, nil
+}
@@ -0,0 +1,97 @@
+// Package integration 定义 ckm 可以配置的外部工具(集成目标)及其注册表。
+//
+// 每个目标在各自的子包中实现 Target 并在 init 中调用 Register，
+// ckm switch 会将激活的 Key 应用到全部已启用的目标。
+package integration
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+)
+
+// DefaultTargets 为未配置 targets 时启用的目标，与旧版本仅同步 Codex 的行为一致
+var DefaultTargets = []string{"codex"}
+
+// Change 描述目标将对一个文件做出的修改
+type Change struct {
+	// Path 为将被写入的文件
+	Path string
+	// Summary 为修改内容的简要说明
+	Summary string
+	// Preview 为写入后的文件内容，密钥已脱敏
+	Preview string
+}
+
+// Target 为可由 ckm 配置的外部工具
+type Target interface {
+	// Name 返回目标名称，用于配置与命令行
+	Name() string
+	// Detect 判断本机是否安装或使用了该工具
+	Detect() bool
+	// Plan 返回应用 key 时将做出的修改，不写入任何文件
+	Plan(key config.APIKey) ([]Change, error)
+	// Apply 将 key 写入工具的配置
+	Apply(key config.APIKey) error
+}
+
+// Factory 创建目标实例，使用默认的配置文件路径
+type Factory func() (Target, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{}
+)
+
+// Register 注册目标，通常在目标子包的 init 中调用；重复注册同名目标会 panic
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("集成目标 %s 重复注册", name))
+	}
+	registry[name] = factory
+}
+
+// Names 返回已注册的目标名称，按名称排序
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Lookup 创建指定名称的目标
+func Lookup(name string) (Target, error) {
+	registryMu.RLock()
+	factory, ok := registry[name]
+	registryMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("未知的集成目标 %s，可选 %s", name, strings.Join(Names(), "/"))
+	}
+	return factory()
+}
+
+// EnabledTargets 返回 key 启用的目标名称：Key 单独配置时优先，其次为全局配置，
+// 均未配置时使用 DefaultTargets
+func EnabledTargets(cfg *config.Config, key config.APIKey) []string {
+	switch {
+	case len(key.Targets) > 0:
+		return slices.Clone(key.Targets)
+	case len(cfg.Targets) > 0:
+		return slices.Clone(cfg.Targets)
+	default:
+		return slices.Clone(DefaultTargets)
+	}
+}
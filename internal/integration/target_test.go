@@ -0,0 +1,59 @@
+package integration
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+)
+
+type fakeTarget struct{ name string }
+
+func (f fakeTarget) Name() string                         { return f.name }
+func (f fakeTarget) Detect() bool                         { return true }
+func (f fakeTarget) Plan(config.APIKey) ([]Change, error) { return nil, nil }
+func (f fakeTarget) Apply(config.APIKey) error            { return nil }
+
+func TestRegistry(t *testing.T) {
+	Register("fake-test", func() (Target, error) { return fakeTarget{name: "fake-test"}, nil })
+
+	if !slices.Contains(Names(), "fake-test") {
+		t.Fatalf("Names 未包含已注册目标: %v", Names())
+	}
+	target, err := Lookup("fake-test")
+	if err != nil || target.Name() != "fake-test" {
+		t.Fatalf("Lookup 结果不符: %v %v", target, err)
+	}
+	if _, err := Lookup("missing"); err == nil {
+		t.Fatalf("未知目标应返回错误")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("重复注册应 panic")
+		}
+	}()
+	Register("fake-test", func() (Target, error) { return fakeTarget{}, nil })
+}
+
+func TestEnabledTargets(t *testing.T) {
+	cfg := &config.Config{}
+	if got := EnabledTargets(cfg, config.APIKey{}); !slices.Equal(got, DefaultTargets) {
+		t.Fatalf("未配置时应使用默认目标，实际 %v", got)
+	}
+
+	cfg.Targets = []string{"codex", "aider"}
+	if got := EnabledTargets(cfg, config.APIKey{}); !slices.Equal(got, cfg.Targets) {
+		t.Fatalf("应使用全局配置，实际 %v", got)
+	}
+
+	key := config.APIKey{Targets: []string{"aider"}}
+	got := EnabledTargets(cfg, key)
+	if !slices.Equal(got, key.Targets) {
+		t.Fatalf("Key 单独配置应优先，实际 %v", got)
+	}
+	got[0] = "changed"
+	if key.Targets[0] != "aider" {
+		t.Fatalf("返回值不应与 Key 共享底层数组")
+	}
+}
@@ -7,7 +7,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
+	"math/rand/v2"
 	"net/http"
 	"net/url"
 	"regexp"
@@ -16,12 +16,32 @@ import (
 	"time"
 
 	"github.com/codex-switch/codex-switch/internal/config"
+	"github.com/codex-switch/codex-switch/internal/logging"
+	"github.com/codex-switch/codex-switch/internal/remote"
+)
+
+// DefaultEndpoint 为 B2 授权接口的默认地址，可通过 RemoteSettings.Endpoint 覆盖
+const DefaultEndpoint = "https://api.backblazeb2.com"
+
+// 重试参数：指数退避 baseDelay*2^n 并加入随机抖动，单次等待不超过 maxDelay
+const (
+	defaultMaxRetries = 4
+	defaultBaseDelay  = 500 * time.Millisecond
+	maxDelay          = 30 * time.Second
 )
 
 // Client 封装 Backblaze B2 API 的最小实现，负责上传/下载快照。
+//
+// 所有请求在遇到 408/429/5xx 或网络错误时按指数退避重试并遵循 Retry-After；
+// 授权令牌过期时自动重新授权。上传 URL 在成功后缓存复用，失败时丢弃。
 type Client struct {
 	httpClient *http.Client
 	settings   *config.RemoteSettings
+	endpoint   string
+
+	maxRetries int
+	baseDelay  time.Duration
+	sleep      func(ctx context.Context, d time.Duration) error
 
 	mu            sync.Mutex
 	accountID     string
@@ -29,25 +49,45 @@ type Client struct {
 	downloadURL   string
 	authToken     string
 	authExpiresAt time.Time
+	uploadTargets []uploadTarget
 }
 
+// uploadTarget 为 b2_get_upload_url 返回的上传地址与专用令牌
+type uploadTarget struct {
+	url   string
+	token string
+}
+
+// listPageSize 为 b2_list_file_names 单页返回的最大文件数
+const listPageSize = 1000
+
 var safeKeyPattern = regexp.MustCompile(`[^a-zA-Z0-9_.-]`)
-var errObjectNotFound = errors.New("b2: object not found")
+var errObjectNotFound = fmt.Errorf("b2: %w", remote.ErrNotFound)
+
+var _ remote.Backend = (*Client)(nil)
 
 // NewClient 根据远程配置创建 B2 客户端。
 func NewClient(settings *config.RemoteSettings) (*Client, error) {
 	if settings == nil {
 		return nil, errors.New("远程配置为空")
 	}
-	if strings.TrimSpace(settings.KeyID) == "" || strings.TrimSpace(settings.ApplicationKey) == "" {
+	if !settings.AccessCredentials().Complete() {
 		return nil, errors.New("缺少 B2 Key 信息")
 	}
 	if strings.TrimSpace(settings.BucketName) == "" {
 		return nil, errors.New("缺少 B2 存储桶名称")
 	}
+	endpoint := strings.TrimRight(strings.TrimSpace(settings.Endpoint), "/")
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
 	return &Client{
 		httpClient: &http.Client{Timeout: 30 * time.Second},
 		settings:   settings,
+		endpoint:   endpoint,
+		maxRetries: defaultMaxRetries,
+		baseDelay:  defaultBaseDelay,
+		sleep:      sleepContext,
 	}, nil
 }
 
@@ -57,39 +97,35 @@ func (c *Client) Upload(ctx context.Context, objectKey string, data []byte) erro
 		return errors.New("上传数据为空")
 	}
 	key := sanitizeObjectKey(objectKey)
-	if err := c.ensureAuthorized(ctx); err != nil {
+	if err := c.Prepare(ctx); err != nil {
 		return err
 	}
-	if c.settings.BucketID == "" {
-		if err := c.fetchBucketID(ctx); err != nil {
+
+	sum := fmt.Sprintf("%x", sha1.Sum(data))
+	err := c.retry(ctx, func() error {
+		target, err := c.acquireUploadTarget(ctx)
+		if err != nil {
 			return err
 		}
-	}
-	uploadURL, uploadToken, err := c.getUploadURL(ctx)
-	if err != nil {
-		return err
-	}
-
-	sum := sha1.Sum(data)
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uploadURL, bytes.NewReader(data))
-	if err != nil {
-		return err
-	}
-	req.Header.Set("Authorization", uploadToken)
-	req.Header.Set("X-Bz-File-Name", key)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Bz-Content-Sha1", fmt.Sprintf("%x", sum))
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.url, bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", target.token)
+		req.Header.Set("X-Bz-File-Name", key)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Bz-Content-Sha1", sum)
 
-	resp, err := c.httpClient.Do(req)
+		// 上传失败后该 URL 可能已失效，B2 要求重新获取
+		if err := c.send(ctx, "上传", req, nil); err != nil {
+			return err
+		}
+		c.releaseUploadTarget(target)
+		return nil
+	})
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
-		return fmt.Errorf("上传失败: %s", strings.TrimSpace(string(body)))
-	}
 
 	c.settings.LastSync = time.Now().UTC()
 	return nil
@@ -98,85 +134,75 @@ func (c *Client) Upload(ctx context.Context, objectKey string, data []byte) erro
 // Download 从 B2 拉取对象。
 func (c *Client) Download(ctx context.Context, objectKey string) ([]byte, error) {
 	key := sanitizeObjectKey(objectKey)
-	if err := c.ensureAuthorized(ctx); err != nil {
-		return nil, err
-	}
-
-	if strings.TrimSpace(c.settings.BucketName) == "" {
-		return nil, errors.New("缺少存储桶名称")
-	}
-
-	downloadURL := fmt.Sprintf("%s/file/%s/%s", c.downloadURL, url.PathEscape(c.settings.BucketName), key)
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, downloadURL, nil)
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("Authorization", c.authToken)
-
-	resp, err := c.httpClient.Do(req)
+	var data []byte
+	err := c.retry(ctx, func() error {
+		if err := c.authorize(ctx); err != nil {
+			return err
+		}
+		_, downloadURL, token := c.session()
+		target := fmt.Sprintf("%s/file/%s/%s", downloadURL, url.PathEscape(c.settings.BucketName), key)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", token)
+		var buf bytes.Buffer
+		if err := c.send(ctx, "下载", req, &buf); err != nil {
+			return err
+		}
+		data = buf.Bytes()
+		return nil
+	})
 	if err != nil {
+		if errors.Is(err, remote.ErrNotFound) {
+			return nil, fmt.Errorf("%w: %s", errObjectNotFound, key)
+		}
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
-		return nil, fmt.Errorf("下载失败: %s", strings.TrimSpace(string(body)))
-	}
-	return io.ReadAll(resp.Body)
+	return data, nil
 }
 
-// Delete 删除远端对象，若对象不存在则视为成功。
+// Delete 删除远端对象的全部版本，若对象不存在则视为成功。
+// b2_delete_file_version 只删除指定版本，仅删除最新版本会让旧版本重新成为当前内容，
+// 因此先列出该对象的所有版本再逐个删除。
 func (c *Client) Delete(ctx context.Context, objectKey string) error {
 	key := sanitizeObjectKey(objectKey)
-	if err := c.ensureAuthorized(ctx); err != nil {
-		return err
-	}
-	if c.settings.BucketID == "" {
-		if err := c.fetchBucketID(ctx); err != nil {
-			return err
-		}
-	}
-
-	fileID, err := c.findFileID(ctx, key)
-	if err != nil {
-		if errors.Is(err, errObjectNotFound) {
-			return nil
-		}
+	if err := c.Prepare(ctx); err != nil {
 		return err
 	}
 
-	endpoint := fmt.Sprintf("%s/b2api/v2/b2_delete_file_version", c.apiURL)
-	payload := map[string]string{
-		"fileName": key,
-		"fileId":   fileID,
-	}
-	body, err := json.Marshal(payload)
+	fileIDs, err := c.findFileVersions(ctx, key)
 	if err != nil {
 		return err
 	}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
-	if err != nil {
-		return err
+	for _, fileID := range fileIDs {
+		payload := map[string]string{
+			"fileName": key,
+			"fileId":   fileID,
+		}
+		err := c.callJSON(ctx, "删除", "b2_delete_file_version", payload, nil)
+		if err != nil && !errors.Is(err, remote.ErrNotFound) {
+			return err
+		}
 	}
-	req.Header.Set("Authorization", c.authToken)
-	req.Header.Set("Content-Type", "application/json")
+	return nil
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
+// Prepare 预先完成授权与存储桶校验，适用于初始化流程。
+func (c *Client) Prepare(ctx context.Context) error {
+	if err := c.retry(ctx, func() error { return c.authorize(ctx) }); err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
-		return fmt.Errorf("删除失败: %s", strings.TrimSpace(string(msg)))
+	if c.settings.BucketID == "" {
+		if err := c.fetchBucketID(ctx); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func (c *Client) ensureAuthorized(ctx context.Context) error {
+// authorize 在令牌缺失或即将过期时调用 b2_authorize_account，不做重试
+func (c *Client) authorize(ctx context.Context) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -184,22 +210,12 @@ func (c *Client) ensureAuthorized(ctx context.Context) error {
 		return nil
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.backblazeb2.com/b2api/v2/b2_authorize_account", nil)
-	if err != nil {
-		return err
-	}
-	req.SetBasicAuth(c.settings.KeyID, c.settings.ApplicationKey)
-
-	resp, err := c.httpClient.Do(req)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint+"/b2api/v2/b2_authorize_account", nil)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
-		return fmt.Errorf("授权失败: %s", strings.TrimSpace(string(body)))
-	}
+	creds := c.settings.AccessCredentials()
+	req.SetBasicAuth(creds.KeyID, creds.ApplicationKey)
 
 	var result struct {
 		AccountID          string `json:"accountId"`
@@ -207,64 +223,165 @@ func (c *Client) ensureAuthorized(ctx context.Context) error {
 		APIURL             string `json:"apiUrl"`
 		DownloadURL        string `json:"downloadUrl"`
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := c.send(ctx, "授权", req, &result); err != nil {
 		return err
 	}
-
 	if result.AccountID == "" || result.AuthorizationToken == "" {
 		return errors.New("授权响应不完整")
 	}
 
 	c.accountID = result.AccountID
 	c.authToken = result.AuthorizationToken
-	c.apiURL = result.APIURL
-	c.downloadURL = result.DownloadURL
+	c.apiURL = strings.TrimRight(result.APIURL, "/")
+	c.downloadURL = strings.TrimRight(result.DownloadURL, "/")
 	c.authExpiresAt = time.Now().Add(22 * time.Hour)
+	// 上传令牌随账户令牌失效，一并丢弃
+	c.uploadTargets = nil
 	return nil
 }
 
-func (c *Client) fetchBucketID(ctx context.Context) error {
+// invalidateAuth 清除当前令牌，下次请求时重新授权
+func (c *Client) invalidateAuth() {
 	c.mu.Lock()
-	apiURL := c.apiURL
-	authToken := c.authToken
-	accountID := c.accountID
-	c.mu.Unlock()
+	defer c.mu.Unlock()
+	c.authToken = ""
+	c.uploadTargets = nil
+}
 
-	endpoint := fmt.Sprintf("%s/b2api/v2/b2_list_buckets", apiURL)
-	payload := map[string]string{
-		"accountId":  accountID,
-		"bucketName": c.settings.BucketName,
+// session 返回当前的 API 地址、下载地址与授权令牌
+func (c *Client) session() (string, string, string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.apiURL, c.downloadURL, c.authToken
+}
+
+// retry 执行 attempt，令牌过期时重新授权一次，临时错误按指数退避重试
+func (c *Client) retry(ctx context.Context, attempt func() error) error {
+	reauthorized := false
+	for n := 0; ; n++ {
+		err := attempt()
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+
+		var apiErr *APIError
+		isAPIErr := errors.As(err, &apiErr)
+		if isAPIErr && apiErr.expiredToken() && !reauthorized {
+			logging.Debugf("B2 授权令牌已过期，重新授权")
+			reauthorized = true
+			c.invalidateAuth()
+			continue
+		}
+		if !errors.Is(err, ErrTransient) || n >= c.maxRetries {
+			return err
+		}
+
+		delay := c.backoff(n)
+		if isAPIErr && apiErr.RetryAfter > delay {
+			delay = min(apiErr.RetryAfter, maxDelay)
+		}
+		logging.Debugf("B2 请求失败，%s 后重试 (%d/%d): %v", delay, n+1, c.maxRetries, err)
+		if err := c.sleep(ctx, delay); err != nil {
+			return err
+		}
 	}
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return err
+}
+
+// backoff 返回第 n 次重试前的等待时间：baseDelay*2^n 的一半加随机抖动
+func (c *Client) backoff(n int) time.Duration {
+	d := c.baseDelay << n
+	if d <= 0 || d > maxDelay {
+		d = maxDelay
 	}
+	half := d / 2
+	return half + time.Duration(rand.Int64N(int64(half)+1))
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
-	if err != nil {
-		return err
+// sleepContext 等待 d 或直到 ctx 结束
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
 	}
-	req.Header.Set("Authorization", authToken)
-	req.Header.Set("Content-Type", "application/json")
+}
 
+// send 发送请求，非 200 响应转换为 *APIError，网络错误归类为 ErrTransient。
+// out 为 *bytes.Buffer 时写入原始响应体，否则按 JSON 解码。
+func (c *Client) send(ctx context.Context, op string, req *http.Request, out any) error {
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return err
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("%s失败: %w: %w", op, ErrTransient, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
-		return fmt.Errorf("获取 bucket 失败: %s", strings.TrimSpace(string(msg)))
+		return newAPIError(op, resp)
+	}
+	switch v := out.(type) {
+	case nil:
+		return nil
+	case *bytes.Buffer:
+		if _, err := v.ReadFrom(resp.Body); err != nil {
+			return fmt.Errorf("%s失败: %w: %w", op, ErrTransient, err)
+		}
+		return nil
+	default:
+		return json.NewDecoder(resp.Body).Decode(out)
 	}
+}
 
+// call 以 JSON 调用一次 apiURL 下的 B2 接口，不做重试
+func (c *Client) call(ctx context.Context, op, api string, payload, out any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	if err := c.authorize(ctx); err != nil {
+		return err
+	}
+	apiURL, _, token := c.session()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL+"/b2api/v2/"+api, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", token)
+	req.Header.Set("Content-Type", "application/json")
+	return c.send(ctx, op, req, out)
+}
+
+// callJSON 调用 B2 接口并自动重试
+func (c *Client) callJSON(ctx context.Context, op, api string, payload, out any) error {
+	return c.retry(ctx, func() error {
+		return c.call(ctx, op, api, payload, out)
+	})
+}
+
+func (c *Client) fetchBucketID(ctx context.Context) error {
+	c.mu.Lock()
+	accountID := c.accountID
+	c.mu.Unlock()
+
+	payload := map[string]string{
+		"accountId":  accountID,
+		"bucketName": c.settings.BucketName,
+	}
 	var result struct {
 		Buckets []struct {
 			BucketID   string `json:"bucketId"`
 			BucketName string `json:"bucketName"`
 		} `json:"buckets"`
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := c.callJSON(ctx, "获取存储桶", "b2_list_buckets", payload, &result); err != nil {
 		return err
 	}
 	for _, b := range result.Buckets {
@@ -276,49 +393,36 @@ func (c *Client) fetchBucketID(ctx context.Context) error {
 	return fmt.Errorf("未找到存储桶 %s", c.settings.BucketName)
 }
 
-func (c *Client) getUploadURL(ctx context.Context) (string, string, error) {
+// acquireUploadTarget 优先复用缓存的上传 URL，没有时调用 b2_get_upload_url
+func (c *Client) acquireUploadTarget(ctx context.Context) (uploadTarget, error) {
 	c.mu.Lock()
-	apiURL := c.apiURL
-	authToken := c.authToken
-	bucketID := c.settings.BucketID
-	c.mu.Unlock()
-
-	endpoint := fmt.Sprintf("%s/b2api/v2/b2_get_upload_url", apiURL)
-	payload := map[string]string{"bucketId": bucketID}
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return "", "", err
-	}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
-	if err != nil {
-		return "", "", err
-	}
-	req.Header.Set("Authorization", authToken)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return "", "", err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
-		return "", "", fmt.Errorf("获取上传 URL 失败: %s", strings.TrimSpace(string(msg)))
+	if n := len(c.uploadTargets); n > 0 {
+		target := c.uploadTargets[n-1]
+		c.uploadTargets = c.uploadTargets[:n-1]
+		c.mu.Unlock()
+		return target, nil
 	}
+	c.mu.Unlock()
 
+	payload := map[string]string{"bucketId": c.settings.BucketID}
 	var result struct {
 		UploadURL          string `json:"uploadUrl"`
 		AuthorizationToken string `json:"authorizationToken"`
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", "", err
+	if err := c.call(ctx, "获取上传地址", "b2_get_upload_url", payload, &result); err != nil {
+		return uploadTarget{}, err
 	}
 	if result.UploadURL == "" || result.AuthorizationToken == "" {
-		return "", "", errors.New("上传 URL 响应缺失字段")
+		return uploadTarget{}, errors.New("上传 URL 响应缺失字段")
 	}
-	return result.UploadURL, result.AuthorizationToken, nil
+	return uploadTarget{url: result.UploadURL, token: result.AuthorizationToken}, nil
+}
+
+// releaseUploadTarget 将上传成功的 URL 放回缓存供后续上传复用
+func (c *Client) releaseUploadTarget(target uploadTarget) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.uploadTargets = append(c.uploadTargets, target)
 }
 
 func sanitizeObjectKey(key string) string {
@@ -329,62 +433,100 @@ func sanitizeObjectKey(key string) string {
 	return safeKeyPattern.ReplaceAllString(trimmed, "_")
 }
 
-// Prepare 预先完成授权与存储桶校验，适用于初始化流程。
-func (c *Client) Prepare(ctx context.Context) error {
-	if err := c.ensureAuthorized(ctx); err != nil {
-		return err
-	}
-	if c.settings.BucketID == "" {
-		if err := c.fetchBucketID(ctx); err != nil {
-			return err
+// findFileVersions 调用 b2_list_file_versions 分页列出 key 的全部版本(含隐藏标记)，
+// 返回各版本的 fileId
+func (c *Client) findFileVersions(ctx context.Context, key string) ([]string, error) {
+	var fileIDs []string
+	startFileID := ""
+	for {
+		payload := map[string]any{
+			"bucketId":      c.settings.BucketID,
+			"startFileName": key,
+			"prefix":        key,
+			"maxFileCount":  listPageSize,
+		}
+		if startFileID != "" {
+			payload["startFileId"] = startFileID
+		}
+		var result struct {
+			Files []struct {
+				FileName string `json:"fileName"`
+				FileID   string `json:"fileId"`
+			} `json:"files"`
+			NextFileName *string `json:"nextFileName"`
+			NextFileID   *string `json:"nextFileId"`
+		}
+		if err := c.callJSON(ctx, "查询对象版本", "b2_list_file_versions", payload, &result); err != nil {
+			return nil, err
 		}
+		for _, f := range result.Files {
+			if f.FileName == key {
+				fileIDs = append(fileIDs, f.FileID)
+			}
+		}
+		// 结果按文件名排序，下一页已是其他对象时结束
+		if result.NextFileName == nil || *result.NextFileName != key || result.NextFileID == nil {
+			return fileIDs, nil
+		}
+		startFileID = *result.NextFileID
 	}
-	return nil
 }
 
-func (c *Client) findFileID(ctx context.Context, key string) (string, error) {
-	endpoint := fmt.Sprintf("%s/b2api/v2/b2_list_file_names", c.apiURL)
-	payload := map[string]any{
-		"bucketId":      c.settings.BucketID,
-		"startFileName": key,
-		"maxFileCount":  1,
-	}
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return "", err
+// List 列出存储桶中以 prefix 开头的对象，自动处理分页。
+func (c *Client) List(ctx context.Context, prefix string) ([]remote.ObjectInfo, error) {
+	if err := c.Prepare(ctx); err != nil {
+		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
-	if err != nil {
-		return "", err
+	var items []remote.ObjectInfo
+	startFileName := ""
+	for {
+		page, next, err := c.listFileNames(ctx, prefix, startFileName)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, page...)
+		if next == "" {
+			return items, nil
+		}
+		startFileName = next
 	}
-	req.Header.Set("Authorization", c.authToken)
-	req.Header.Set("Content-Type", "application/json")
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return "", err
+// listFileNames 调用 b2_list_file_names 获取一页结果，返回下一页的起始文件名
+func (c *Client) listFileNames(ctx context.Context, prefix string, startFileName string) ([]remote.ObjectInfo, string, error) {
+	payload := map[string]any{
+		"bucketId":     c.settings.BucketID,
+		"prefix":       prefix,
+		"maxFileCount": listPageSize,
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
-		return "", fmt.Errorf("查询对象失败: %s", strings.TrimSpace(string(msg)))
+	if startFileName != "" {
+		payload["startFileName"] = startFileName
 	}
 
 	var result struct {
 		Files []struct {
-			FileName string `json:"fileName"`
-			FileID   string `json:"fileId"`
+			FileName        string `json:"fileName"`
+			ContentLength   int64  `json:"contentLength"`
+			UploadTimestamp int64  `json:"uploadTimestamp"`
 		} `json:"files"`
+		NextFileName *string `json:"nextFileName"`
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", err
+	if err := c.callJSON(ctx, "列出对象", "b2_list_file_names", payload, &result); err != nil {
+		return nil, "", err
 	}
+
+	items := make([]remote.ObjectInfo, 0, len(result.Files))
 	for _, f := range result.Files {
-		if strings.EqualFold(f.FileName, key) {
-			return f.FileID, nil
-		}
+		items = append(items, remote.ObjectInfo{
+			Name:      f.FileName,
+			Size:      f.ContentLength,
+			UpdatedAt: time.UnixMilli(f.UploadTimestamp).UTC(),
+		})
+	}
+	next := ""
+	if result.NextFileName != nil {
+		next = *result.NextFileName
 	}
-	return "", errObjectNotFound
+	return items, next, nil
 }
@@ -0,0 +1,381 @@
+package b2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+	"github.com/codex-switch/codex-switch/internal/remote"
+)
+
+// fakeB2 是覆盖快照同步所需接口的最小 B2 替身
+type fakeB2 struct {
+	mu      sync.Mutex
+	url     string
+	token   int
+	objects map[string][]byte
+	// older 保存各对象被覆盖的历史版本，按上传顺序排列
+	older    map[string][][]byte
+	pageSize int
+	// failures 按接口名注入错误响应，依次消耗
+	failures map[string][]fakeFailure
+	calls    map[string]int
+}
+
+type fakeFailure struct {
+	status     int
+	code       string
+	retryAfter string
+}
+
+func newFakeB2(t *testing.T) *fakeB2 {
+	f := &fakeB2{
+		objects:  make(map[string][]byte),
+		older:    make(map[string][][]byte),
+		pageSize: 2,
+		failures: make(map[string][]fakeFailure),
+		calls:    make(map[string]int),
+	}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	f.url = srv.URL
+	return f
+}
+
+func (f *fakeB2) currentToken() string { return fmt.Sprintf("token-%d", f.token) }
+
+func (f *fakeB2) fail(api string, failures ...fakeFailure) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures[api] = append(f.failures[api], failures...)
+}
+
+func (f *fakeB2) count(api string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[api]
+}
+
+func writeError(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{"status": status, "code": code, "message": code})
+}
+
+func (f *fakeB2) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	api := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	if strings.HasPrefix(r.URL.Path, "/file/") {
+		api = "download"
+	}
+	f.calls[api]++
+	if queue := f.failures[api]; len(queue) > 0 {
+		failure := queue[0]
+		f.failures[api] = queue[1:]
+		if failure.retryAfter != "" {
+			w.Header().Set("Retry-After", failure.retryAfter)
+		}
+		writeError(w, failure.status, failure.code)
+		return
+	}
+
+	if api == "b2_authorize_account" {
+		if user, pass, _ := r.BasicAuth(); user != "kid" || pass != "secret" {
+			writeError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		f.token++
+		json.NewEncoder(w).Encode(map[string]string{
+			"accountId":          "acc",
+			"authorizationToken": f.currentToken(),
+			"apiUrl":             f.url,
+			"downloadUrl":        f.url,
+		})
+		return
+	}
+	if r.Header.Get("Authorization") != f.currentToken() && api != "upload" {
+		writeError(w, http.StatusUnauthorized, "expired_auth_token")
+		return
+	}
+
+	var req map[string]any
+	if r.Method == http.MethodPost && api != "upload" {
+		json.NewDecoder(r.Body).Decode(&req)
+	}
+	switch api {
+	case "b2_list_buckets":
+		json.NewEncoder(w).Encode(map[string]any{"buckets": []map[string]string{{"bucketId": "bid", "bucketName": "ckm"}}})
+	case "b2_get_upload_url":
+		json.NewEncoder(w).Encode(map[string]string{"uploadUrl": f.url + "/upload", "authorizationToken": "up-" + f.currentToken()})
+	case "upload":
+		if r.Header.Get("Authorization") != "up-"+f.currentToken() {
+			writeError(w, http.StatusUnauthorized, "expired_auth_token")
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		name := r.Header.Get("X-Bz-File-Name")
+		if current, ok := f.objects[name]; ok {
+			f.older[name] = append(f.older[name], current)
+		}
+		f.objects[name] = data
+		json.NewEncoder(w).Encode(map[string]string{"fileId": "id"})
+	case "download":
+		data, ok := f.objects[strings.TrimPrefix(r.URL.Path, "/file/ckm/")]
+		if !ok {
+			writeError(w, http.StatusNotFound, "not_found")
+			return
+		}
+		w.Write(data)
+	case "b2_list_file_names":
+		prefix, _ := req["prefix"].(string)
+		start, _ := req["startFileName"].(string)
+		names := make([]string, 0, len(f.objects))
+		for name := range f.objects {
+			if strings.HasPrefix(name, prefix) && name >= start {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		limit := f.pageSize
+		if n, ok := req["maxFileCount"].(float64); ok && int(n) < limit {
+			limit = int(n)
+		}
+		var next any
+		if len(names) > limit {
+			next = names[limit]
+			names = names[:limit]
+		}
+		files := make([]map[string]any, 0, len(names))
+		for _, name := range names {
+			files = append(files, map[string]any{"fileName": name, "fileId": "id-" + name, "contentLength": len(f.objects[name]), "uploadTimestamp": 1700000000000})
+		}
+		json.NewEncoder(w).Encode(map[string]any{"files": files, "nextFileName": next})
+	case "b2_list_file_versions":
+		prefix, _ := req["prefix"].(string)
+		start, _ := req["startFileName"].(string)
+		startID, _ := req["startFileId"].(string)
+		names := make([]string, 0, len(f.objects))
+		for name := range f.objects {
+			if strings.HasPrefix(name, prefix) && name >= start {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		// 同名对象的版本从新到旧排列
+		var files []map[string]any
+		for _, name := range names {
+			for i := len(f.older[name]); i >= 0; i-- {
+				files = append(files, map[string]any{"fileName": name, "fileId": fmt.Sprintf("id-%s-%d", name, i)})
+			}
+		}
+		for i, file := range files {
+			if file["fileId"] == startID {
+				files = files[i:]
+				break
+			}
+		}
+		var nextName, nextID any
+		if len(files) > f.pageSize {
+			nextName, nextID = files[f.pageSize]["fileName"], files[f.pageSize]["fileId"]
+			files = files[:f.pageSize]
+		}
+		json.NewEncoder(w).Encode(map[string]any{"files": files, "nextFileName": nextName, "nextFileId": nextID})
+	case "b2_delete_file_version":
+		name := req["fileName"].(string)
+		if _, ok := f.objects[name]; !ok {
+			writeError(w, http.StatusNotFound, "file_not_present")
+			return
+		}
+		older := f.older[name]
+		switch id := req["fileId"].(string); {
+		case id == fmt.Sprintf("id-%s-%d", name, len(older)):
+			// 删除最新版本后上一版本重新成为当前内容
+			if len(older) == 0 {
+				delete(f.objects, name)
+			} else {
+				f.objects[name] = older[len(older)-1]
+				f.older[name] = older[:len(older)-1]
+			}
+		default:
+			var index int
+			if _, err := fmt.Sscanf(strings.TrimPrefix(id, "id-"+name+"-"), "%d", &index); err != nil || index >= len(older) {
+				writeError(w, http.StatusNotFound, "file_not_present")
+				return
+			}
+			f.older[name] = append(older[:index:index], older[index+1:]...)
+		}
+		json.NewEncoder(w).Encode(map[string]string{})
+	default:
+		writeError(w, http.StatusBadRequest, "bad_request")
+	}
+}
+
+// newTestClient 创建指向替身的客户端，记录退避时长而不实际等待
+func newTestClient(t *testing.T, f *fakeB2, appKey string) (*Client, *[]time.Duration) {
+	client, err := NewClient(&config.RemoteSettings{KeyID: "kid", ApplicationKey: appKey, BucketName: "ckm", Endpoint: f.url + "/"})
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	var delays []time.Duration
+	client.sleep = func(_ context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+	return client, &delays
+}
+
+// TestUploadReusesURLAndRetries 验证上传 URL 复用、5xx 重试与 Retry-After
+func TestUploadReusesURLAndRetries(t *testing.T) {
+	f := newFakeB2(t)
+	client, delays := newTestClient(t, f, "secret")
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if err := client.Upload(ctx, fmt.Sprintf("p%d.json", i), []byte("data")); err != nil {
+			t.Fatalf("上传失败: %v", err)
+		}
+	}
+	if n := f.count("b2_get_upload_url"); n != 1 {
+		t.Fatalf("上传 URL 应复用, 实际获取 %d 次", n)
+	}
+
+	f.fail("upload", fakeFailure{status: http.StatusServiceUnavailable, code: "service_unavailable", retryAfter: "3"})
+	if err := client.Upload(ctx, "p3.json", []byte("data")); err != nil {
+		t.Fatalf("503 后应重试成功: %v", err)
+	}
+	if n := f.count("b2_get_upload_url"); n != 2 {
+		t.Fatalf("失败后应重新获取上传 URL, 实际获取 %d 次", n)
+	}
+	if len(*delays) != 1 || (*delays)[0] < 3*time.Second {
+		t.Fatalf("应遵循 Retry-After 等待 3s, got %v", *delays)
+	}
+}
+
+// TestReauthorizeOnExpiredToken 验证令牌过期时自动重新授权
+func TestReauthorizeOnExpiredToken(t *testing.T) {
+	f := newFakeB2(t)
+	client, _ := newTestClient(t, f, "secret")
+	ctx := context.Background()
+
+	if err := client.Upload(ctx, "default.json", []byte("v1")); err != nil {
+		t.Fatalf("上传失败: %v", err)
+	}
+	// 服务端轮换令牌，客户端缓存的令牌随之失效
+	f.mu.Lock()
+	f.token++
+	f.mu.Unlock()
+
+	data, err := client.Download(ctx, "default.json")
+	if err != nil || string(data) != "v1" {
+		t.Fatalf("令牌过期后应重新授权并下载成功: %q %v", data, err)
+	}
+	if n := f.count("b2_authorize_account"); n != 2 {
+		t.Fatalf("应重新授权一次, 实际授权 %d 次", n)
+	}
+}
+
+// TestTypedErrors 验证错误可区分鉴权、不存在、配额与临时失败
+func TestTypedErrors(t *testing.T) {
+	ctx := context.Background()
+
+	f := newFakeB2(t)
+	bad, _ := newTestClient(t, f, "wrong")
+	if err := bad.Prepare(ctx); !errors.Is(err, ErrAuth) {
+		t.Fatalf("错误凭据应返回 ErrAuth, got %v", err)
+	}
+
+	client, delays := newTestClient(t, f, "secret")
+	if _, err := client.Download(ctx, "missing.json"); !errors.Is(err, remote.ErrNotFound) {
+		t.Fatalf("不存在的对象应返回 ErrNotFound, got %v", err)
+	}
+
+	f.fail("upload", fakeFailure{status: http.StatusForbidden, code: "storage_cap_exceeded"})
+	if err := client.Upload(ctx, "default.json", []byte("data")); !errors.Is(err, ErrQuota) {
+		t.Fatalf("超出配额应返回 ErrQuota, got %v", err)
+	}
+
+	outage := make([]fakeFailure, client.maxRetries+1)
+	for i := range outage {
+		outage[i] = fakeFailure{status: http.StatusServiceUnavailable, code: "service_unavailable"}
+	}
+	f.fail("download", outage...)
+	*delays = nil
+	_, err := client.Download(ctx, "default.json")
+	var apiErr *APIError
+	if !errors.Is(err, ErrTransient) || !errors.As(err, &apiErr) || apiErr.Status != http.StatusServiceUnavailable {
+		t.Fatalf("持续 503 应返回 ErrTransient, got %v", err)
+	}
+	if len(*delays) != client.maxRetries {
+		t.Fatalf("应重试 %d 次, got %d", client.maxRetries, len(*delays))
+	}
+	for i := 1; i < len(*delays); i++ {
+		if (*delays)[i] < (*delays)[i-1]/2 {
+			t.Fatalf("退避时间应递增: %v", *delays)
+		}
+	}
+}
+
+// TestListPaginationAndDelete 验证分页列出与删除
+func TestListPaginationAndDelete(t *testing.T) {
+	f := newFakeB2(t)
+	client, _ := newTestClient(t, f, "secret")
+	ctx := context.Background()
+
+	for _, name := range []string{"a.json", "b.json", "c.json", "d.json", "e.json"} {
+		f.objects[name] = []byte(name)
+	}
+	items, err := client.List(ctx, "")
+	if err != nil {
+		t.Fatalf("列出失败: %v", err)
+	}
+	if len(items) != 5 || f.count("b2_list_file_names") != 3 {
+		t.Fatalf("应分 3 页列出 5 个对象, got %d 个对象 %d 页", len(items), f.count("b2_list_file_names"))
+	}
+
+	if err := client.Delete(ctx, "c.json"); err != nil {
+		t.Fatalf("删除失败: %v", err)
+	}
+	if err := client.Delete(ctx, "c.json"); err != nil {
+		t.Fatalf("删除不存在的对象应视为成功: %v", err)
+	}
+	if _, ok := f.objects["c.json"]; ok {
+		t.Fatalf("对象应已删除")
+	}
+}
+
+// TestDeleteRemovesAllVersions 验证删除会清除对象的全部版本，旧版本不会重新出现
+func TestDeleteRemovesAllVersions(t *testing.T) {
+	f := newFakeB2(t)
+	client, _ := newTestClient(t, f, "secret")
+	ctx := context.Background()
+
+	for _, data := range []string{"v1", "v2", "v3"} {
+		if err := client.Upload(ctx, "lease.k.json", []byte(data)); err != nil {
+			t.Fatalf("上传失败: %v", err)
+		}
+	}
+	if err := client.Upload(ctx, "lease.k.json.bak", []byte("other")); err != nil {
+		t.Fatalf("上传失败: %v", err)
+	}
+	if err := client.Delete(ctx, "lease.k.json"); err != nil {
+		t.Fatalf("删除失败: %v", err)
+	}
+	if _, err := client.Download(ctx, "lease.k.json"); !errors.Is(err, remote.ErrNotFound) {
+		t.Fatalf("删除后应返回 ErrNotFound，got=%v", err)
+	}
+	if data, err := client.Download(ctx, "lease.k.json.bak"); err != nil || string(data) != "other" {
+		t.Fatalf("不应删除同前缀的其他对象: %s err=%v", data, err)
+	}
+}
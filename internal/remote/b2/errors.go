@@ -0,0 +1,102 @@
+package b2
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/codex-switch/codex-switch/internal/remote"
+)
+
+// 错误分类，可通过 errors.Is 判断；未找到对象时匹配 remote.ErrNotFound
+var (
+	// ErrAuth 表示凭据无效、权限不足或令牌无法刷新
+	ErrAuth = errors.New("B2 鉴权失败，请检查 Key ID 与 Application Key")
+	// ErrQuota 表示账户的存储或事务配额已用尽
+	ErrQuota = errors.New("B2 配额已用尽")
+	// ErrTransient 表示服务暂时不可用或网络异常，重试次数耗尽后返回
+	ErrTransient = errors.New("B2 服务暂时不可用")
+)
+
+// APIError 描述 B2 接口返回的错误响应
+type APIError struct {
+	Op         string
+	Status     int
+	Code       string
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.Status)
+	}
+	if e.Code != "" {
+		return fmt.Sprintf("%s失败 (%d %s): %s", e.Op, e.Status, e.Code, msg)
+	}
+	return fmt.Sprintf("%s失败 (%d): %s", e.Op, e.Status, msg)
+}
+
+// Unwrap 按状态码与错误码返回错误分类
+func (e *APIError) Unwrap() error {
+	switch {
+	case e.Status == http.StatusForbidden && strings.HasSuffix(e.Code, "cap_exceeded"):
+		return ErrQuota
+	case e.Status == http.StatusUnauthorized || e.Status == http.StatusForbidden:
+		return ErrAuth
+	case e.Status == http.StatusNotFound || e.Code == "not_found" || e.Code == "no_such_file":
+		return remote.ErrNotFound
+	case e.Status == http.StatusRequestTimeout || e.Status == http.StatusTooManyRequests || e.Status >= 500:
+		return ErrTransient
+	}
+	return nil
+}
+
+// expiredToken 判断是否为授权令牌过期，此时应重新授权后重试
+func (e *APIError) expiredToken() bool {
+	return e.Status == http.StatusUnauthorized && (e.Code == "expired_auth_token" || e.Code == "bad_auth_token")
+}
+
+// newAPIError 读取错误响应体并解析 B2 的 {status, code, message} 结构
+func newAPIError(op string, resp *http.Response) *APIError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	apiErr := &APIError{
+		Op:         op,
+		Status:     resp.StatusCode,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+	}
+	var payload struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &payload); err == nil {
+		apiErr.Code = payload.Code
+		apiErr.Message = payload.Message
+	} else {
+		apiErr.Message = strings.TrimSpace(string(body))
+	}
+	return apiErr
+}
+
+// parseRetryAfter 解析 Retry-After 头，支持秒数与 HTTP 日期两种格式
+func parseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
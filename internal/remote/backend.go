@@ -0,0 +1,43 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// 支持的远程存储类型
+const (
+	ProviderB2     = "b2"
+	ProviderS3     = "s3"
+	ProviderWebDAV = "webdav"
+	ProviderDir    = "dir"
+	ProviderGit    = "git"
+)
+
+// ErrNotFound 表示远端对象不存在，各后端实现应使用 errors.Is 可识别的方式返回
+var ErrNotFound = errors.New("远程对象不存在")
+
+// ObjectInfo 描述远端对象的基础信息
+type ObjectInfo struct {
+	Name      string
+	Size      int64
+	UpdatedAt time.Time
+}
+
+// Backend 抽象远程快照存储，B2、S3 等实现均满足该接口。
+//
+// 对象名由调用方生成(如 <profile>.json)，实现负责在必要时做转义；
+// Delete 对不存在的对象应视为成功。
+type Backend interface {
+	// Prepare 完成鉴权与存储位置校验，适用于初始化流程
+	Prepare(ctx context.Context) error
+	// Upload 覆盖写入指定对象
+	Upload(ctx context.Context, name string, data []byte) error
+	// Download 读取指定对象，不存在时返回 ErrNotFound
+	Download(ctx context.Context, name string) ([]byte, error)
+	// Delete 删除指定对象
+	Delete(ctx context.Context, name string) error
+	// List 列出以 prefix 开头的对象
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+}
@@ -0,0 +1,210 @@
+package remote
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/codex-switch/codex-switch/internal/logging"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// sealMagic 为加密快照的信封头，紧随其后的一个字节表示信封版本。
+// 未加密的历史快照以 '{' 开头，可据此区分。
+var sealMagic = []byte("CKMSEAL")
+
+const (
+	sealVersionToken byte = 1
+	sealInfoToken         = "codex-switch snapshot v1"
+)
+
+// ErrMissingToken 表示需要 SyncToken 但配置中为空
+var ErrMissingToken = errors.New("缺少 SyncToken，无法加解密快照")
+
+// ErrDecrypt 表示解密失败，通常是 SyncToken 与推送端不一致
+var ErrDecrypt = errors.New("解密快照失败: SyncToken 不匹配或数据已损坏")
+
+// Codec 负责快照与远端存储字节之间的转换，包括签名、加密、解密与验签。
+type Codec struct {
+	// SyncToken 用于派生 AES-256-GCM 密钥与 HMAC 签名密钥
+	SyncToken string
+	// Plaintext 为 true 时上传明文 JSON，仅用于兼容旧版本客户端
+	Plaintext bool
+	// SigningKey 非空时使用 ed25519 签名代替 HMAC
+	SigningKey ed25519.PrivateKey
+	// TrustedKeys 为接受的 ed25519 签名公钥
+	TrustedKeys []ed25519.PublicKey
+	// SkipVerify 为 true 时签名校验失败仅记录警告
+	SkipVerify bool
+	// Recipients 非空时按接收者公钥加密快照，代替 SyncToken 对称加密
+	Recipients []*ecdh.PublicKey
+	// Identity 为本机 X25519 身份私钥，用于解密按接收者加密的快照
+	Identity *ecdh.PrivateKey
+}
+
+// Encode 为快照签名并序列化，启用加密时封装为带版本头的密文信封。
+//
+// 配置了接收者时按接收者公钥加密；此时各接收者不共享 SyncToken，
+// 必须使用 ed25519 私钥签名，未配置时返回 ErrSigningKeyRequired。
+func (c Codec) Encode(snap *Snapshot) ([]byte, error) {
+	if snap == nil {
+		return nil, errors.New("快照为空")
+	}
+	if len(c.Recipients) > 0 && !c.Plaintext {
+		if c.SigningKey == nil {
+			return nil, ErrSigningKeyRequired
+		}
+		snap.Signature = ""
+		if err := c.sign(snap); err != nil {
+			return nil, err
+		}
+		data, err := snap.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		return sealForRecipients(data, c.Recipients)
+	}
+	if err := c.sign(snap); err != nil {
+		return nil, err
+	}
+	data, err := snap.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	if c.Plaintext {
+		return data, nil
+	}
+	return sealWithToken(data, c.SyncToken)
+}
+
+// Decode 解析远端数据并校验签名，自动识别加密信封与历史明文快照。
+func (c Codec) Decode(data []byte) (*Snapshot, error) {
+	var envelope byte
+	if IsSealed(data) {
+		envelope = data[len(sealMagic)]
+		var plain []byte
+		var err error
+		if envelope == sealVersionRecipients {
+			plain, err = openForIdentity(data, c.Identity)
+		} else {
+			plain, err = openWithToken(data, c.SyncToken)
+		}
+		if err != nil {
+			return nil, err
+		}
+		data = plain
+	}
+	snap, err := UnmarshalSnapshot(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkSignature(snap, envelope); err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// SaveSnapshotFile 为快照签名后写入本地文件
+func (c Codec) SaveSnapshotFile(path string, snap *Snapshot) error {
+	if snap == nil {
+		return errors.New("快照对象为空")
+	}
+	if err := c.sign(snap); err != nil {
+		return err
+	}
+	return SaveSnapshotFile(path, snap)
+}
+
+// LoadSnapshotFile 读取本地快照文件并校验签名
+func (c Codec) LoadSnapshotFile(path string) (*Snapshot, error) {
+	snap, err := LoadSnapshotFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkSignature(snap, 0); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return snap, nil
+}
+
+// checkSignature 校验签名，SkipVerify 时仅记录警告
+func (c Codec) checkSignature(snap *Snapshot, envelope byte) error {
+	err := c.verify(snap, envelope)
+	if err != nil && c.SkipVerify {
+		logging.Warnf("已跳过快照签名校验: %v", err)
+		return nil
+	}
+	return err
+}
+
+// IsSealed 判断数据是否为加密信封
+func IsSealed(data []byte) bool {
+	return len(data) > len(sealMagic) && bytes.HasPrefix(data, sealMagic)
+}
+
+// sealWithToken 使用 SyncToken 派生的密钥加密数据，信封头作为附加认证数据
+func sealWithToken(plain []byte, token string) ([]byte, error) {
+	aead, err := tokenAEAD(token)
+	if err != nil {
+		return nil, err
+	}
+	header := append(append([]byte(nil), sealMagic...), sealVersionToken)
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out := append(header, nonce...)
+	return aead.Seal(out, nonce, plain, header), nil
+}
+
+// openWithToken 解密 sealWithToken 生成的信封
+func openWithToken(data []byte, token string) ([]byte, error) {
+	headerLen := len(sealMagic) + 1
+	if len(data) < headerLen {
+		return nil, errors.New("加密快照格式不完整")
+	}
+	if version := data[len(sealMagic)]; version != sealVersionToken {
+		return nil, fmt.Errorf("不支持的快照信封版本: %d", version)
+	}
+	aead, err := tokenAEAD(token)
+	if err != nil {
+		return nil, err
+	}
+	header := data[:headerLen]
+	rest := data[headerLen:]
+	if len(rest) < aead.NonceSize() {
+		return nil, errors.New("加密快照格式不完整")
+	}
+	nonce, ciphertext := rest[:aead.NonceSize()], rest[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, ciphertext, header)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plain, nil
+}
+
+func tokenAEAD(token string) (cipher.AEAD, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil, ErrMissingToken
+	}
+	return newGCM(deriveKey([]byte(token), sealInfoToken))
+}
+
+// deriveKey 以 HKDF-SHA256 (RFC 5869，空 salt) 派生 32 字节密钥
+func deriveKey(secret []byte, info string) []byte {
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, nil, []byte(info)), key); err != nil {
+		// 32 字节远小于 HKDF-SHA256 的输出上限，不会出错
+		panic(err)
+	}
+	return key
+}
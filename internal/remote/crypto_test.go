@@ -0,0 +1,79 @@
+package remote
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+)
+
+func testSnapshot() *Snapshot {
+	return BuildSnapshot(&config.Config{
+		ActiveKeyID: "1",
+		Keys:        []config.APIKey{{ID: "1", Name: "main", APIKey: "sk-secret-value"}},
+	})
+}
+
+// TestCodecRoundTrip 验证加密快照可以用相同 token 解密且不含明文密钥
+func TestCodecRoundTrip(t *testing.T) {
+	codec := Codec{SyncToken: "token-for-tests-0123456789"}
+	data, err := codec.Encode(testSnapshot())
+	if err != nil {
+		t.Fatalf("加密失败: %v", err)
+	}
+	if !IsSealed(data) || bytes.Contains(data, []byte("sk-secret-value")) {
+		t.Fatalf("快照应被加密")
+	}
+
+	snap, err := codec.Decode(data)
+	if err != nil {
+		t.Fatalf("解密失败: %v", err)
+	}
+	if len(snap.Keys) != 1 || snap.Keys[0].APIKey != "sk-secret-value" {
+		t.Fatalf("解密结果不正确: %#v", snap)
+	}
+
+	if _, err := (Codec{SyncToken: "another-token-0123456789"}).Decode(data); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("错误 token 应返回 ErrDecrypt, got %v", err)
+	}
+	if _, err := (Codec{}).Encode(testSnapshot()); !errors.Is(err, ErrMissingToken) {
+		t.Fatalf("缺少 token 应报错, got %v", err)
+	}
+}
+
+// TestCodecLegacyPlaintext 验证明文快照需带签名，未签名的历史快照仅在跳过校验时可读取
+func TestCodecLegacyPlaintext(t *testing.T) {
+	codec := Codec{SyncToken: "token-for-tests-0123456789"}
+	plain, err := Codec{SyncToken: codec.SyncToken, Plaintext: true}.Encode(testSnapshot())
+	if err != nil {
+		t.Fatalf("编码失败: %v", err)
+	}
+	if IsSealed(plain) {
+		t.Fatalf("明文模式不应加密")
+	}
+	snap, err := codec.Decode(plain)
+	if err != nil || len(snap.Keys) != 1 {
+		t.Fatalf("读取明文快照失败: %v", err)
+	}
+
+	legacy, _ := testSnapshot().Marshal()
+	if _, err := codec.Decode(legacy); !errors.Is(err, ErrUnsigned) {
+		t.Fatalf("未签名的明文快照应被拒绝, got %v", err)
+	}
+	codec.SkipVerify = true
+	if snap, err := codec.Decode(legacy); err != nil || len(snap.Keys) != 1 {
+		t.Fatalf("跳过校验时应可读取历史快照: %v", err)
+	}
+}
+
+// TestDeriveKeyVector 使用 RFC 5869 测试用例 3 (空 salt、空 info) 验证密钥派生结果不变，
+// 保证已有快照仍可解密
+func TestDeriveKeyVector(t *testing.T) {
+	got := hex.EncodeToString(deriveKey(bytes.Repeat([]byte{0x0b}, 22), ""))
+	want := "8da4e775a563c18f715f802a063c5a31b8a11f5c5ee1879ec3454e5f3c738d2d"
+	if got != want {
+		t.Fatalf("HKDF 派生结果不正确: %s", got)
+	}
+}
@@ -0,0 +1,322 @@
+package dir
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+	"github.com/codex-switch/codex-switch/internal/logging"
+	"github.com/codex-switch/codex-switch/internal/remote"
+)
+
+// frameMagic 为每个文件的首行标识，格式为 "CKMDIR1 <sha256> <长度>\n"，
+// 读取时据此校验文件是否被完整写入或同步。
+const frameMagic = "CKMDIR1"
+
+// tempMarker 出现在写入中的临时文件名中，List 会忽略这些文件
+const tempMarker = ".tmp-"
+
+// ErrIncomplete 表示文件内容与校验头不一致，通常是同步工具仍在传输中
+var ErrIncomplete = errors.New("快照文件不完整，可能仍在同步中或写入被中断")
+
+// Backend 将快照保存在本地目录中，适用于 Syncthing 同步目录或 NAS 挂载点。
+type Backend struct {
+	root string
+}
+
+var (
+	_ remote.Backend      = (*Backend)(nil)
+	_ remote.LeaseBackend = (*Backend)(nil)
+)
+
+// NewBackend 根据远程配置创建目录后端。
+func NewBackend(settings *config.RemoteSettings) (*Backend, error) {
+	if settings == nil {
+		return nil, errors.New("远程配置为空")
+	}
+	root := strings.TrimSpace(settings.Directory)
+	if root == "" {
+		return nil, errors.New("缺少快照目录")
+	}
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("解析快照目录失败: %w", err)
+	}
+	return &Backend{root: abs}, nil
+}
+
+// Prepare 确保目录存在且可写，并提示遗留的未完成写入。
+func (b *Backend) Prepare(_ context.Context) error {
+	if err := os.MkdirAll(b.root, 0o700); err != nil {
+		return fmt.Errorf("创建快照目录失败: %w", err)
+	}
+	probe, err := os.CreateTemp(b.root, "."+"probe"+tempMarker+"*")
+	if err != nil {
+		return fmt.Errorf("快照目录不可写: %w", err)
+	}
+	probe.Close()
+	_ = os.Remove(probe.Name())
+
+	entries, err := os.ReadDir(b.root)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if strings.Contains(e.Name(), tempMarker) {
+			logging.Warnf("快照目录存在未完成的写入: %s", filepath.Join(b.root, e.Name()))
+		}
+	}
+	return nil
+}
+
+// Upload 先写入同目录临时文件并 fsync，再原子重命名为目标文件。
+func (b *Backend) Upload(_ context.Context, name string, data []byte) error {
+	if len(data) == 0 {
+		return errors.New("上传数据为空")
+	}
+	target, err := b.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(b.root, 0o700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(b.root, "."+name+tempMarker+"*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	cleanup := func() { _ = os.Remove(tmpName) }
+
+	if _, err := tmp.Write(frame(data)); err != nil {
+		tmp.Close()
+		cleanup()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		cleanup()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		cleanup()
+		return err
+	}
+	if err := os.Chmod(tmpName, 0o600); err != nil {
+		cleanup()
+		return err
+	}
+	if err := os.Rename(tmpName, target); err != nil {
+		cleanup()
+		return err
+	}
+	return syncDir(b.root)
+}
+
+// Create 写入临时文件后通过硬链接创建目标文件，目标已存在时链接失败，保证只有一方成功。
+func (b *Backend) Create(_ context.Context, name string, data []byte) error {
+	if len(data) == 0 {
+		return errors.New("上传数据为空")
+	}
+	target, err := b.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(b.root, 0o700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(b.root, "."+name+tempMarker+"*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	if _, err := tmp.Write(frame(data)); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpName, 0o600); err != nil {
+		return err
+	}
+	if err := os.Link(tmpName, target); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("%w: %s", remote.ErrObjectExists, name)
+		}
+		return err
+	}
+	return syncDir(b.root)
+}
+
+// Download 读取文件并校验长度与 SHA256。
+func (b *Backend) Download(ctx context.Context, name string) ([]byte, error) {
+	data, _, err := b.DownloadTagged(ctx, name)
+	return data, err
+}
+
+// DownloadTagged 读取文件并返回文件内容(含校验头)的 SHA256 作为版本标识。
+func (b *Backend) DownloadTagged(_ context.Context, name string) ([]byte, string, error) {
+	target, err := b.path(name)
+	if err != nil {
+		return nil, "", err
+	}
+	raw, err := os.ReadFile(target)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, "", fmt.Errorf("%w: %s", remote.ErrNotFound, name)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	data, err := unframe(raw)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", target, err)
+	}
+	return data, fileTag(raw), nil
+}
+
+// Delete 删除文件，不存在时视为成功。
+func (b *Backend) Delete(_ context.Context, name string) error {
+	target, err := b.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return syncDir(b.root)
+}
+
+// DeleteIf 先将文件重命名为临时文件(重命名是原子的，并发时只有一方能取走)，
+// 再比较版本标识；不一致时将文件链接回原名并返回 ErrConditionFailed。
+// 若此期间已有其他客户端创建了新文件，链接失败，保留较新的文件。
+func (b *Backend) DeleteIf(_ context.Context, name string, tag string) error {
+	target, err := b.path(name)
+	if err != nil {
+		return err
+	}
+	taken := filepath.Join(b.root, "."+name+tempMarker+"delete-"+strconv.Itoa(os.Getpid()))
+	if err := os.Rename(target, taken); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	defer os.Remove(taken)
+
+	raw, err := os.ReadFile(taken)
+	if err != nil {
+		return err
+	}
+	if fileTag(raw) != tag {
+		if err := os.Link(taken, target); err != nil && !errors.Is(err, os.ErrExist) {
+			return err
+		}
+		return fmt.Errorf("%w: %s", remote.ErrConditionFailed, name)
+	}
+	return syncDir(b.root)
+}
+
+// List 列出目录中以 prefix 开头的文件，忽略临时文件与子目录。
+func (b *Backend) List(_ context.Context, prefix string) ([]remote.ObjectInfo, error) {
+	entries, err := os.ReadDir(b.root)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var items []remote.ObjectInfo
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || strings.HasPrefix(name, ".") || strings.Contains(name, tempMarker) || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		items = append(items, remote.ObjectInfo{Name: name, Size: info.Size(), UpdatedAt: info.ModTime().UTC()})
+	}
+	return items, nil
+}
+
+// path 校验对象名并返回完整路径，禁止跨目录访问
+func (b *Backend) path(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("非法的对象名: %q", name)
+	}
+	return filepath.Join(b.root, name), nil
+}
+
+// frame 为数据添加校验头
+func frame(data []byte) []byte {
+	sum := sha256.Sum256(data)
+	header := fmt.Sprintf("%s %s %d\n", frameMagic, hex.EncodeToString(sum[:]), len(data))
+	return append([]byte(header), data...)
+}
+
+// unframe 校验并去除校验头
+func unframe(raw []byte) ([]byte, error) {
+	reader := bufio.NewReader(bytes.NewReader(raw))
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, ErrIncomplete
+	}
+	fields := strings.Fields(line)
+	if len(fields) != 3 || fields[0] != frameMagic {
+		return nil, errors.New("不是 ckm 目录后端写入的快照文件")
+	}
+	size, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return nil, errors.New("快照文件校验头损坏")
+	}
+	data := raw[len(line):]
+	if len(data) != size {
+		return nil, ErrIncomplete
+	}
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != fields[1] {
+		return nil, ErrIncomplete
+	}
+	return data, nil
+}
+
+// fileTag 返回文件原始内容的 SHA256，作为 DeleteIf 使用的版本标识
+func fileTag(raw []byte) string {
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// syncDir 对目录执行 fsync，确保重命名与删除操作落盘
+func syncDir(path string) error {
+	d, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil {
+		// 部分平台(如 Windows)不支持对目录 fsync，记录后忽略
+		logging.Debugf("目录 fsync 失败: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package dir
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+	"github.com/codex-switch/codex-switch/internal/remote"
+)
+
+func TestBackendRoundTrip(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "sync")
+	backend, err := NewBackend(&config.RemoteSettings{Directory: root})
+	if err != nil {
+		t.Fatalf("创建后端失败: %v", err)
+	}
+	ctx := context.Background()
+	if err := backend.Prepare(ctx); err != nil {
+		t.Fatalf("Prepare 失败: %v", err)
+	}
+
+	if _, err := backend.Download(ctx, "default.json"); !errors.Is(err, remote.ErrNotFound) {
+		t.Fatalf("期望 ErrNotFound，实际 %v", err)
+	}
+
+	payload := []byte(`{"keys":[]}`)
+	if err := backend.Upload(ctx, "default.json", payload); err != nil {
+		t.Fatalf("上传失败: %v", err)
+	}
+	got, err := backend.Download(ctx, "default.json")
+	if err != nil {
+		t.Fatalf("下载失败: %v", err)
+	}
+	if string(got) != string(payload) {
+		t.Fatalf("内容不一致: %s", got)
+	}
+
+	// 遗留的临时文件不应出现在列表中
+	if err := os.WriteFile(filepath.Join(root, ".work.json"+tempMarker+"1"), []byte("x"), 0o600); err != nil {
+		t.Fatalf("写入临时文件失败: %v", err)
+	}
+	items, err := backend.List(ctx, "")
+	if err != nil {
+		t.Fatalf("列出失败: %v", err)
+	}
+	if len(items) != 1 || items[0].Name != "default.json" {
+		t.Fatalf("列表结果异常: %+v", items)
+	}
+
+	if err := backend.Delete(ctx, "default.json"); err != nil {
+		t.Fatalf("删除失败: %v", err)
+	}
+	if err := backend.Delete(ctx, "default.json"); err != nil {
+		t.Fatalf("重复删除应视为成功: %v", err)
+	}
+	if err := backend.Upload(ctx, "../escape.json", payload); err == nil {
+		t.Fatalf("期望拒绝跨目录对象名")
+	}
+}
+
+func TestBackendDetectsPartialWrite(t *testing.T) {
+	root := t.TempDir()
+	backend, err := NewBackend(&config.RemoteSettings{Directory: root})
+	if err != nil {
+		t.Fatalf("创建后端失败: %v", err)
+	}
+	ctx := context.Background()
+	if err := backend.Upload(ctx, "default.json", []byte(`{"keys":[{"name":"main"}]}`)); err != nil {
+		t.Fatalf("上传失败: %v", err)
+	}
+
+	target := filepath.Join(root, "default.json")
+	raw, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatalf("读取文件失败: %v", err)
+	}
+	if err := os.WriteFile(target, raw[:len(raw)-5], 0o600); err != nil {
+		t.Fatalf("截断文件失败: %v", err)
+	}
+	if _, err := backend.Download(ctx, "default.json"); !errors.Is(err, ErrIncomplete) {
+		t.Fatalf("期望 ErrIncomplete，实际 %v", err)
+	}
+
+	// 长度一致但内容被篡改同样视为不完整
+	tampered := append([]byte{}, raw...)
+	tampered[len(tampered)-2] = 'X'
+	if err := os.WriteFile(target, tampered, 0o600); err != nil {
+		t.Fatalf("写入文件失败: %v", err)
+	}
+	if _, err := backend.Download(ctx, "default.json"); !errors.Is(err, ErrIncomplete) {
+		t.Fatalf("期望 ErrIncomplete，实际 %v", err)
+	}
+}
+
+// TestBackendCreate 验证条件创建在文件已存在时返回 ErrObjectExists 且不覆盖原内容
+func TestBackendCreate(t *testing.T) {
+	backend, err := NewBackend(&config.RemoteSettings{Directory: t.TempDir()})
+	if err != nil {
+		t.Fatalf("创建后端失败: %v", err)
+	}
+	ctx := context.Background()
+	if err := backend.Create(ctx, "lease.1.json", []byte("first")); err != nil {
+		t.Fatalf("创建失败: %v", err)
+	}
+	if err := backend.Create(ctx, "lease.1.json", []byte("second")); !errors.Is(err, remote.ErrObjectExists) {
+		t.Fatalf("期望 ErrObjectExists，实际 %v", err)
+	}
+	got, err := backend.Download(ctx, "lease.1.json")
+	if err != nil || string(got) != "first" {
+		t.Fatalf("原内容不应被覆盖: %s err=%v", got, err)
+	}
+	items, _ := backend.List(ctx, "")
+	if len(items) != 1 {
+		t.Fatalf("不应残留临时文件: %+v", items)
+	}
+}
+
+// TestBackendDeleteIf 验证条件删除在文件已被改写时返回 ErrConditionFailed 且保留新内容
+func TestBackendDeleteIf(t *testing.T) {
+	backend, err := NewBackend(&config.RemoteSettings{Directory: t.TempDir()})
+	if err != nil {
+		t.Fatalf("创建后端失败: %v", err)
+	}
+	ctx := context.Background()
+	if err := backend.Upload(ctx, "lease.1.json", []byte("first")); err != nil {
+		t.Fatalf("上传失败: %v", err)
+	}
+	_, stale, err := backend.DownloadTagged(ctx, "lease.1.json")
+	if err != nil {
+		t.Fatalf("下载失败: %v", err)
+	}
+	if err := backend.Upload(ctx, "lease.1.json", []byte("second")); err != nil {
+		t.Fatalf("上传失败: %v", err)
+	}
+	if err := backend.DeleteIf(ctx, "lease.1.json", stale); !errors.Is(err, remote.ErrConditionFailed) {
+		t.Fatalf("期望 ErrConditionFailed，实际 %v", err)
+	}
+	got, tag, err := backend.DownloadTagged(ctx, "lease.1.json")
+	if err != nil || string(got) != "second" {
+		t.Fatalf("新内容不应被删除: %s err=%v", got, err)
+	}
+	if err := backend.DeleteIf(ctx, "lease.1.json", tag); err != nil {
+		t.Fatalf("条件删除失败: %v", err)
+	}
+	if _, err := backend.Download(ctx, "lease.1.json"); !errors.Is(err, remote.ErrNotFound) {
+		t.Fatalf("删除后文件应不存在，got=%v", err)
+	}
+	items, _ := backend.List(ctx, "")
+	if len(items) != 0 {
+		t.Fatalf("不应残留临时文件: %+v", items)
+	}
+}
@@ -0,0 +1,100 @@
+package remote
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+	"github.com/codex-switch/codex-switch/internal/logging"
+)
+
+// Syncable 判断 Key 是否参与远程同步。
+//
+// LocalOnly 的 Key 始终不同步；带有 ExcludeTags 中任一标签的 Key 不同步；
+// IncludeTags 非空时仅同步带有其中任一标签的 Key。标签比较忽略大小写。
+func Syncable(key config.APIKey, settings *config.RemoteSettings) bool {
+	if key.LocalOnly {
+		return false
+	}
+	if settings == nil {
+		return true
+	}
+	if hasAnyTag(key.Tags, settings.ExcludeTags) {
+		return false
+	}
+	return len(settings.IncludeTags) == 0 || hasAnyTag(key.Tags, settings.IncludeTags)
+}
+
+// SyncableKeys 返回参与同步的 Key 副本
+func SyncableKeys(keys []config.APIKey, settings *config.RemoteSettings) []config.APIKey {
+	result := make([]config.APIKey, 0, len(keys))
+	for _, k := range keys {
+		if Syncable(k, settings) {
+			result = append(result, k)
+		}
+	}
+	return result
+}
+
+// KeepLocalKeys 将本地不参与同步的 Key 追加到同步得到的 Key 列表中，
+// 避免拉取或合并时因远端快照缺少这些 Key 而将其删除。
+//
+// 同名时保留本地 Key；synced 中的 ID 与保留的 Key 冲突时重新编号；
+// 保留的 Key 处于激活状态时继续保持激活。
+func KeepLocalKeys(local, synced []config.APIKey, settings *config.RemoteSettings) []config.APIKey {
+	var kept []config.APIKey
+	for _, k := range local {
+		if !Syncable(k, settings) {
+			kept = append(kept, k)
+		}
+	}
+	if len(kept) == 0 {
+		return synced
+	}
+
+	keptNames := make(map[string]bool, len(kept))
+	keptIDs := make(map[string]bool, len(kept))
+	keptActive := false
+	maxID := 0
+	for _, k := range kept {
+		keptNames[nameKey(k.Name)] = true
+		keptIDs[k.ID] = true
+		keptActive = keptActive || k.Active
+	}
+	for _, keys := range [][]config.APIKey{local, synced} {
+		for _, k := range keys {
+			if n, err := strconv.Atoi(k.ID); err == nil && n > maxID {
+				maxID = n
+			}
+		}
+	}
+
+	result := make([]config.APIKey, 0, len(synced)+len(kept))
+	for _, k := range synced {
+		if keptNames[nameKey(k.Name)] {
+			logging.Warnf("远端 Key %s 与仅本地的 Key 同名，已保留本地版本", k.Name)
+			continue
+		}
+		if k.ID == "" || keptIDs[k.ID] {
+			maxID++
+			k.ID = strconv.Itoa(maxID)
+		}
+		if keptActive {
+			k.Active = false
+		}
+		result = append(result, k)
+	}
+	return append(result, kept...)
+}
+
+// hasAnyTag 判断 tags 是否包含 wanted 中的任一标签
+func hasAnyTag(tags, wanted []string) bool {
+	for _, t := range tags {
+		for _, w := range wanted {
+			if strings.EqualFold(strings.TrimSpace(t), strings.TrimSpace(w)) {
+				return true
+			}
+		}
+	}
+	return false
+}
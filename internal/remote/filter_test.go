@@ -0,0 +1,60 @@
+package remote
+
+import (
+	"testing"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+)
+
+// TestBuildSnapshotFiltersKeys 验证快照不包含仅本地与被标签规则排除的 Key
+func TestBuildSnapshotFiltersKeys(t *testing.T) {
+	cfg := &config.Config{
+		ActiveKeyID: "2",
+		Keys: []config.APIKey{
+			{ID: "1", Name: "team", Tags: []string{"Work"}},
+			{ID: "2", Name: "sandbox", Tags: []string{"work"}, LocalOnly: true, Active: true},
+			{ID: "3", Name: "personal", Tags: []string{"work", "personal"}},
+			{ID: "4", Name: "misc"},
+		},
+		Remote: &config.RemoteSettings{IncludeTags: []string{"work"}, ExcludeTags: []string{"personal"}},
+	}
+
+	snap := BuildSnapshot(cfg)
+	if len(snap.Keys) != 1 || snap.Keys[0].Name != "team" {
+		t.Fatalf("快照应仅包含 team, got %+v", snap.Keys)
+	}
+	if snap.ActiveKeyID != "" {
+		t.Fatalf("激活的 Key 未同步时不应写入 ActiveKeyID, got %s", snap.ActiveKeyID)
+	}
+}
+
+// TestKeepLocalKeys 验证拉取时保留仅本地的 Key 及其激活状态
+func TestKeepLocalKeys(t *testing.T) {
+	local := []config.APIKey{
+		{ID: "1", Name: "team"},
+		{ID: "2", Name: "sandbox", LocalOnly: true, Active: true},
+	}
+	synced := []config.APIKey{
+		{ID: "1", Name: "team", Active: true},
+		{ID: "2", Name: "relay"},
+		{ID: "3", Name: "Sandbox"},
+	}
+
+	keys := KeepLocalKeys(local, synced, nil)
+	if len(keys) != 3 {
+		t.Fatalf("应得到 team/relay/sandbox 三个 Key, got %+v", keys)
+	}
+	ids := make(map[string]string)
+	for _, k := range keys {
+		if prev, ok := ids[k.ID]; ok {
+			t.Fatalf("ID %s 重复: %s 与 %s", k.ID, prev, k.Name)
+		}
+		ids[k.ID] = k.Name
+		if k.Active != (k.Name == "sandbox") {
+			t.Fatalf("应保持本地激活的 sandbox, got %+v", k)
+		}
+	}
+	if ids["2"] != "sandbox" || !keys[2].LocalOnly {
+		t.Fatalf("仅本地的 Key 应保持原 ID 与标记, got %+v", keys)
+	}
+}
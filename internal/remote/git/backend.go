@@ -0,0 +1,244 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+	"github.com/codex-switch/codex-switch/internal/logging"
+	"github.com/codex-switch/codex-switch/internal/remote"
+)
+
+// DefaultBranch 为未指定分支时使用的同步分支
+const DefaultBranch = "ckm"
+
+// ErrPlaintext 表示拒绝将未加密的快照提交到 Git 仓库
+var ErrPlaintext = errors.New("拒绝向 Git 仓库提交未加密的快照，如确需明文请使用 --allow-plaintext")
+
+// Backend 将快照提交到 Git 仓库的专用分支，借助 Git 获得历史、审阅与追溯能力。
+//
+// 后端通过调用系统中的 git 命令工作：workDir 为本地工作副本，每次操作前
+// 会从远端拉取并重置到最新分支，写操作完成后提交并推送。
+type Backend struct {
+	repo           string
+	branch         string
+	workDir        string
+	allowPlaintext bool
+}
+
+var _ remote.Backend = (*Backend)(nil)
+
+// NewBackend 根据远程配置创建 Git 后端，workDir 为本地工作副本所在目录。
+func NewBackend(settings *config.RemoteSettings, workDir string) (*Backend, error) {
+	if settings == nil {
+		return nil, errors.New("远程配置为空")
+	}
+	repo := strings.TrimSpace(settings.Repo)
+	if repo == "" {
+		return nil, errors.New("缺少 Git 仓库地址")
+	}
+	if strings.TrimSpace(workDir) == "" {
+		return nil, errors.New("缺少 Git 工作目录")
+	}
+	branch := strings.TrimSpace(settings.Branch)
+	if branch == "" {
+		branch = DefaultBranch
+	}
+	return &Backend{
+		repo:           repo,
+		branch:         branch,
+		workDir:        workDir,
+		allowPlaintext: settings.AllowPlaintext,
+	}, nil
+}
+
+// Prepare 检查 git 命令是否可用，并确认仓库可以访问。
+func (b *Backend) Prepare(ctx context.Context) error {
+	if _, err := exec.LookPath("git"); err != nil {
+		return errors.New("未找到 git 命令，请先安装 Git")
+	}
+	if err := os.MkdirAll(b.workDir, 0o700); err != nil {
+		return fmt.Errorf("创建 Git 工作目录失败: %w", err)
+	}
+	if _, err := b.git(ctx, "ls-remote", "--heads", b.repo); err != nil {
+		return fmt.Errorf("无法访问 Git 仓库 %s: %w", b.repo, err)
+	}
+	return b.sync(ctx)
+}
+
+// Upload 写入快照文件并提交推送，内容未变化时不产生新提交。
+func (b *Backend) Upload(ctx context.Context, name string, data []byte) error {
+	if len(data) == 0 {
+		return errors.New("上传数据为空")
+	}
+	if !b.allowPlaintext && !remote.IsSealed(data) {
+		return ErrPlaintext
+	}
+	target, err := b.path(name)
+	if err != nil {
+		return err
+	}
+	if err := b.sync(ctx); err != nil {
+		return err
+	}
+	if err := os.WriteFile(target, data, 0o600); err != nil {
+		return err
+	}
+	if _, err := b.git(ctx, "add", "--", name); err != nil {
+		return err
+	}
+	return b.commitAndPush(ctx, fmt.Sprintf("ckm: 更新 %s", name))
+}
+
+// Download 拉取最新分支并读取快照文件。
+func (b *Backend) Download(ctx context.Context, name string) ([]byte, error) {
+	target, err := b.path(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.sync(ctx); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(target)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", remote.ErrNotFound, name)
+	}
+	return data, err
+}
+
+// Delete 删除快照文件并提交推送，不存在时视为成功。
+func (b *Backend) Delete(ctx context.Context, name string) error {
+	target, err := b.path(name)
+	if err != nil {
+		return err
+	}
+	if err := b.sync(ctx); err != nil {
+		return err
+	}
+	if _, err := os.Stat(target); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if _, err := b.git(ctx, "rm", "-q", "--", name); err != nil {
+		return err
+	}
+	return b.commitAndPush(ctx, fmt.Sprintf("ckm: 删除 %s", name))
+}
+
+// List 列出分支根目录下以 prefix 开头的文件，更新时间取最后一次提交时间。
+func (b *Backend) List(ctx context.Context, prefix string) ([]remote.ObjectInfo, error) {
+	if err := b.sync(ctx); err != nil {
+		return nil, err
+	}
+	out, err := b.git(ctx, "ls-files")
+	if err != nil {
+		return nil, err
+	}
+
+	var items []remote.ObjectInfo
+	for _, name := range strings.Split(out, "\n") {
+		if name == "" || strings.Contains(name, "/") || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		info := remote.ObjectInfo{Name: name}
+		if st, err := os.Stat(filepath.Join(b.workDir, name)); err == nil {
+			info.Size = st.Size()
+		}
+		if ts, err := b.git(ctx, "log", "-1", "--format=%ct", "--", name); err == nil {
+			if sec, err := strconv.ParseInt(strings.TrimSpace(ts), 10, 64); err == nil {
+				info.UpdatedAt = time.Unix(sec, 0).UTC()
+			}
+		}
+		items = append(items, info)
+	}
+	return items, nil
+}
+
+// sync 初始化工作副本并重置到远端分支的最新提交，远端分支不存在时切换到孤立分支
+func (b *Backend) sync(ctx context.Context) error {
+	if _, err := os.Stat(filepath.Join(b.workDir, ".git")); errors.Is(err, os.ErrNotExist) {
+		if err := os.MkdirAll(b.workDir, 0o700); err != nil {
+			return fmt.Errorf("创建 Git 工作目录失败: %w", err)
+		}
+		if _, err := b.git(ctx, "init", "-q"); err != nil {
+			return err
+		}
+		if _, err := b.git(ctx, "remote", "add", "origin", b.repo); err != nil {
+			return err
+		}
+	} else if _, err := b.git(ctx, "remote", "set-url", "origin", b.repo); err != nil {
+		return err
+	}
+
+	refspec := fmt.Sprintf("+refs/heads/%[1]s:refs/remotes/origin/%[1]s", b.branch)
+	if _, err := b.git(ctx, "fetch", "-q", "--prune", "origin", refspec); err != nil {
+		// 空仓库或分支尚未创建时 fetch 会失败，此时继续使用孤立分支
+		logging.Debugf("git fetch 未获取到分支 %s: %v", b.branch, err)
+	}
+
+	remoteRef := "refs/remotes/origin/" + b.branch
+	if _, err := b.git(ctx, "rev-parse", "--verify", "-q", remoteRef); err == nil {
+		if _, err := b.git(ctx, "checkout", "-q", "-f", "-B", b.branch, remoteRef); err != nil {
+			return err
+		}
+		_, err := b.git(ctx, "clean", "-q", "-f", "-d")
+		return err
+	}
+	_, err := b.git(ctx, "symbolic-ref", "HEAD", "refs/heads/"+b.branch)
+	return err
+}
+
+// commitAndPush 提交暂存区的变更并推送到远端分支
+func (b *Backend) commitAndPush(ctx context.Context, message string) error {
+	if _, err := b.git(ctx, "diff", "--cached", "--quiet"); err == nil {
+		logging.Debugf("快照未变化，跳过提交")
+		return nil
+	}
+
+	args := []string{"commit", "-q", "-m", message}
+	if out, _ := b.git(ctx, "config", "user.email"); strings.TrimSpace(out) == "" {
+		// 未配置提交者信息时使用默认身份，避免提交失败
+		args = append([]string{"-c", "user.name=ckm", "-c", "user.email=ckm@localhost"}, args...)
+	}
+	if _, err := b.git(ctx, args...); err != nil {
+		return err
+	}
+	if _, err := b.git(ctx, "push", "-q", "origin", "HEAD:refs/heads/"+b.branch); err != nil {
+		return fmt.Errorf("推送到 Git 仓库失败，远端可能已被其他设备更新，请重试: %w", err)
+	}
+	return nil
+}
+
+// git 在工作目录中执行 git 命令并返回标准输出
+func (b *Backend) git(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = b.workDir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "LC_ALL=C")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return stdout.String(), fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return stdout.String(), nil
+}
+
+// path 校验对象名并返回工作副本中的完整路径
+func (b *Backend) path(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("非法的对象名: %q", name)
+	}
+	return filepath.Join(b.workDir, name), nil
+}
@@ -0,0 +1,122 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+	"github.com/codex-switch/codex-switch/internal/remote"
+)
+
+func newBareRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("未安装 git，跳过")
+	}
+	repo := filepath.Join(t.TempDir(), "sync.git")
+	if out, err := exec.Command("git", "init", "-q", "--bare", repo).CombinedOutput(); err != nil {
+		t.Fatalf("创建裸仓库失败: %v %s", err, out)
+	}
+	return repo
+}
+
+func sealedPayload(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := remote.Codec{SyncToken: "token-for-tests-0123456789"}.Encode(remote.BuildSnapshot(&config.Config{
+		Keys: []config.APIKey{{ID: "1", Name: name, APIKey: "sk-secret-value"}},
+	}))
+	if err != nil {
+		t.Fatalf("加密快照失败: %v", err)
+	}
+	return data
+}
+
+// TestBackendSyncBetweenClones 模拟两台机器通过同一裸仓库同步
+func TestBackendSyncBetweenClones(t *testing.T) {
+	repo := newBareRepo(t)
+	settings := &config.RemoteSettings{Repo: repo}
+	ctx := context.Background()
+
+	laptop, err := NewBackend(settings, filepath.Join(t.TempDir(), "laptop"))
+	if err != nil {
+		t.Fatalf("创建后端失败: %v", err)
+	}
+	desktop, err := NewBackend(settings, filepath.Join(t.TempDir(), "desktop"))
+	if err != nil {
+		t.Fatalf("创建后端失败: %v", err)
+	}
+	if err := laptop.Prepare(ctx); err != nil {
+		t.Fatalf("空仓库 Prepare 失败: %v", err)
+	}
+	if _, err := desktop.Download(ctx, "default.json"); !errors.Is(err, remote.ErrNotFound) {
+		t.Fatalf("期望 ErrNotFound，实际 %v", err)
+	}
+
+	first := sealedPayload(t, "main")
+	if err := laptop.Upload(ctx, "default.json", first); err != nil {
+		t.Fatalf("上传失败: %v", err)
+	}
+	got, err := desktop.Download(ctx, "default.json")
+	if err != nil || string(got) != string(first) {
+		t.Fatalf("另一副本读取结果不一致: %v", err)
+	}
+
+	second := sealedPayload(t, "backup")
+	if err := desktop.Upload(ctx, "default.json", second); err != nil {
+		t.Fatalf("第二次上传失败: %v", err)
+	}
+	// 内容未变化时不应产生新提交
+	if err := desktop.Upload(ctx, "default.json", second); err != nil {
+		t.Fatalf("重复上传失败: %v", err)
+	}
+	got, err = laptop.Download(ctx, "default.json")
+	if err != nil || string(got) != string(second) {
+		t.Fatalf("应读取到最新快照: %v", err)
+	}
+
+	out, err := exec.Command("git", "--git-dir", repo, "rev-list", "--count", DefaultBranch).Output()
+	if err != nil {
+		t.Fatalf("读取提交历史失败: %v", err)
+	}
+	if string(out) != "2\n" {
+		t.Fatalf("期望 2 个提交，实际 %s", out)
+	}
+
+	items, err := laptop.List(ctx, "default")
+	if err != nil || len(items) != 1 || items[0].UpdatedAt.IsZero() {
+		t.Fatalf("列表结果异常: %+v %v", items, err)
+	}
+
+	if err := laptop.Delete(ctx, "default.json"); err != nil {
+		t.Fatalf("删除失败: %v", err)
+	}
+	if _, err := desktop.Download(ctx, "default.json"); !errors.Is(err, remote.ErrNotFound) {
+		t.Fatalf("删除后期望 ErrNotFound，实际 %v", err)
+	}
+}
+
+// TestBackendRejectsPlaintext 验证默认拒绝提交明文快照
+func TestBackendRejectsPlaintext(t *testing.T) {
+	repo := newBareRepo(t)
+	ctx := context.Background()
+	plain := []byte(`{"keys":[{"api_key":"sk-secret-value"}]}`)
+
+	strict, err := NewBackend(&config.RemoteSettings{Repo: repo}, filepath.Join(t.TempDir(), "strict"))
+	if err != nil {
+		t.Fatalf("创建后端失败: %v", err)
+	}
+	if err := strict.Upload(ctx, "default.json", plain); !errors.Is(err, ErrPlaintext) {
+		t.Fatalf("期望 ErrPlaintext，实际 %v", err)
+	}
+
+	relaxed, err := NewBackend(&config.RemoteSettings{Repo: repo, AllowPlaintext: true}, filepath.Join(t.TempDir(), "relaxed"))
+	if err != nil {
+		t.Fatalf("创建后端失败: %v", err)
+	}
+	if err := relaxed.Upload(ctx, "default.json", plain); err != nil {
+		t.Fatalf("允许明文时上传失败: %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package remote
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultHistoryLimit 为未配置保留数量时每个 profile 保留的历史版本数
+const DefaultHistoryLimit = 20
+
+// historyInfix 用于区分历史版本对象与最新快照，profile 名不包含 "."，因此不会冲突
+const historyInfix = ".history."
+
+// versionTimeLayout 为版本 ID 的时间部分，按字典序即可排序
+const versionTimeLayout = "20060102T150405Z"
+
+// Version 描述远端的一个历史快照版本
+type Version struct {
+	ID        string
+	Object    string
+	Size      int64
+	UpdatedAt time.Time
+}
+
+// LatestObjectName 返回 profile 最新快照的对象名，兼容旧版本客户端直接读取
+func LatestObjectName(profile string) string {
+	return profile + ".json"
+}
+
+// VersionObjectName 返回指定版本的不可变对象名
+func VersionObjectName(profile string, id string) string {
+	return profile + historyInfix + id + ".json"
+}
+
+// NewVersionID 根据时间生成版本 ID，附加随机后缀避免同一秒内多次推送冲突
+func NewVersionID(now time.Time) string {
+	suffix := make([]byte, 2)
+	_, _ = rand.Read(suffix)
+	return now.UTC().Format(versionTimeLayout) + "-" + hex.EncodeToString(suffix)
+}
+
+// VersionTime 解析版本 ID 中的时间，无法解析时返回零值
+func VersionTime(id string) time.Time {
+	stamp, _, _ := strings.Cut(id, "-")
+	t, err := time.Parse(versionTimeLayout, stamp)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// ListVersions 列出 profile 的全部历史版本，按时间从新到旧排序
+func ListVersions(ctx context.Context, backend Backend, profile string) ([]Version, error) {
+	prefix := profile + historyInfix
+	objects, err := backend.List(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("列出历史版本失败: %w", err)
+	}
+
+	versions := make([]Version, 0, len(objects))
+	for _, obj := range objects {
+		if !strings.HasPrefix(obj.Name, prefix) || !strings.HasSuffix(obj.Name, ".json") {
+			continue
+		}
+		id := strings.TrimSuffix(strings.TrimPrefix(obj.Name, prefix), ".json")
+		if id == "" || strings.Contains(id, ".") {
+			continue
+		}
+		updated := VersionTime(id)
+		if updated.IsZero() {
+			updated = obj.UpdatedAt
+		}
+		versions = append(versions, Version{ID: id, Object: obj.Name, Size: obj.Size, UpdatedAt: updated})
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].ID > versions[j].ID })
+	return versions, nil
+}
+
+// PruneVersions 删除超出保留数量的旧版本并返回被删除的版本 ID，keep 小于 0 表示不清理
+func PruneVersions(ctx context.Context, backend Backend, profile string, keep int) ([]string, error) {
+	if keep < 0 {
+		return nil, nil
+	}
+	if keep == 0 {
+		keep = DefaultHistoryLimit
+	}
+	versions, err := ListVersions(ctx, backend, profile)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) <= keep {
+		return nil, nil
+	}
+
+	var removed []string
+	for _, v := range versions[keep:] {
+		if err := backend.Delete(ctx, v.Object); err != nil {
+			return removed, fmt.Errorf("删除历史版本 %s 失败: %w", v.ID, err)
+		}
+		removed = append(removed, v.ID)
+	}
+	return removed, nil
+}
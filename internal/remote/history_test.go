@@ -0,0 +1,96 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// memoryBackend 为测试使用的内存后端
+type memoryBackend struct {
+	objects map[string][]byte
+}
+
+func newMemoryBackend() *memoryBackend {
+	return &memoryBackend{objects: make(map[string][]byte)}
+}
+
+func (m *memoryBackend) Prepare(context.Context) error { return nil }
+
+func (m *memoryBackend) Upload(_ context.Context, name string, data []byte) error {
+	m.objects[name] = append([]byte{}, data...)
+	return nil
+}
+
+func (m *memoryBackend) Download(_ context.Context, name string) ([]byte, error) {
+	data, ok := m.objects[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return data, nil
+}
+
+func (m *memoryBackend) Delete(_ context.Context, name string) error {
+	delete(m.objects, name)
+	return nil
+}
+
+func (m *memoryBackend) List(_ context.Context, prefix string) ([]ObjectInfo, error) {
+	var items []ObjectInfo
+	for name, data := range m.objects {
+		if strings.HasPrefix(name, prefix) {
+			items = append(items, ObjectInfo{Name: name, Size: int64(len(data))})
+		}
+	}
+	return items, nil
+}
+
+// TestVersionsListAndPrune 验证版本按时间排序、不混入其他 profile，且清理保留最新版本
+func TestVersionsListAndPrune(t *testing.T) {
+	ctx := context.Background()
+	backend := newMemoryBackend()
+	base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var ids []string
+	for i := 0; i < 5; i++ {
+		id := NewVersionID(base.Add(time.Duration(i) * time.Minute))
+		ids = append(ids, id)
+		_ = backend.Upload(ctx, VersionObjectName("work", id), []byte("v"))
+	}
+	_ = backend.Upload(ctx, LatestObjectName("work"), []byte("latest"))
+	_ = backend.Upload(ctx, VersionObjectName("home", NewVersionID(base)), []byte("v"))
+
+	versions, err := ListVersions(ctx, backend, "work")
+	if err != nil {
+		t.Fatalf("列出版本失败: %v", err)
+	}
+	if len(versions) != 5 || versions[0].ID != ids[4] || versions[4].ID != ids[0] {
+		t.Fatalf("版本排序不正确: %+v", versions)
+	}
+	if !versions[0].UpdatedAt.Equal(base.Add(4 * time.Minute)) {
+		t.Fatalf("版本时间解析错误: %v", versions[0].UpdatedAt)
+	}
+
+	removed, err := PruneVersions(ctx, backend, "work", 2)
+	if err != nil {
+		t.Fatalf("清理失败: %v", err)
+	}
+	if len(removed) != 3 {
+		t.Fatalf("期望清理 3 个版本，实际 %v", removed)
+	}
+	if _, err := backend.Download(ctx, VersionObjectName("work", ids[4])); err != nil {
+		t.Fatalf("最新版本不应被清理: %v", err)
+	}
+	if _, err := backend.Download(ctx, LatestObjectName("work")); err != nil {
+		t.Fatalf("最新快照不应被清理: %v", err)
+	}
+	if others, _ := ListVersions(ctx, backend, "home"); len(others) != 1 {
+		t.Fatalf("其他 profile 的版本不应受影响")
+	}
+
+	if removed, _ := PruneVersions(ctx, backend, "work", -1); len(removed) != 0 {
+		t.Fatalf("负数保留数量不应清理")
+	}
+}
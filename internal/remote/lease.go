@@ -0,0 +1,279 @@
+package remote
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+)
+
+// ErrObjectExists 表示条件创建时目标对象已存在
+var ErrObjectExists = errors.New("远程对象已存在")
+
+// ErrLeaseHeld 表示 Key 已被其他用户租用且租约未过期
+var ErrLeaseHeld = errors.New("Key 已被其他用户租用")
+
+// leasePrefix 为租约对象名前缀，对象名包含 "."，不会被识别为配置档案
+const leasePrefix = "lease."
+
+// ErrConditionFailed 表示条件删除时对象已被其他客户端修改
+var ErrConditionFailed = errors.New("远程对象已被其他客户端修改")
+
+// Creator 为支持条件创建的后端实现的可选接口：对象已存在时返回 ErrObjectExists，
+// 由存储服务保证并发创建时只有一方成功。
+type Creator interface {
+	Create(ctx context.Context, name string, data []byte) error
+}
+
+// ConditionalDeleter 为支持按版本条件删除的后端实现的可选接口
+type ConditionalDeleter interface {
+	// DownloadTagged 读取对象及其版本标识(如 ETag)，不存在时返回 ErrNotFound
+	DownloadTagged(ctx context.Context, name string) ([]byte, string, error)
+	// DeleteIf 仅在对象的版本标识仍为 tag 时删除，已被修改时返回 ErrConditionFailed，
+	// 对象已不存在时视为成功
+	DeleteIf(ctx context.Context, name string, tag string) error
+}
+
+// LeaseBackend 为可保存租约的后端：租约的独占性依赖存储服务提供的条件创建与条件删除
+type LeaseBackend interface {
+	Backend
+	Creator
+	ConditionalDeleter
+}
+
+// AsLeaseBackend 检查后端是否支持租约。B2、Git 等无法原子地条件写入的存储返回错误，
+// 避免在无法保证独占时仍然发放租约。
+func AsLeaseBackend(backend Backend, provider string) (LeaseBackend, error) {
+	lb, ok := backend.(LeaseBackend)
+	if !ok {
+		return nil, fmt.Errorf("%s 存储不支持条件写入，无法保证租约独占；请使用 s3、webdav 或 dir 类型的远程保存租约 (--remote)", provider)
+	}
+	return lb, nil
+}
+
+// Lease 为 Key 的独占租约，保存在远程存储中，过期后其他用户可直接接管。
+// 租约按 Identity 命名，KeyID 与 KeyName 为持有者本地的 Key，仅用于展示
+type Lease struct {
+	Identity   string    `json:"identity"`
+	KeyID      string    `json:"key_id"`
+	KeyName    string    `json:"key_name"`
+	Owner      string    `json:"owner"`
+	Host       string    `json:"host"`
+	User       string    `json:"user"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// Expired 判断租约在 now 时是否已过期
+func (l *Lease) Expired(now time.Time) bool {
+	return !now.Before(l.ExpiresAt)
+}
+
+// NewLease 为当前机器构建 key 的租约，有效期为 ttl
+func NewLease(key config.APIKey, ttl time.Duration, now time.Time) Lease {
+	host, name := leaseIdentity()
+	return Lease{
+		Identity:   LeaseIdentity(key),
+		KeyID:      key.ID,
+		KeyName:    key.Name,
+		Owner:      name + "@" + host,
+		Host:       host,
+		User:       name,
+		AcquiredAt: now.UTC(),
+		ExpiresAt:  now.Add(ttl).UTC(),
+	}
+}
+
+// LeaseOwner 返回当前机器的租约持有者标识，格式为 user@host
+func LeaseOwner() string {
+	host, name := leaseIdentity()
+	return name + "@" + host
+}
+
+// leaseIdentity 返回当前主机名与用户名
+func leaseIdentity() (string, string) {
+	host, _ := os.Hostname()
+	name := os.Getenv("USER")
+	if current, err := user.Current(); err == nil && current.Username != "" {
+		name = current.Username
+	}
+	if name == "" {
+		name = "unknown"
+	}
+	return host, name
+}
+
+// LeaseIdentity 返回 Key 在各机器间一致的租约标识。Key ID 由各机器独立分配，
+// 同步与团队配置都可能重新编号，因此使用 API Key 与 Base URL 的摘要；
+// 未设置 API Key 时退回到忽略大小写的 Key 名称，与同步时的匹配规则一致
+func LeaseIdentity(key config.APIKey) string {
+	material := "name:" + strings.ToLower(strings.TrimSpace(key.Name))
+	if secret := strings.TrimSpace(key.APIKey); secret != "" {
+		base := strings.TrimRight(strings.ToLower(strings.TrimSpace(key.BaseURL)), "/")
+		material = "key:" + secret + "\n" + base
+	}
+	sum := sha256.Sum256([]byte("codex-switch lease v1\n" + material))
+	return hex.EncodeToString(sum[:16])
+}
+
+// LeaseObjectName 返回租约的对象名，标识中的非法字符替换为 -
+func LeaseObjectName(identity string) string {
+	var builder strings.Builder
+	for _, r := range identity {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+			builder.WriteRune(r)
+		} else {
+			builder.WriteRune('-')
+		}
+	}
+	return leasePrefix + builder.String() + ".json"
+}
+
+// GetLease 读取标识对应的租约，不存在时返回 ErrNotFound
+func GetLease(ctx context.Context, backend Backend, identity string) (*Lease, error) {
+	data, err := backend.Download(ctx, LeaseObjectName(identity))
+	if err != nil {
+		return nil, err
+	}
+	return parseLease(data)
+}
+
+// getTaggedLease 读取标识对应的租约及其版本标识，不存在时返回 ErrNotFound
+func getTaggedLease(ctx context.Context, backend LeaseBackend, identity string) (*Lease, string, error) {
+	data, tag, err := backend.DownloadTagged(ctx, LeaseObjectName(identity))
+	if err != nil {
+		return nil, "", err
+	}
+	lease, err := parseLease(data)
+	return lease, tag, err
+}
+
+func parseLease(data []byte) (*Lease, error) {
+	var lease Lease
+	if err := json.Unmarshal(data, &lease); err != nil {
+		return nil, fmt.Errorf("解析租约失败: %w", err)
+	}
+	return &lease, nil
+}
+
+// AcquireLease 为 lease.Identity 创建租约。已持有的租约直接续期，过期租约按版本条件删除后
+// 重新创建；其他用户持有未过期的租约，或并发接管时被他人抢先，返回 ErrLeaseHeld 与当前租约。
+func AcquireLease(ctx context.Context, backend LeaseBackend, lease Lease, now time.Time) (*Lease, error) {
+	if lease.Identity == "" {
+		return nil, errors.New("租约缺少 Key 标识")
+	}
+	data, err := json.MarshalIndent(lease, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	name := LeaseObjectName(lease.Identity)
+
+	err = backend.Create(ctx, name, data)
+	if !errors.Is(err, ErrObjectExists) {
+		return nil, err
+	}
+
+	current, tag, err := getTaggedLease(ctx, backend, lease.Identity)
+	if errors.Is(err, ErrNotFound) {
+		// 租约恰好被释放，重新创建
+		return nil, createLease(ctx, backend, name, data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case current.Owner == lease.Owner:
+		return nil, backend.Upload(ctx, name, data)
+	case current.Expired(now):
+		// 仅删除读取到的这份过期租约；其他用户已抢先接管时版本标识不同，删除失败
+		if err := backend.DeleteIf(ctx, name, tag); err != nil {
+			if errors.Is(err, ErrConditionFailed) {
+				return heldLease(ctx, backend, lease.Identity)
+			}
+			return nil, err
+		}
+		if err := createLease(ctx, backend, name, data); err != nil {
+			if errors.Is(err, ErrLeaseHeld) {
+				return heldLease(ctx, backend, lease.Identity)
+			}
+			return nil, err
+		}
+		return nil, nil
+	default:
+		return current, ErrLeaseHeld
+	}
+}
+
+// heldLease 返回并发接管失败时其他用户持有的租约与 ErrLeaseHeld
+func heldLease(ctx context.Context, backend Backend, identity string) (*Lease, error) {
+	current, err := GetLease(ctx, backend, identity)
+	if err != nil {
+		return nil, ErrLeaseHeld
+	}
+	return current, ErrLeaseHeld
+}
+
+// createLease 条件创建租约，与其他用户同时接管时返回 ErrLeaseHeld
+func createLease(ctx context.Context, backend Creator, name string, data []byte) error {
+	err := backend.Create(ctx, name, data)
+	if errors.Is(err, ErrObjectExists) {
+		return ErrLeaseHeld
+	}
+	return err
+}
+
+// ReleaseLease 删除标识对应的租约。租约属于其他用户且未过期时，仅在 force 为 true 时删除；
+// 按读取到的版本条件删除，不会误删他人在此期间接管的租约
+func ReleaseLease(ctx context.Context, backend LeaseBackend, identity string, owner string, force bool, now time.Time) error {
+	current, tag, err := getTaggedLease(ctx, backend, identity)
+	if err != nil {
+		return err
+	}
+	if current.Owner != owner && !current.Expired(now) && !force {
+		return fmt.Errorf("%w: %s 持有至 %s", ErrLeaseHeld, current.Owner, current.ExpiresAt.Local().Format("2006-01-02 15:04:05"))
+	}
+	if err := backend.DeleteIf(ctx, LeaseObjectName(identity), tag); err != nil {
+		if errors.Is(err, ErrConditionFailed) {
+			return fmt.Errorf("%w: 租约在释放期间已被修改，请重新执行", ErrLeaseHeld)
+		}
+		return err
+	}
+	return nil
+}
+
+// ListLeases 列出远端全部租约，按 Key 名称排序；无法解析的对象跳过。
+// Identity 取自对象名，与释放时使用的对象一致
+func ListLeases(ctx context.Context, backend Backend) ([]Lease, error) {
+	objects, err := backend.List(ctx, leasePrefix)
+	if err != nil {
+		return nil, fmt.Errorf("列出租约失败: %w", err)
+	}
+	leases := make([]Lease, 0, len(objects))
+	for _, obj := range objects {
+		if !strings.HasSuffix(obj.Name, ".json") {
+			continue
+		}
+		data, err := backend.Download(ctx, obj.Name)
+		if err != nil {
+			continue
+		}
+		var lease Lease
+		if err := json.Unmarshal(data, &lease); err != nil {
+			continue
+		}
+		lease.Identity = strings.TrimSuffix(strings.TrimPrefix(obj.Name, leasePrefix), ".json")
+		leases = append(leases, lease)
+	}
+	sort.Slice(leases, func(i, j int) bool { return leases[i].KeyName < leases[j].KeyName })
+	return leases, nil
+}
@@ -0,0 +1,172 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+)
+
+// memoryLeaseBackend 在内存后端上增加原子创建与按版本条件删除，版本号在每次写入时递增
+type memoryLeaseBackend struct {
+	*memoryBackend
+	versions map[string]int
+	next     int
+	// beforeDelete 在条件删除前调用，用于模拟并发接管
+	beforeDelete func()
+}
+
+func newMemoryLeaseBackend() *memoryLeaseBackend {
+	return &memoryLeaseBackend{memoryBackend: newMemoryBackend(), versions: make(map[string]int)}
+}
+
+func (m *memoryLeaseBackend) Upload(ctx context.Context, name string, data []byte) error {
+	m.next++
+	m.versions[name] = m.next
+	return m.memoryBackend.Upload(ctx, name, data)
+}
+
+func (m *memoryLeaseBackend) Create(ctx context.Context, name string, data []byte) error {
+	if _, ok := m.objects[name]; ok {
+		return fmt.Errorf("%w: %s", ErrObjectExists, name)
+	}
+	return m.Upload(ctx, name, data)
+}
+
+func (m *memoryLeaseBackend) DownloadTagged(ctx context.Context, name string) ([]byte, string, error) {
+	data, err := m.Download(ctx, name)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, strconv.Itoa(m.versions[name]), nil
+}
+
+func (m *memoryLeaseBackend) DeleteIf(ctx context.Context, name string, tag string) error {
+	if hook := m.beforeDelete; hook != nil {
+		m.beforeDelete = nil
+		hook()
+	}
+	if _, ok := m.objects[name]; !ok {
+		return nil
+	}
+	if strconv.Itoa(m.versions[name]) != tag {
+		return fmt.Errorf("%w: %s", ErrConditionFailed, name)
+	}
+	return m.Delete(ctx, name)
+}
+
+// TestAcquireLease 验证租约的独占、续期、过期接管与释放
+func TestAcquireLease(t *testing.T) {
+	ctx := context.Background()
+	backend := newMemoryLeaseBackend()
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	alice := Lease{Identity: "team:relay:1", KeyID: "1", KeyName: "relay", Owner: "alice@a", ExpiresAt: now.Add(time.Hour)}
+	if _, err := AcquireLease(ctx, backend, alice, now); err != nil {
+		t.Fatalf("租用失败: %v", err)
+	}
+	if LeaseObjectName(alice.Identity) != "lease.team-relay-1.json" {
+		t.Fatalf("租约对象名不正确: %s", LeaseObjectName(alice.Identity))
+	}
+
+	bob := Lease{Identity: "team:relay:1", KeyID: "1", KeyName: "relay", Owner: "bob@b", ExpiresAt: now.Add(2 * time.Hour)}
+	current, err := AcquireLease(ctx, backend, bob, now)
+	if !errors.Is(err, ErrLeaseHeld) || current == nil || current.Owner != "alice@a" {
+		t.Fatalf("期望租约被 alice 持有，got=%+v err=%v", current, err)
+	}
+	if err := ReleaseLease(ctx, backend, bob.Identity, bob.Owner, false, now); !errors.Is(err, ErrLeaseHeld) {
+		t.Fatalf("不应释放他人的租约，got=%v", err)
+	}
+
+	// 持有者重复租用视为续期
+	alice.ExpiresAt = now.Add(3 * time.Hour)
+	if _, err := AcquireLease(ctx, backend, alice, now); err != nil {
+		t.Fatalf("续期失败: %v", err)
+	}
+	lease, err := GetLease(ctx, backend, alice.Identity)
+	if err != nil || !lease.ExpiresAt.Equal(alice.ExpiresAt) {
+		t.Fatalf("续期后到期时间不正确: %+v err=%v", lease, err)
+	}
+
+	// 过期后其他用户可以接管
+	later := now.Add(4 * time.Hour)
+	bob.ExpiresAt = later.Add(time.Hour)
+	if _, err := AcquireLease(ctx, backend, bob, later); err != nil {
+		t.Fatalf("接管过期租约失败: %v", err)
+	}
+	leases, err := ListLeases(ctx, backend)
+	if err != nil || len(leases) != 1 || leases[0].Owner != "bob@b" {
+		t.Fatalf("租约列表不正确: %+v err=%v", leases, err)
+	}
+
+	if err := ReleaseLease(ctx, backend, bob.Identity, bob.Owner, false, later); err != nil {
+		t.Fatalf("释放失败: %v", err)
+	}
+	if _, err := GetLease(ctx, backend, bob.Identity); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("释放后租约应不存在，got=%v", err)
+	}
+}
+
+// TestAcquireLeaseConcurrentTakeover 验证两个用户同时接管过期租约时只有一方成功
+func TestAcquireLeaseConcurrentTakeover(t *testing.T) {
+	ctx := context.Background()
+	backend := newMemoryLeaseBackend()
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	alice := Lease{Identity: "relay", Owner: "alice@a", ExpiresAt: now.Add(time.Hour)}
+	if _, err := AcquireLease(ctx, backend, alice, now); err != nil {
+		t.Fatalf("租用失败: %v", err)
+	}
+
+	later := now.Add(2 * time.Hour)
+	bob := Lease{Identity: "relay", Owner: "bob@b", ExpiresAt: later.Add(time.Hour)}
+	carol := Lease{Identity: "relay", Owner: "carol@c", ExpiresAt: later.Add(time.Hour)}
+	// bob 读取到过期租约后、删除前，carol 抢先完成接管
+	backend.beforeDelete = func() {
+		if _, err := AcquireLease(ctx, backend, carol, later); err != nil {
+			t.Fatalf("carol 接管失败: %v", err)
+		}
+	}
+	current, err := AcquireLease(ctx, backend, bob, later)
+	if !errors.Is(err, ErrLeaseHeld) || current == nil || current.Owner != "carol@c" {
+		t.Fatalf("期望租约被 carol 持有，got=%+v err=%v", current, err)
+	}
+	lease, err := GetLease(ctx, backend, "relay")
+	if err != nil || lease.Owner != "carol@c" {
+		t.Fatalf("carol 的租约不应被删除: %+v err=%v", lease, err)
+	}
+}
+
+// TestLeaseIdentityAcrossMachines 验证租约按各机器一致的标识命名：同一 Key 在两台机器上
+// ID 不同时仍互斥，不同 Key 恰好 ID 相同时互不影响
+func TestLeaseIdentityAcrossMachines(t *testing.T) {
+	ctx := context.Background()
+	backend := newMemoryLeaseBackend()
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	aliceKey := config.APIKey{ID: "3", Name: "relay", APIKey: "sk-shared", BaseURL: "https://relay.example.com/v1"}
+	bobKey := config.APIKey{ID: "team:dev:1", Name: "Relay", APIKey: "sk-shared", BaseURL: "https://relay.example.com/v1/"}
+	other := config.APIKey{ID: "3", Name: "personal", APIKey: "sk-personal"}
+
+	alice := NewLease(aliceKey, time.Hour, now)
+	alice.Owner = "alice@a"
+	if _, err := AcquireLease(ctx, backend, alice, now); err != nil {
+		t.Fatalf("租用失败: %v", err)
+	}
+	bob := NewLease(bobKey, time.Hour, now)
+	bob.Owner = "bob@b"
+	current, err := AcquireLease(ctx, backend, bob, now)
+	if !errors.Is(err, ErrLeaseHeld) || current == nil || current.Owner != "alice@a" {
+		t.Fatalf("同一 Key 的 ID 不同也应被 alice 持有，got=%+v err=%v", current, err)
+	}
+
+	personal := NewLease(other, time.Hour, now)
+	personal.Owner = "bob@b"
+	if _, err := AcquireLease(ctx, backend, personal, now); err != nil {
+		t.Fatalf("ID 相同的其他 Key 不应被占用: %v", err)
+	}
+}
@@ -0,0 +1,427 @@
+package remote
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+)
+
+// 同步变更的方向
+const (
+	SideLocal  = "local"
+	SideRemote = "remote"
+)
+
+// 同步变更的类型
+const (
+	ChangeAdded   = "added"
+	ChangeUpdated = "updated"
+	ChangeDeleted = "deleted"
+)
+
+// SyncChange 描述一侧相对于合并基准的一次变更，Side 表示变更来源
+type SyncChange struct {
+	Name   string
+	Side   string
+	Action string
+}
+
+// SyncConflict 描述两侧对同一个 Key 的不兼容修改。
+//
+// Local/Remote 为 nil 表示该侧删除了 Key；Fields 列出双方修改不一致的字段，
+// 删除与修改冲突、双方新增同名 Key 时 Fields 为空。
+type SyncConflict struct {
+	Name   string
+	Fields []string
+	Local  *config.APIKey
+	Remote *config.APIKey
+
+	// merged 为自动合并非冲突字段后的结果，冲突字段取本地值
+	merged   *config.APIKey
+	resolved bool
+	choice   string
+}
+
+// Resolved 判断冲突是否已指定处理方式
+func (c *SyncConflict) Resolved() bool {
+	return c.resolved
+}
+
+// SyncResult 为三方合并的结果，冲突全部解决后可通过 Keys 获取最终列表
+type SyncResult struct {
+	Changes   []SyncChange
+	Conflicts []*SyncConflict
+
+	slots []syncSlot
+}
+
+// syncSlot 保持合并后 Key 的顺序，conflict 非空时内容由冲突的处理结果决定
+type syncSlot struct {
+	key      *config.APIKey
+	conflict *SyncConflict
+}
+
+// Resolve 指定冲突采用哪一侧的修改，side 为 SideLocal 或 SideRemote
+func (c *SyncConflict) Resolve(side string) {
+	c.choice = side
+	c.resolved = true
+}
+
+// Unresolved 返回尚未处理的冲突
+func (r *SyncResult) Unresolved() []*SyncConflict {
+	var list []*SyncConflict
+	for _, c := range r.Conflicts {
+		if !c.resolved {
+			list = append(list, c)
+		}
+	}
+	return list
+}
+
+// ChangesFrom 返回指定一侧的变更
+func (r *SyncResult) ChangesFrom(side string) []SyncChange {
+	var list []SyncChange
+	for _, c := range r.Changes {
+		if c.Side == side {
+			list = append(list, c)
+		}
+	}
+	return list
+}
+
+// Keys 返回合并后的 Key 列表，未解决的冲突按本地版本处理
+func (r *SyncResult) Keys() []config.APIKey {
+	keys := make([]config.APIKey, 0, len(r.slots))
+	for _, slot := range r.slots {
+		if slot.conflict == nil {
+			keys = append(keys, *slot.key)
+			continue
+		}
+		if key := slot.conflict.pick(); key != nil {
+			keys = append(keys, *key)
+		}
+	}
+	return keys
+}
+
+// pick 根据处理方式返回冲突的最终结果，nil 表示删除
+func (c *SyncConflict) pick() *config.APIKey {
+	switch {
+	case c.merged != nil:
+		if c.choice != SideRemote {
+			return c.merged
+		}
+		merged := *c.merged
+		for _, field := range syncFields {
+			if containsString(c.Fields, field.name) {
+				field.set(&merged, field.get(c.Remote))
+			}
+		}
+		return &merged
+	case c.choice == SideRemote:
+		return c.Remote
+	default:
+		return c.Local
+	}
+}
+
+// syncField 描述参与三方合并的字符串字段
+type syncField struct {
+	name string
+	get  func(*config.APIKey) string
+	set  func(*config.APIKey, string)
+}
+
+var syncFields = []syncField{
+	{"api_key", func(k *config.APIKey) string { return k.APIKey }, func(k *config.APIKey, v string) { k.APIKey = v }},
+	{"base_url", func(k *config.APIKey) string { return k.BaseURL }, func(k *config.APIKey, v string) { k.BaseURL = v }},
+	{"type", func(k *config.APIKey) string { return k.Type }, func(k *config.APIKey, v string) { k.Type = v }},
+	{"description", func(k *config.APIKey) string { return k.Description }, func(k *config.APIKey, v string) { k.Description = v }},
+	{"provider", func(k *config.APIKey) string { return k.Provider }, func(k *config.APIKey, v string) { k.Provider = v }},
+	{"preferred_auth_method", func(k *config.APIKey) string { return k.PreferredAuthMethod }, func(k *config.APIKey, v string) { k.PreferredAuthMethod = v }},
+	{"wire_api", func(k *config.APIKey) string { return k.WireAPI }, func(k *config.APIKey, v string) { k.WireAPI = v }},
+	{"env_key", func(k *config.APIKey) string { return k.EnvKey }, func(k *config.APIKey, v string) { k.EnvKey = v }},
+	{"raw_config", func(k *config.APIKey) string { return k.RawConfig }, func(k *config.APIKey, v string) { k.RawConfig = v }},
+	{"requires_openai_auth", getRequiresAuth, setRequiresAuth},
+}
+
+func getRequiresAuth(k *config.APIKey) string {
+	if k.RequiresOpenAIAuth == nil {
+		return ""
+	}
+	return strconv.FormatBool(*k.RequiresOpenAIAuth)
+}
+
+func setRequiresAuth(k *config.APIKey, v string) {
+	if v == "" {
+		k.RequiresOpenAIAuth = nil
+		return
+	}
+	b := v == "true"
+	k.RequiresOpenAIAuth = &b
+}
+
+// MergeKeys 以 base 为合并基准，对本地与远端的 Key 列表执行三方合并。
+//
+// Key 按名称(忽略大小写)匹配；只有一侧修改的字段自动采用修改后的值，
+// 两侧对同一字段修改不一致、一侧删除而另一侧修改、双方新增同名但内容不同的 Key
+// 记为冲突。标签按集合合并，使用时间取较新值；ID 与激活状态保留本地值，
+// 激活 Key 由各机器自行维护。
+func MergeKeys(base, local, remote []config.APIKey) *SyncResult {
+	baseIdx := indexByName(base)
+	localIdx := indexByName(local)
+	remoteIdx := indexByName(remote)
+
+	usedIDs := make(map[string]bool, len(local))
+	maxID := 0
+	for _, k := range local {
+		usedIDs[k.ID] = true
+		if n, err := strconv.Atoi(k.ID); err == nil && n > maxID {
+			maxID = n
+		}
+	}
+	// remoteKey 处理仅来自远端的 Key：保持非激活，并在 ID 冲突时重新编号
+	remoteKey := func(k config.APIKey) *config.APIKey {
+		k.Active = false
+		if k.ID == "" || usedIDs[k.ID] {
+			maxID++
+			k.ID = strconv.Itoa(maxID)
+		}
+		usedIDs[k.ID] = true
+		return &k
+	}
+
+	result := &SyncResult{}
+	addChange := func(name, side, action string) {
+		result.Changes = append(result.Changes, SyncChange{Name: name, Side: side, Action: action})
+	}
+	addConflict := func(c *SyncConflict) {
+		result.Conflicts = append(result.Conflicts, c)
+		result.slots = append(result.slots, syncSlot{conflict: c})
+	}
+
+	for i := range local {
+		l := local[i]
+		name := nameKey(l.Name)
+		b, inBase := baseIdx[name]
+		r, inRemote := remoteIdx[name]
+
+		switch {
+		case !inBase && !inRemote:
+			addChange(l.Name, SideLocal, ChangeAdded)
+			result.slots = append(result.slots, syncSlot{key: &l})
+		case !inBase && inRemote:
+			// 双方新增同名 Key，内容一致时视为同一个
+			if len(changedFields(&l, r)) == 0 {
+				merged := mergeMeta(config.APIKey{}, l, *r)
+				result.slots = append(result.slots, syncSlot{key: &merged})
+			} else {
+				// 选择远端版本时沿用本地 ID，避免重复编号
+				rk := *r
+				rk.ID, rk.Active = l.ID, l.Active
+				addConflict(&SyncConflict{Name: l.Name, Local: &l, Remote: &rk})
+			}
+		case inBase && !inRemote:
+			if len(changedFields(b, &l)) == 0 {
+				addChange(l.Name, SideRemote, ChangeDeleted)
+			} else {
+				addConflict(&SyncConflict{Name: l.Name, Local: &l})
+			}
+		default:
+			merged, fields, localChanged, remoteChanged := mergeKey(*b, l, *r)
+			if len(fields) > 0 {
+				addConflict(&SyncConflict{Name: l.Name, Fields: fields, Local: &l, Remote: r, merged: &merged})
+				continue
+			}
+			if localChanged {
+				addChange(l.Name, SideLocal, ChangeUpdated)
+			}
+			if remoteChanged {
+				addChange(l.Name, SideRemote, ChangeUpdated)
+			}
+			result.slots = append(result.slots, syncSlot{key: &merged})
+		}
+	}
+
+	for i := range remote {
+		r := remote[i]
+		name := nameKey(r.Name)
+		if _, ok := localIdx[name]; ok {
+			continue
+		}
+		b, inBase := baseIdx[name]
+		switch {
+		case !inBase:
+			addChange(r.Name, SideRemote, ChangeAdded)
+			result.slots = append(result.slots, syncSlot{key: remoteKey(r)})
+		case len(changedFields(b, &r)) == 0:
+			addChange(r.Name, SideLocal, ChangeDeleted)
+		default:
+			addConflict(&SyncConflict{Name: r.Name, Remote: remoteKey(r)})
+		}
+	}
+	return result
+}
+
+// mergeKey 对单个 Key 执行字段级三方合并，返回合并结果、冲突字段以及两侧是否有修改
+func mergeKey(base, local, remote config.APIKey) (config.APIKey, []string, bool, bool) {
+	merged := mergeMeta(base, local, remote)
+	var conflicts []string
+	localChanged, remoteChanged := false, false
+
+	for _, field := range syncFields {
+		b, l, r := field.get(&base), field.get(&local), field.get(&remote)
+		switch {
+		case l == r:
+			// 两侧一致(包括做了相同修改)，无需处理
+		case l == b:
+			field.set(&merged, r)
+			remoteChanged = true
+		case r == b:
+			localChanged = true
+		default:
+			conflicts = append(conflicts, field.name)
+		}
+	}
+	if !sameTags(merged.Tags, local.Tags) {
+		remoteChanged = true
+	}
+	if !sameTags(merged.Tags, remote.Tags) {
+		localChanged = true
+	}
+	return merged, conflicts, localChanged, remoteChanged
+}
+
+// mergeMeta 以本地 Key 为基础合并标签与时间戳，字符串字段保持本地值
+func mergeMeta(base, local, remote config.APIKey) config.APIKey {
+	merged := local
+	merged.Tags = mergeTags(base.Tags, local.Tags, remote.Tags)
+	if remote.LastUsed.After(merged.LastUsed) {
+		merged.LastUsed = remote.LastUsed
+	}
+	if remote.LastChecked.After(merged.LastChecked) {
+		merged.LastChecked = remote.LastChecked
+	}
+	if merged.CreatedAt.IsZero() || (!remote.CreatedAt.IsZero() && remote.CreatedAt.Before(merged.CreatedAt)) {
+		merged.CreatedAt = remote.CreatedAt
+	}
+	return merged
+}
+
+// mergeTags 对标签执行集合意义上的三方合并：任一侧新增的保留，任一侧删除的移除
+func mergeTags(base, local, remote []string) []string {
+	inBase := tagSet(base)
+	inLocal := tagSet(local)
+	inRemote := tagSet(remote)
+
+	var result []string
+	seen := make(map[string]bool)
+	for _, list := range [][]string{local, remote} {
+		for _, tag := range list {
+			key := strings.ToLower(strings.TrimSpace(tag))
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			// 基准中存在但任一侧已删除的标签不再保留
+			if inBase[key] && (!inLocal[key] || !inRemote[key]) {
+				continue
+			}
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+// changedFields 返回两个 Key 之间内容不同的字段，忽略 ID、激活状态与时间戳
+func changedFields(a, b *config.APIKey) []string {
+	var fields []string
+	for _, field := range syncFields {
+		if field.get(a) != field.get(b) {
+			fields = append(fields, field.name)
+		}
+	}
+	if !sameTags(a.Tags, b.Tags) {
+		fields = append(fields, "tags")
+	}
+	return fields
+}
+
+func sameTags(a, b []string) bool {
+	sa, sb := tagSet(a), tagSet(b)
+	if len(sa) != len(sb) {
+		return false
+	}
+	for tag := range sa {
+		if !sb[tag] {
+			return false
+		}
+	}
+	return true
+}
+
+func tagSet(tags []string) map[string]bool {
+	set := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		if key := strings.ToLower(strings.TrimSpace(tag)); key != "" {
+			set[key] = true
+		}
+	}
+	return set
+}
+
+func indexByName(keys []config.APIKey) map[string]*config.APIKey {
+	idx := make(map[string]*config.APIKey, len(keys))
+	for i := range keys {
+		idx[nameKey(keys[i].Name)] = &keys[i]
+	}
+	return idx
+}
+
+func nameKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// FieldValue 返回 Key 中参与合并的字段值，用于展示冲突详情
+func FieldValue(key *config.APIKey, name string) string {
+	if key == nil {
+		return ""
+	}
+	for _, field := range syncFields {
+		if field.name == name {
+			return field.get(key)
+		}
+	}
+	return ""
+}
+
+// DiffKeys 比较两个 Key 列表，返回仅存在于 a、仅存在于 b 以及内容不同的 Key 名称
+func DiffKeys(a, b []config.APIKey) (onlyA, onlyB, changed []string) {
+	idxA := indexByName(a)
+	idxB := indexByName(b)
+	for i := range a {
+		other, ok := idxB[nameKey(a[i].Name)]
+		switch {
+		case !ok:
+			onlyA = append(onlyA, a[i].Name)
+		case len(changedFields(&a[i], other)) > 0:
+			changed = append(changed, a[i].Name)
+		}
+	}
+	for i := range b {
+		if _, ok := idxA[nameKey(b[i].Name)]; !ok {
+			onlyB = append(onlyB, b[i].Name)
+		}
+	}
+	return onlyA, onlyB, changed
+}
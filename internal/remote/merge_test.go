@@ -0,0 +1,113 @@
+package remote
+
+import (
+	"testing"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+)
+
+func findKey(keys []config.APIKey, name string) *config.APIKey {
+	for i := range keys {
+		if keys[i].Name == name {
+			return &keys[i]
+		}
+	}
+	return nil
+}
+
+// TestMergeKeysAutoMerge 验证两侧互不冲突的新增、修改、删除均被自动合并
+func TestMergeKeysAutoMerge(t *testing.T) {
+	base := []config.APIKey{
+		{ID: "1", Name: "main", APIKey: "sk-main", BaseURL: "https://a.example.com", Tags: []string{"prod"}},
+		{ID: "2", Name: "old", APIKey: "sk-old"},
+		{ID: "3", Name: "gone", APIKey: "sk-gone"},
+	}
+	local := []config.APIKey{
+		{ID: "1", Name: "main", APIKey: "sk-main", BaseURL: "https://a.example.com", Description: "本地描述", Tags: []string{"prod", "local"}, Active: true},
+		{ID: "3", Name: "gone", APIKey: "sk-gone"},
+		{ID: "4", Name: "laptop", APIKey: "sk-laptop"},
+	}
+	remoteKeys := []config.APIKey{
+		{ID: "1", Name: "main", APIKey: "sk-main-rotated", BaseURL: "https://a.example.com", Tags: []string{"team"}},
+		{ID: "2", Name: "old", APIKey: "sk-old"},
+		{ID: "4", Name: "desktop", APIKey: "sk-desktop", Active: true},
+	}
+
+	result := MergeKeys(base, local, remoteKeys)
+	if len(result.Conflicts) != 0 {
+		t.Fatalf("不应存在冲突: %+v", result.Conflicts[0])
+	}
+	keys := result.Keys()
+	if len(keys) != 3 {
+		t.Fatalf("期望 3 个 Key，实际 %+v", keys)
+	}
+
+	main := findKey(keys, "main")
+	if main == nil || main.APIKey != "sk-main-rotated" || main.Description != "本地描述" || !main.Active {
+		t.Fatalf("main 字段级合并结果不正确: %+v", main)
+	}
+	if !sameTags(main.Tags, []string{"local", "team"}) {
+		t.Fatalf("标签合并结果不正确: %v", main.Tags)
+	}
+	if findKey(keys, "old") != nil || findKey(keys, "gone") != nil {
+		t.Fatalf("两侧删除的 Key 应被移除")
+	}
+	desktop := findKey(keys, "desktop")
+	if desktop == nil || desktop.ID == "4" || desktop.Active {
+		t.Fatalf("远端新增 Key 应重新编号且保持非激活: %+v", desktop)
+	}
+	if findKey(keys, "laptop") == nil {
+		t.Fatalf("本地新增 Key 应保留")
+	}
+	if len(result.ChangesFrom(SideLocal)) != 3 || len(result.ChangesFrom(SideRemote)) != 3 {
+		t.Fatalf("变更统计不正确: %+v", result.Changes)
+	}
+}
+
+// TestMergeKeysConflicts 验证冲突检测以及按策略处理
+func TestMergeKeysConflicts(t *testing.T) {
+	base := []config.APIKey{
+		{ID: "1", Name: "main", APIKey: "sk-main", Description: "base"},
+		{ID: "2", Name: "shared", APIKey: "sk-shared"},
+	}
+	local := []config.APIKey{
+		{ID: "1", Name: "main", APIKey: "sk-local", Description: "base", BaseURL: "https://local.example.com"},
+	}
+	remoteKeys := []config.APIKey{
+		{ID: "1", Name: "main", APIKey: "sk-remote", Description: "remote"},
+		{ID: "2", Name: "shared", APIKey: "sk-shared-new"},
+	}
+
+	result := MergeKeys(base, local, remoteKeys)
+	if len(result.Conflicts) != 2 {
+		t.Fatalf("期望 2 个冲突，实际 %d", len(result.Conflicts))
+	}
+	edit := result.Conflicts[0]
+	if edit.Name != "main" || len(edit.Fields) != 1 || edit.Fields[0] != "api_key" {
+		t.Fatalf("字段冲突检测不正确: %+v", edit)
+	}
+	if deleted := result.Conflicts[1]; deleted.Local != nil || deleted.Remote == nil {
+		t.Fatalf("应检测到本地删除与远端修改的冲突: %+v", deleted)
+	}
+
+	// 未处理的冲突按本地处理
+	keys := result.Keys()
+	if len(keys) != 1 || keys[0].APIKey != "sk-local" || keys[0].Description != "remote" {
+		t.Fatalf("默认结果不正确: %+v", keys)
+	}
+
+	for _, c := range result.Conflicts {
+		c.Resolve(SideRemote)
+	}
+	keys = result.Keys()
+	main := findKey(keys, "main")
+	if main == nil || main.APIKey != "sk-remote" || main.BaseURL != "https://local.example.com" {
+		t.Fatalf("采用远端后非冲突字段仍应保留本地修改: %+v", main)
+	}
+	if findKey(keys, "shared") == nil {
+		t.Fatalf("采用远端后应恢复被本地删除的 Key")
+	}
+	if len(result.Unresolved()) != 0 {
+		t.Fatalf("冲突应全部解决")
+	}
+}
@@ -0,0 +1,119 @@
+package remote
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// OutboxEntry 记录一个自动推送失败、等待重试的配置档案。
+//
+// 重试时推送的是当时的最新配置，因此每个远程的每个档案只保留一条记录。
+type OutboxEntry struct {
+	// Remote 为推送的远程名称，旧版本记录为空，表示默认远程
+	Remote    string    `json:"remote,omitempty"`
+	Profile   string    `json:"profile"`
+	QueuedAt  time.Time `json:"queued_at"`
+	Attempts  int       `json:"attempts"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// Outbox 为待推送队列，持久化在配置目录下
+type Outbox struct {
+	Entries []OutboxEntry `json:"entries"`
+}
+
+// LoadOutbox 读取待推送队列，文件不存在时返回空队列
+func LoadOutbox(path string) (*Outbox, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Outbox{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var box Outbox
+	if err := json.Unmarshal(data, &box); err != nil {
+		return nil, err
+	}
+	return &box, nil
+}
+
+// Save 写回待推送队列，队列为空时删除文件
+func (o *Outbox) Save(path string) error {
+	if len(o.Entries) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(o, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// Queue 记录 remote 上的 profile 推送失败，已存在时累加尝试次数并更新错误信息
+func (o *Outbox) Queue(remote, profile string, cause error) {
+	msg := ""
+	if cause != nil {
+		msg = cause.Error()
+	}
+	for i := range o.Entries {
+		if o.Entries[i].Remote == remote && o.Entries[i].Profile == profile {
+			o.Entries[i].Attempts++
+			o.Entries[i].LastError = msg
+			return
+		}
+	}
+	o.Entries = append(o.Entries, OutboxEntry{
+		Remote:    remote,
+		Profile:   profile,
+		QueuedAt:  time.Now().UTC(),
+		Attempts:  1,
+		LastError: msg,
+	})
+}
+
+// Remove 移除 remote 上 profile 的待推送记录，返回记录是否存在
+func (o *Outbox) Remove(remote, profile string) bool {
+	for i := range o.Entries {
+		if o.Entries[i].Remote == remote && o.Entries[i].Profile == profile {
+			o.Entries = append(o.Entries[:i], o.Entries[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// RenameRemote 将 oldName 上的待推送记录改记到 newName，返回改写的记录数。newName 上
+// 已有同一档案的记录(如已删除远程的残留)时合并为一条，避免同一档案重复推送
+func (o *Outbox) RenameRemote(oldName, newName string) int {
+	renamed := 0
+	entries := make([]OutboxEntry, 0, len(o.Entries))
+	index := make(map[[2]string]int, len(o.Entries))
+	for _, entry := range o.Entries {
+		if entry.Remote == oldName {
+			entry.Remote = newName
+			renamed++
+		}
+		key := [2]string{entry.Remote, entry.Profile}
+		if i, ok := index[key]; ok {
+			entries[i].Attempts += entry.Attempts
+			if entry.QueuedAt.Before(entries[i].QueuedAt) {
+				entries[i].QueuedAt = entry.QueuedAt
+			}
+			continue
+		}
+		index[key] = len(entries)
+		entries = append(entries, entry)
+	}
+	o.Entries = entries
+	return renamed
+}
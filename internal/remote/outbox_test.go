@@ -0,0 +1,74 @@
+package remote
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestOutboxQueueAndRemove 验证待推送队列按档案去重并在清空后删除文件
+func TestOutboxQueueAndRemove(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.json")
+	box, err := LoadOutbox(path)
+	if err != nil || len(box.Entries) != 0 {
+		t.Fatalf("文件不存在时应返回空队列: %v", err)
+	}
+
+	box.Queue("origin", "default", errors.New("network down"))
+	box.Queue("origin", "default", errors.New("timeout"))
+	box.Queue("team", "default", errors.New("forbidden"))
+	if err := box.Save(path); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+	loaded, err := LoadOutbox(path)
+	if err != nil {
+		t.Fatalf("读取失败: %v", err)
+	}
+	if len(loaded.Entries) != 2 || loaded.Entries[0].Attempts != 2 || loaded.Entries[0].LastError != "timeout" {
+		t.Fatalf("同一远程的同一档案应合并为一条记录: %+v", loaded.Entries)
+	}
+	if loaded.Entries[1].Remote != "team" || loaded.Entries[1].Attempts != 1 {
+		t.Fatalf("不同远程的档案应分别记录: %+v", loaded.Entries[1])
+	}
+
+	if !loaded.Remove("origin", "default") || !loaded.Remove("team", "default") {
+		t.Fatalf("应移除两个远程的 default")
+	}
+	if loaded.Remove("origin", "default") {
+		t.Fatalf("重复移除应返回 false")
+	}
+	if err := loaded.Save(path); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("队列为空时应删除文件, got %v", err)
+	}
+}
+
+// TestOutboxRenameRemote 验证重命名远程时改写待推送记录，并与新名称上的残留记录合并
+func TestOutboxRenameRemote(t *testing.T) {
+	box := &Outbox{}
+	box.Queue("work", "default", errors.New("network down"))
+	box.Queue("work", "dev", errors.New("network down"))
+	box.Queue("corp", "dev", errors.New("stale"))
+	box.Queue("origin", "default", errors.New("timeout"))
+
+	if n := box.RenameRemote("work", "corp"); n != 2 {
+		t.Fatalf("应改写 2 条记录, got %d", n)
+	}
+	if len(box.Entries) != 3 {
+		t.Fatalf("同一档案的记录应合并: %+v", box.Entries)
+	}
+	for _, entry := range box.Entries {
+		if entry.Remote == "work" {
+			t.Fatalf("不应保留旧远程名称: %+v", entry)
+		}
+		if entry.Remote == "corp" && entry.Profile == "dev" && entry.Attempts != 2 {
+			t.Fatalf("合并后应累加尝试次数: %+v", entry)
+		}
+	}
+	if !box.Remove("corp", "default") || !box.Remove("origin", "default") {
+		t.Fatalf("其他记录应保持不变: %+v", box.Entries)
+	}
+}
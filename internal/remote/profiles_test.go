@@ -0,0 +1,47 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestCopyAndDeleteProfile 验证档案复制包含历史版本，且不覆盖已存在的目标
+func TestCopyAndDeleteProfile(t *testing.T) {
+	ctx := context.Background()
+	backend := newMemoryBackend()
+	backend.objects[LatestObjectName("default")] = []byte("latest")
+	backend.objects[VersionObjectName("default", "20260101T000000Z-aaaa")] = []byte("v1")
+	backend.objects[VersionObjectName("default", "20260102T000000Z-bbbb")] = []byte("v2")
+	backend.objects[LatestObjectName("work")] = []byte("work")
+
+	copied, err := CopyProfile(ctx, backend, "default", "team", false)
+	if err != nil {
+		t.Fatalf("复制失败: %v", err)
+	}
+	if copied != 3 || string(backend.objects["team.json"]) != "latest" {
+		t.Fatalf("复制结果不符合预期: copied=%d", copied)
+	}
+	if _, err := CopyProfile(ctx, backend, "default", "work", false); !errors.Is(err, ErrProfileExists) {
+		t.Fatalf("目标已存在时应返回 ErrProfileExists, got %v", err)
+	}
+	if _, err := CopyProfile(ctx, backend, "missing", "other", false); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("源档案不存在时应返回 ErrNotFound, got %v", err)
+	}
+
+	profiles, err := ListProfiles(ctx, backend)
+	if err != nil {
+		t.Fatalf("列出档案失败: %v", err)
+	}
+	if len(profiles) != 3 || profiles[1].Name != "team" || profiles[1].Versions != 2 {
+		t.Fatalf("档案列表不符合预期: %+v", profiles)
+	}
+
+	removed, err := DeleteProfile(ctx, backend, "default")
+	if err != nil || removed != 2 {
+		t.Fatalf("删除档案失败: removed=%d err=%v", removed, err)
+	}
+	if len(backend.objects) != 4 {
+		t.Fatalf("删除后应剩余 4 个对象, got %d", len(backend.objects))
+	}
+}
@@ -0,0 +1,241 @@
+package remote
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// 多接收者信封 (版本 2)，参考 age 的 X25519 方案：
+//
+//	CKMSEAL | 0x02 | 接收者数量(1 字节) | 接收者节 * N | nonce(12) | AES-256-GCM 密文
+//
+// 每个接收者节为 公钥指纹(8) | 临时公钥(32) | 加密后的数据密钥(48)。数据密钥随机生成，
+// 分别用临时私钥与接收者公钥协商出的密钥加密；整个信封头作为正文的附加认证数据。
+const (
+	sealVersionRecipients byte = 2
+	sealInfoRecipient          = "codex-switch recipient v1"
+	recipientPrefix            = "x25519:"
+	fingerprintSize            = 8
+	wrappedKeySize             = 32 + 16
+	stanzaSize                 = fingerprintSize + 32 + wrappedKeySize
+	maxRecipients              = 255
+)
+
+// ErrNoIdentity 表示快照按接收者加密，但本机尚未创建身份密钥
+var ErrNoIdentity = errors.New("快照按接收者公钥加密，请先执行 ckm identity init 并让团队成员将公钥加入接收者列表")
+
+// ErrRecipientDecrypt 表示按接收者加密的快照解密失败，通常是身份私钥有误或数据已损坏
+var ErrRecipientDecrypt = errors.New("解密快照失败: 身份私钥与接收者公钥不匹配或数据已损坏")
+
+// ErrNotRecipient 表示本机身份不在快照的接收者列表中
+var ErrNotRecipient = errors.New("本机身份不在快照的接收者列表中，无法解密")
+
+// GenerateIdentity 生成新的 X25519 身份私钥，返回 Base64 编码
+func GenerateIdentity() (string, error) {
+	priv, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(priv.Bytes()), nil
+}
+
+// ParseIdentity 解析 Base64 编码的 X25519 身份私钥
+func ParseIdentity(encoded string) (*ecdh.PrivateKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, errors.New("无效的身份私钥")
+	}
+	priv, err := ecdh.X25519().NewPrivateKey(raw)
+	if err != nil {
+		return nil, errors.New("无效的身份私钥")
+	}
+	return priv, nil
+}
+
+// EncodeRecipient 返回公钥的文本形式 x25519:<Base64>
+func EncodeRecipient(pub *ecdh.PublicKey) string {
+	return recipientPrefix + base64.StdEncoding.EncodeToString(pub.Bytes())
+}
+
+// ParseRecipient 解析 x25519:<Base64> 形式的接收者公钥
+func ParseRecipient(encoded string) (*ecdh.PublicKey, error) {
+	text := strings.TrimSpace(encoded)
+	if !strings.HasPrefix(text, recipientPrefix) {
+		return nil, fmt.Errorf("无效的接收者公钥，应以 %s 开头: %s", recipientPrefix, encoded)
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(text, recipientPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("无效的接收者公钥: %s", encoded)
+	}
+	pub, err := ecdh.X25519().NewPublicKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("无效的接收者公钥: %s", encoded)
+	}
+	return pub, nil
+}
+
+// RecipientFingerprint 返回公钥指纹的十六进制形式，用于比较信封的接收者
+func RecipientFingerprint(pub *ecdh.PublicKey) string {
+	return fmt.Sprintf("%x", fingerprint(pub))
+}
+
+// SealedRecipients 返回多接收者信封中各接收者的公钥指纹(已排序)；
+// 非多接收者信封返回 nil。
+func SealedRecipients(data []byte) []string {
+	stanzas, _, err := parseRecipientHeader(data)
+	if err != nil {
+		return nil
+	}
+	prints := make([]string, 0, len(stanzas))
+	for _, s := range stanzas {
+		prints = append(prints, fmt.Sprintf("%x", s[:fingerprintSize]))
+	}
+	sort.Strings(prints)
+	return prints
+}
+
+func fingerprint(pub *ecdh.PublicKey) []byte {
+	sum := sha256.Sum256(pub.Bytes())
+	return sum[:fingerprintSize]
+}
+
+// sealForRecipients 使用随机数据密钥加密数据，并为每个接收者封装一份数据密钥
+func sealForRecipients(plain []byte, recipients []*ecdh.PublicKey) ([]byte, error) {
+	if len(recipients) == 0 {
+		return nil, errors.New("接收者列表为空")
+	}
+	if len(recipients) > maxRecipients {
+		return nil, fmt.Errorf("接收者数量不能超过 %d", maxRecipients)
+	}
+	fileKey := make([]byte, 32)
+	if _, err := rand.Read(fileKey); err != nil {
+		return nil, err
+	}
+
+	header := append(append([]byte(nil), sealMagic...), sealVersionRecipients, byte(len(recipients)))
+	for _, pub := range recipients {
+		ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		shared, err := ephemeral.ECDH(pub)
+		if err != nil {
+			return nil, err
+		}
+		wrap, err := wrapAEAD(shared, ephemeral.PublicKey(), pub)
+		if err != nil {
+			return nil, err
+		}
+		header = append(header, fingerprint(pub)...)
+		header = append(header, ephemeral.PublicKey().Bytes()...)
+		header = wrap.Seal(header, make([]byte, wrap.NonceSize()), fileKey, nil)
+	}
+
+	aead, err := newGCM(fileKey)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out := append(header, nonce...)
+	return aead.Seal(out, nonce, plain, header), nil
+}
+
+// openForIdentity 使用身份私钥解密 sealForRecipients 生成的信封
+func openForIdentity(data []byte, identity *ecdh.PrivateKey) ([]byte, error) {
+	if identity == nil {
+		return nil, ErrNoIdentity
+	}
+	stanzas, headerLen, err := parseRecipientHeader(data)
+	if err != nil {
+		return nil, err
+	}
+
+	own := fingerprint(identity.PublicKey())
+	var fileKey []byte
+	for _, s := range stanzas {
+		if !bytes.Equal(s[:fingerprintSize], own) {
+			continue
+		}
+		ephemeral, err := ecdh.X25519().NewPublicKey(s[fingerprintSize : fingerprintSize+32])
+		if err != nil {
+			return nil, ErrRecipientDecrypt
+		}
+		shared, err := identity.ECDH(ephemeral)
+		if err != nil {
+			return nil, ErrRecipientDecrypt
+		}
+		wrap, err := wrapAEAD(shared, ephemeral, identity.PublicKey())
+		if err != nil {
+			return nil, ErrRecipientDecrypt
+		}
+		fileKey, err = wrap.Open(nil, make([]byte, wrap.NonceSize()), s[fingerprintSize+32:], nil)
+		if err == nil {
+			break
+		}
+	}
+	if fileKey == nil {
+		return nil, ErrNotRecipient
+	}
+
+	aead, err := newGCM(fileKey)
+	if err != nil {
+		return nil, err
+	}
+	header := data[:headerLen]
+	rest := data[headerLen:]
+	if len(rest) < aead.NonceSize() {
+		return nil, errors.New("加密快照格式不完整")
+	}
+	nonce, ciphertext := rest[:aead.NonceSize()], rest[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, ciphertext, header)
+	if err != nil {
+		return nil, ErrRecipientDecrypt
+	}
+	return plain, nil
+}
+
+// parseRecipientHeader 拆分多接收者信封头，返回各接收者节与信封头长度
+func parseRecipientHeader(data []byte) ([][]byte, int, error) {
+	prefix := len(sealMagic) + 2
+	if !IsSealed(data) || len(data) < prefix || data[len(sealMagic)] != sealVersionRecipients {
+		return nil, 0, errors.New("不是多接收者信封")
+	}
+	count := int(data[len(sealMagic)+1])
+	headerLen := prefix + count*stanzaSize
+	if count == 0 || len(data) < headerLen {
+		return nil, 0, errors.New("加密快照格式不完整")
+	}
+	stanzas := make([][]byte, count)
+	for i := range stanzas {
+		start := prefix + i*stanzaSize
+		stanzas[i] = data[start : start+stanzaSize]
+	}
+	return stanzas, headerLen, nil
+}
+
+// wrapAEAD 由 X25519 协商结果及双方公钥派生封装数据密钥用的 AEAD。
+// 每个临时密钥只使用一次，因此可以使用全零 nonce。
+func wrapAEAD(shared []byte, ephemeral, recipient *ecdh.PublicKey) (cipher.AEAD, error) {
+	secret := append(append(shared, ephemeral.Bytes()...), recipient.Bytes()...)
+	return newGCM(deriveKey(secret, sealInfoRecipient))
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
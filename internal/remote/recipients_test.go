@@ -0,0 +1,114 @@
+package remote
+
+import (
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"errors"
+	"testing"
+)
+
+func mustIdentity(t *testing.T) *ecdh.PrivateKey {
+	t.Helper()
+	encoded, err := GenerateIdentity()
+	if err != nil {
+		t.Fatalf("生成身份失败: %v", err)
+	}
+	priv, err := ParseIdentity(encoded)
+	if err != nil {
+		t.Fatalf("解析身份失败: %v", err)
+	}
+	return priv
+}
+
+func mustSigningKey(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	seed, err := GenerateSigningKey()
+	if err != nil {
+		t.Fatalf("生成私钥失败: %v", err)
+	}
+	priv, err := ParseSigningKey(seed)
+	if err != nil {
+		t.Fatalf("解析私钥失败: %v", err)
+	}
+	return priv
+}
+
+// TestRecipientsEnvelope 验证每个接收者都能用自己的私钥解密，其他人无法解密
+func TestRecipientsEnvelope(t *testing.T) {
+	alice, bob, eve := mustIdentity(t), mustIdentity(t), mustIdentity(t)
+	bobPub, err := ParseRecipient(EncodeRecipient(bob.PublicKey()))
+	if err != nil {
+		t.Fatalf("解析公钥失败: %v", err)
+	}
+
+	signer := mustSigningKey(t)
+	if _, err := (Codec{Recipients: []*ecdh.PublicKey{alice.PublicKey()}}).Encode(testSnapshot()); !errors.Is(err, ErrSigningKeyRequired) {
+		t.Fatalf("未配置签名私钥时应拒绝按接收者加密, got %v", err)
+	}
+	writer := Codec{Recipients: []*ecdh.PublicKey{alice.PublicKey(), bobPub}, SigningKey: signer}
+	data, err := writer.Encode(testSnapshot())
+	if err != nil {
+		t.Fatalf("编码失败: %v", err)
+	}
+	trusted := []ed25519.PublicKey{signer.Public().(ed25519.PublicKey)}
+	if got := SealedRecipients(data); len(got) != 2 {
+		t.Fatalf("信封应包含 2 个接收者, got %v", got)
+	}
+
+	for _, id := range []*ecdh.PrivateKey{alice, bob} {
+		snap, err := (Codec{Identity: id, TrustedKeys: trusted}).Decode(data)
+		if err != nil {
+			t.Fatalf("接收者应能解密: %v", err)
+		}
+		if snap.Keys[0].APIKey != "sk-secret-value" {
+			t.Fatalf("解密内容不符合预期")
+		}
+	}
+	if _, err := (Codec{Identity: eve}).Decode(data); !errors.Is(err, ErrNotRecipient) {
+		t.Fatalf("非接收者应返回 ErrNotRecipient, got %v", err)
+	}
+	if _, err := (Codec{SyncToken: "token-for-tests-0123456789"}).Decode(data); !errors.Is(err, ErrNoIdentity) {
+		t.Fatalf("缺少身份应返回 ErrNoIdentity, got %v", err)
+	}
+
+	// 信封头参与认证，篡改接收者节后正文无法解密
+	tampered := append([]byte(nil), data...)
+	tampered[len(sealMagic)+2+fingerprintSize] ^= 0xff
+	if _, err := (Codec{Identity: alice, TrustedKeys: trusted}).Decode(tampered); err == nil {
+		t.Fatalf("篡改信封头后应解密失败")
+	}
+	if _, err := (Codec{Identity: alice}).Decode(data); !errors.Is(err, ErrUntrustedSigner) {
+		t.Fatalf("签名者不在信任列表时应拒绝, got %v", err)
+	}
+}
+
+// TestRecipientsEnvelopeRequiresSignature 验证任何人都能用公开的接收者公钥封装快照，
+// 因此未签名或仅有 HMAC 签名的接收者信封必须被拒绝
+func TestRecipientsEnvelopeRequiresSignature(t *testing.T) {
+	alice := mustIdentity(t)
+	recipients := []*ecdh.PublicKey{alice.PublicKey()}
+
+	unsigned, err := testSnapshot().Marshal()
+	if err != nil {
+		t.Fatalf("序列化失败: %v", err)
+	}
+	hmacSnap := testSnapshot()
+	if err := (Codec{SyncToken: "token-for-tests-0123456789"}).sign(hmacSnap); err != nil {
+		t.Fatalf("签名失败: %v", err)
+	}
+	hmacSigned, err := hmacSnap.Marshal()
+	if err != nil {
+		t.Fatalf("序列化失败: %v", err)
+	}
+
+	reader := Codec{Identity: alice, SyncToken: "token-for-tests-0123456789"}
+	for name, plain := range map[string][]byte{"未签名": unsigned, "HMAC 签名": hmacSigned} {
+		data, err := sealForRecipients(plain, recipients)
+		if err != nil {
+			t.Fatalf("封装失败: %v", err)
+		}
+		if _, err := reader.Decode(data); !errors.Is(err, ErrRecipientsUnsigned) {
+			t.Fatalf("%s的接收者信封应被拒绝, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,416 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+	"github.com/codex-switch/codex-switch/internal/remote"
+)
+
+const defaultRegion = "us-east-1"
+
+// emptyPayloadHash 为空请求体的 SHA256，用于 GET/DELETE/HEAD 请求签名
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// Client 基于 AWS Signature V4 的 S3 兼容存储客户端，支持 MinIO 等自建服务。
+type Client struct {
+	httpClient *http.Client
+	settings   *config.RemoteSettings
+	endpoint   *url.URL
+	region     string
+	now        func() time.Time
+}
+
+var (
+	_ remote.Backend      = (*Client)(nil)
+	_ remote.LeaseBackend = (*Client)(nil)
+)
+
+// NewClient 根据远程配置创建 S3 客户端。
+//
+// 未配置 Endpoint 时使用 AWS 官方地址 https://s3.<region>.amazonaws.com；
+// PathStyle 为 true 时使用 <endpoint>/<bucket>/<key> 形式访问，适用于 MinIO。
+func NewClient(settings *config.RemoteSettings) (*Client, error) {
+	if settings == nil {
+		return nil, errors.New("远程配置为空")
+	}
+	if !settings.AccessCredentials().Complete() {
+		return nil, errors.New("缺少 S3 Access Key 信息")
+	}
+	if strings.TrimSpace(settings.BucketName) == "" {
+		return nil, errors.New("缺少 S3 存储桶名称")
+	}
+
+	region := strings.TrimSpace(settings.Region)
+	if region == "" {
+		region = defaultRegion
+	}
+	rawEndpoint := strings.TrimSpace(settings.Endpoint)
+	if rawEndpoint == "" {
+		rawEndpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+	}
+	endpoint, err := url.Parse(strings.TrimRight(rawEndpoint, "/"))
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, fmt.Errorf("无效的 S3 Endpoint: %s", rawEndpoint)
+	}
+
+	return &Client{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		settings:   settings,
+		endpoint:   endpoint,
+		region:     region,
+		now:        time.Now,
+	}, nil
+}
+
+// Prepare 通过 HEAD Bucket 校验凭据与存储桶是否可用。
+func (c *Client) Prepare(ctx context.Context) error {
+	resp, err := c.do(ctx, http.MethodHead, "", nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		return fmt.Errorf("未找到存储桶 %s", c.settings.BucketName)
+	case http.StatusForbidden:
+		return errors.New("S3 鉴权失败，请检查 Access Key 与权限")
+	default:
+		return fmt.Errorf("校验存储桶失败: HTTP %d", resp.StatusCode)
+	}
+}
+
+// Upload 使用 PUT Object 覆盖写入对象。
+func (c *Client) Upload(ctx context.Context, name string, data []byte) error {
+	if len(data) == 0 {
+		return errors.New("上传数据为空")
+	}
+	header := http.Header{}
+	header.Set("Content-Type", "application/octet-stream")
+	resp, err := c.do(ctx, http.MethodPut, name, nil, data, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return responseError("上传失败", resp)
+	}
+	return nil
+}
+
+// Create 使用带 If-None-Match: * 的 PUT Object 条件写入，对象已存在时服务端返回 412。
+func (c *Client) Create(ctx context.Context, name string, data []byte) error {
+	if len(data) == 0 {
+		return errors.New("上传数据为空")
+	}
+	header := http.Header{}
+	header.Set("Content-Type", "application/octet-stream")
+	header.Set("If-None-Match", "*")
+	resp, err := c.do(ctx, http.MethodPut, name, nil, data, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusPreconditionFailed, http.StatusConflict:
+		return fmt.Errorf("%w: %s", remote.ErrObjectExists, name)
+	default:
+		return responseError("上传失败", resp)
+	}
+}
+
+// Download 使用 GET Object 读取对象。
+func (c *Client) Download(ctx context.Context, name string) ([]byte, error) {
+	data, _, err := c.DownloadTagged(ctx, name)
+	return data, err
+}
+
+// DownloadTagged 使用 GET Object 读取对象，并返回响应中的 ETag。
+func (c *Client) DownloadTagged(ctx context.Context, name string) ([]byte, string, error) {
+	resp, err := c.do(ctx, http.MethodGet, name, nil, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, "", fmt.Errorf("%w: %s", remote.ErrNotFound, name)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", responseError("下载失败", resp)
+	}
+	data, err := io.ReadAll(resp.Body)
+	return data, resp.Header.Get("ETag"), err
+}
+
+// Delete 删除对象，S3 对不存在的对象同样返回成功。
+func (c *Client) Delete(ctx context.Context, name string) error {
+	resp, err := c.do(ctx, http.MethodDelete, name, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return responseError("删除失败", resp)
+	}
+	return nil
+}
+
+// DeleteIf 使用带 If-Match 的 DELETE Object 条件删除，ETag 不一致时服务端返回 412，
+// 与其他条件请求并发冲突时返回 409。服务端未返回 ETag 时无法条件删除，直接返回错误，
+// 避免空的 If-Match 被忽略后退化为无条件删除。
+func (c *Client) DeleteIf(ctx context.Context, name string, tag string) error {
+	if tag == "" {
+		return fmt.Errorf("S3 服务未返回 ETag，无法条件删除 %s", name)
+	}
+	header := http.Header{}
+	header.Set("If-Match", tag)
+	resp, err := c.do(ctx, http.MethodDelete, name, nil, nil, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNoContent, http.StatusOK, http.StatusNotFound:
+		return nil
+	case http.StatusPreconditionFailed, http.StatusConflict:
+		return fmt.Errorf("%w: %s", remote.ErrConditionFailed, name)
+	default:
+		return responseError("删除失败", resp)
+	}
+}
+
+// List 使用 ListObjectsV2 分页列出以 prefix 开头的对象。
+func (c *Client) List(ctx context.Context, prefix string) ([]remote.ObjectInfo, error) {
+	var items []remote.ObjectInfo
+	token := ""
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		if prefix != "" {
+			query.Set("prefix", prefix)
+		}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		resp, err := c.do(ctx, http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			err := responseError("列出对象失败", resp)
+			resp.Body.Close()
+			return nil, err
+		}
+
+		var result struct {
+			Contents []struct {
+				Key          string    `xml:"Key"`
+				Size         int64     `xml:"Size"`
+				LastModified time.Time `xml:"LastModified"`
+			} `xml:"Contents"`
+			IsTruncated           bool   `xml:"IsTruncated"`
+			NextContinuationToken string `xml:"NextContinuationToken"`
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, obj := range result.Contents {
+			items = append(items, remote.ObjectInfo{Name: obj.Key, Size: obj.Size, UpdatedAt: obj.LastModified.UTC()})
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return items, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// objectURL 根据寻址方式构造对象地址，key 为空时指向存储桶本身
+func (c *Client) objectURL(key string, query url.Values) *url.URL {
+	u := *c.endpoint
+	bucket := c.settings.BucketName
+	basePath := strings.TrimRight(u.Path, "/")
+	if c.settings.PathStyle {
+		u.Path = basePath + "/" + bucket
+	} else {
+		u.Host = bucket + "." + u.Host
+		u.Path = basePath
+	}
+	if key != "" {
+		u.Path += "/" + key
+	} else if c.settings.PathStyle {
+		u.Path += "/"
+	} else if u.Path == "" {
+		u.Path = "/"
+	}
+	u.RawPath = encodePath(u.Path)
+	u.RawQuery = canonicalQuery(query)
+	return &u
+}
+
+// do 构造、签名并发送请求，extra 为需要额外附加的请求头
+func (c *Client) do(ctx context.Context, method string, key string, query url.Values, body []byte, extra ...http.Header) (*http.Response, error) {
+	target := c.objectURL(key, query)
+
+	var reader io.Reader
+	payloadHash := emptyPayloadHash
+	if body != nil {
+		reader = bytes.NewReader(body)
+		sum := sha256.Sum256(body)
+		payloadHash = hex.EncodeToString(sum[:])
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), reader)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range extra {
+		for name, values := range h {
+			for _, v := range values {
+				req.Header.Add(name, v)
+			}
+		}
+	}
+	creds := c.settings.AccessCredentials()
+	signRequest(req, payloadHash, creds.KeyID, creds.ApplicationKey, c.region, c.now().UTC())
+	return c.httpClient.Do(req)
+}
+
+// signRequest 按 AWS Signature V4 为请求添加 Authorization 头。
+//
+// 参与签名的请求头为 host、x-amz-* 以及 content-type/range/if-none-match/if-match。
+func signRequest(req *http.Request, payloadHash, accessKey, secretKey, region string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") || lower == "content-type" || lower == "range" || lower == "if-none-match" || lower == "if-match" {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	hashed := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hashed[:])
+
+	signingKey := hmacSHA256([]byte("AWS4"+secretKey), date)
+	signingKey = hmacSHA256(signingKey, region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// encodePath 按 SigV4 规则逐段编码路径，保留 '/'
+func encodePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = uriEncode(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// canonicalQuery 生成按键排序并编码的查询字符串
+func canonicalQuery(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		for _, v := range query[k] {
+			parts = append(parts, uriEncode(k)+"="+uriEncode(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode 仅保留 RFC 3986 非保留字符，其余按 %XX 大写编码
+func uriEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if (ch >= 'A' && ch <= 'Z') || (ch >= 'a' && ch <= 'z') || (ch >= '0' && ch <= '9') ||
+			ch == '-' || ch == '_' || ch == '.' || ch == '~' {
+			b.WriteByte(ch)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", ch)
+	}
+	return b.String()
+}
+
+// responseError 读取 S3 错误响应中的 Code/Message 字段
+func responseError(action string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	var payload struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	if err := xml.Unmarshal(body, &payload); err == nil && payload.Code != "" {
+		return fmt.Errorf("%s: %s %s", action, payload.Code, payload.Message)
+	}
+	return fmt.Errorf("%s: HTTP %d %s", action, resp.StatusCode, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,175 @@
+package remote
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// 签名格式前缀：hmac-sha256:<签名> 或 ed25519:<公钥>:<签名>，均为标准 Base64
+const (
+	sigPrefixHMAC    = "hmac-sha256:"
+	sigPrefixEd25519 = "ed25519:"
+	signInfoHMAC     = "codex-switch snapshot signature v1"
+)
+
+// ErrUnsigned 表示快照缺少签名
+var ErrUnsigned = errors.New("快照未签名，拒绝导入；确认来源可信后可使用 --insecure-skip-verify")
+
+// ErrBadSignature 表示签名与内容不符，快照可能被篡改
+var ErrBadSignature = errors.New("快照签名校验失败，内容可能被篡改；确认来源可信后可使用 --insecure-skip-verify")
+
+// ErrRecipientsUnsigned 表示按接收者加密的快照缺少 ed25519 签名。接收者公钥是公开的，
+// 任何能写入存储的人都能封装此类信封，因此必须依靠可信签名确认来源
+var ErrRecipientsUnsigned = errors.New("按接收者加密的快照缺少 ed25519 签名，拒绝导入；确认来源可信后可使用 --insecure-skip-verify")
+
+// ErrSigningKeyRequired 表示按接收者加密但未配置 ed25519 签名私钥
+var ErrSigningKeyRequired = errors.New("按接收者加密需要 ed25519 签名私钥，请先执行 ckm remote signing init")
+
+// ErrUntrustedSigner 表示 ed25519 签名者不在信任列表中
+var ErrUntrustedSigner = errors.New("快照签名者不在信任列表中，可执行 ckm remote signing trust <公钥> 添加")
+
+// GenerateSigningKey 生成新的 ed25519 签名私钥，返回 Base64 编码的种子
+func GenerateSigningKey() (string, error) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(priv.Seed()), nil
+}
+
+// ParseSigningKey 解析 Base64 编码的 ed25519 私钥种子
+func ParseSigningKey(encoded string) (ed25519.PrivateKey, error) {
+	seed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, errors.New("无效的签名私钥")
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// ParsePublicKey 解析 Base64 编码的 ed25519 公钥
+func ParsePublicKey(encoded string) (ed25519.PublicKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("无效的签名公钥: %s", encoded)
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// EncodePublicKey 返回私钥对应公钥的 Base64 编码，便于在其他机器上信任
+func EncodePublicKey(priv ed25519.PrivateKey) string {
+	return base64.StdEncoding.EncodeToString(priv.Public().(ed25519.PublicKey))
+}
+
+// canonicalBytes 返回不含签名字段的紧凑 JSON，作为签名内容
+func (s *Snapshot) canonicalBytes() ([]byte, error) {
+	if s == nil {
+		return nil, errors.New("快照为空")
+	}
+	unsigned := *s
+	unsigned.Signature = ""
+	return json.Marshal(&unsigned)
+}
+
+// sign 为快照签名：配置了 ed25519 私钥时使用 ed25519，否则使用 SyncToken 派生的 HMAC
+func (c Codec) sign(snap *Snapshot) error {
+	payload, err := snap.canonicalBytes()
+	if err != nil {
+		return err
+	}
+	if c.SigningKey != nil {
+		sig := ed25519.Sign(c.SigningKey, payload)
+		snap.Signature = sigPrefixEd25519 + EncodePublicKey(c.SigningKey) + ":" + base64.StdEncoding.EncodeToString(sig)
+		return nil
+	}
+	mac, err := c.tokenMAC(payload)
+	if err != nil {
+		return err
+	}
+	snap.Signature = sigPrefixHMAC + base64.StdEncoding.EncodeToString(mac)
+	return nil
+}
+
+// verify 校验快照签名。envelope 为快照所在信封的版本，明文快照为 0。
+// SyncToken 加密的信封已由 AES-GCM 认证，允许未签名的旧快照通过；
+// 按接收者加密的信封任何人都能生成，必须带有可信的 ed25519 签名。
+func (c Codec) verify(snap *Snapshot, envelope byte) error {
+	if envelope == sealVersionRecipients && !strings.HasPrefix(snap.Signature, sigPrefixEd25519) {
+		return ErrRecipientsUnsigned
+	}
+	if snap.Signature == "" {
+		if envelope == sealVersionToken {
+			return nil
+		}
+		return ErrUnsigned
+	}
+	payload, err := snap.canonicalBytes()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case strings.HasPrefix(snap.Signature, sigPrefixHMAC):
+		got, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(snap.Signature, sigPrefixHMAC))
+		if err != nil {
+			return ErrBadSignature
+		}
+		want, err := c.tokenMAC(payload)
+		if err != nil {
+			return err
+		}
+		if !hmac.Equal(got, want) {
+			return ErrBadSignature
+		}
+		return nil
+	case strings.HasPrefix(snap.Signature, sigPrefixEd25519):
+		pubText, sigText, ok := strings.Cut(strings.TrimPrefix(snap.Signature, sigPrefixEd25519), ":")
+		if !ok {
+			return ErrBadSignature
+		}
+		pub, err := ParsePublicKey(pubText)
+		if err != nil {
+			return ErrBadSignature
+		}
+		if !c.trusts(pub) {
+			return ErrUntrustedSigner
+		}
+		sig, err := base64.StdEncoding.DecodeString(sigText)
+		if err != nil || !ed25519.Verify(pub, payload, sig) {
+			return ErrBadSignature
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: 未知的签名格式", ErrBadSignature)
+	}
+}
+
+// trusts 判断公钥是否为本机签名公钥或位于信任列表中
+func (c Codec) trusts(pub ed25519.PublicKey) bool {
+	if c.SigningKey != nil && pub.Equal(c.SigningKey.Public()) {
+		return true
+	}
+	for _, trusted := range c.TrustedKeys {
+		if pub.Equal(trusted) {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenMAC 使用 SyncToken 派生的独立密钥计算 HMAC-SHA256
+func (c Codec) tokenMAC(payload []byte) ([]byte, error) {
+	token := strings.TrimSpace(c.SyncToken)
+	if token == "" {
+		return nil, ErrMissingToken
+	}
+	mac := hmac.New(sha256.New, deriveKey([]byte(token), signInfoHMAC))
+	mac.Write(payload)
+	return mac.Sum(nil), nil
+}
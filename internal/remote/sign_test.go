@@ -0,0 +1,73 @@
+package remote
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// TestSignatureDetectsTampering 验证明文快照被篡改后拒绝导入
+func TestSignatureDetectsTampering(t *testing.T) {
+	codec := Codec{SyncToken: "token-for-tests-0123456789", Plaintext: true}
+	data, err := codec.Encode(testSnapshot())
+	if err != nil {
+		t.Fatalf("编码失败: %v", err)
+	}
+
+	tampered := bytes.Replace(data, []byte("sk-secret-value"), []byte("sk-attacker-key"), 1)
+	if _, err := codec.Decode(tampered); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("篡改后应返回 ErrBadSignature, got %v", err)
+	}
+	if _, err := (Codec{SyncToken: "another-token-0123456789"}).Decode(data); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("不同 token 的 HMAC 应校验失败, got %v", err)
+	}
+}
+
+// TestEd25519Signature 验证 ed25519 签名需要信任签名者
+func TestEd25519Signature(t *testing.T) {
+	seed, err := GenerateSigningKey()
+	if err != nil {
+		t.Fatalf("生成私钥失败: %v", err)
+	}
+	priv, err := ParseSigningKey(seed)
+	if err != nil {
+		t.Fatalf("解析私钥失败: %v", err)
+	}
+	signer := Codec{SyncToken: "token-for-tests-0123456789", SigningKey: priv}
+	data, err := signer.Encode(testSnapshot())
+	if err != nil {
+		t.Fatalf("编码失败: %v", err)
+	}
+
+	reader := Codec{SyncToken: signer.SyncToken}
+	if _, err := reader.Decode(data); !errors.Is(err, ErrUntrustedSigner) {
+		t.Fatalf("未信任的签名者应被拒绝, got %v", err)
+	}
+	pub, err := ParsePublicKey(EncodePublicKey(priv))
+	if err != nil {
+		t.Fatalf("解析公钥失败: %v", err)
+	}
+	reader.TrustedKeys = []ed25519.PublicKey{pub}
+	if _, err := reader.Decode(data); err != nil {
+		t.Fatalf("信任签名者后应通过: %v", err)
+	}
+
+	// 本地快照文件同样校验签名
+	path := filepath.Join(t.TempDir(), "default.json")
+	snap := testSnapshot()
+	if err := signer.SaveSnapshotFile(path, snap); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+	if _, err := reader.LoadSnapshotFile(path); err != nil {
+		t.Fatalf("读取本地快照失败: %v", err)
+	}
+	snap.Keys[0].RawConfig = "model = \"evil\""
+	if err := SaveSnapshotFile(path, snap); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+	if _, err := reader.LoadSnapshotFile(path); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("被修改的本地快照应校验失败, got %v", err)
+	}
+}
@@ -1,11 +1,15 @@
 package remote
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"github.com/codex-switch/codex-switch/internal/config"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -18,21 +22,33 @@ const snapshotSchemaVersion = "1.0"
 type Snapshot struct {
 	SchemaVersion string          `json:"schema_version"`
 	GeneratedAt   time.Time       `json:"generated_at"`
+	Host          string          `json:"host,omitempty"`
 	ActiveKeyID   string          `json:"active_key_id"`
 	Keys          []config.APIKey `json:"keys"`
+	// Signature 为除自身外全部字段的签名，见 Codec.Encode
+	Signature string `json:"signature,omitempty"`
 }
 
 // BuildSnapshot 根据当前配置构建快照，供上传或备份使用。
+//
+// 仅包含参与同步的 Key，LocalOnly 与被 cfg.Remote 标签规则排除的 Key 不会写入快照。
 func BuildSnapshot(cfg *config.Config) *Snapshot {
+	host, _ := os.Hostname()
 	if cfg == nil {
-		return &Snapshot{SchemaVersion: snapshotSchemaVersion, GeneratedAt: time.Now().UTC()}
+		return &Snapshot{SchemaVersion: snapshotSchemaVersion, GeneratedAt: time.Now().UTC(), Host: host}
+	}
+	keys := SyncableKeys(cfg.Keys, cfg.Remote)
+	activeKeyID := ""
+	for _, k := range keys {
+		if k.ID == cfg.ActiveKeyID {
+			activeKeyID = k.ID
+		}
 	}
-	keys := make([]config.APIKey, len(cfg.Keys))
-	copy(keys, cfg.Keys)
 	return &Snapshot{
 		SchemaVersion: snapshotSchemaVersion,
 		GeneratedAt:   time.Now().UTC(),
-		ActiveKeyID:   cfg.ActiveKeyID,
+		Host:          host,
+		ActiveKeyID:   activeKeyID,
 		Keys:          keys,
 	}
 }
@@ -45,6 +61,43 @@ func (s *Snapshot) Marshal() ([]byte, error) {
 	return json.MarshalIndent(s, "", "  ")
 }
 
+// ContentHash 计算快照中 Key 内容的摘要，用于判断两端自上次同步后是否发生变化。
+//
+// 摘要与 Key 的顺序、ID、激活状态及使用时间无关，切换 Key 不会改变摘要。
+func (s *Snapshot) ContentHash() string {
+	if s == nil {
+		return ""
+	}
+	return KeysHash(s.Keys)
+}
+
+// KeysHash 计算 Key 列表的内容摘要，规则同 Snapshot.ContentHash
+func KeysHash(keys []config.APIKey) string {
+	normalized := make([]config.APIKey, len(keys))
+	for i, key := range keys {
+		key.ID = ""
+		key.Active = false
+		key.LastUsed = time.Time{}
+		key.LastChecked = time.Time{}
+		key.CreatedAt = time.Time{}
+		tags := make([]string, 0, len(key.Tags))
+		for _, tag := range key.Tags {
+			if t := strings.ToLower(strings.TrimSpace(tag)); t != "" {
+				tags = append(tags, t)
+			}
+		}
+		sort.Strings(tags)
+		key.Tags = tags
+		normalized[i] = key
+	}
+	sort.Slice(normalized, func(i, j int) bool {
+		return nameKey(normalized[i].Name) < nameKey(normalized[j].Name)
+	})
+	data, _ := json.Marshal(normalized)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
 // UnmarshalSnapshot 反序列化 JSON 快照。
 func UnmarshalSnapshot(data []byte) (*Snapshot, error) {
 	if len(data) == 0 {
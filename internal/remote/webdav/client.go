@@ -0,0 +1,307 @@
+package webdav
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+	"github.com/codex-switch/codex-switch/internal/remote"
+)
+
+// propfindBody 仅请求列举所需的属性，减少服务端负载
+const propfindBody = `<?xml version="1.0" encoding="utf-8"?>
+<d:propfind xmlns:d="DAV:"><d:prop><d:getcontentlength/><d:getlastmodified/><d:resourcetype/></d:prop></d:propfind>`
+
+// Client 通过 WebDAV 协议读写快照，兼容 Nextcloud/ownCloud 等服务。
+//
+// 配置中的 URL 指向用于存放快照的目录(collection)，对象直接存放在该目录下。
+type Client struct {
+	httpClient *http.Client
+	settings   *config.RemoteSettings
+	base       *url.URL
+}
+
+var (
+	_ remote.Backend      = (*Client)(nil)
+	_ remote.LeaseBackend = (*Client)(nil)
+)
+
+// NewClient 根据远程配置创建 WebDAV 客户端。
+func NewClient(settings *config.RemoteSettings) (*Client, error) {
+	if settings == nil {
+		return nil, errors.New("远程配置为空")
+	}
+	raw := strings.TrimSpace(settings.URL)
+	if raw == "" {
+		return nil, errors.New("缺少 WebDAV 地址")
+	}
+	base, err := url.Parse(raw)
+	if err != nil || base.Host == "" || (base.Scheme != "http" && base.Scheme != "https") {
+		return nil, fmt.Errorf("无效的 WebDAV 地址: %s", raw)
+	}
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+	}
+	base.RawPath = ""
+	return &Client{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		settings:   settings,
+		base:       base,
+	}, nil
+}
+
+// Prepare 校验目录是否可访问，不存在时尝试通过 MKCOL 创建。
+func (c *Client) Prepare(ctx context.Context) error {
+	resp, err := c.do(ctx, "PROPFIND", c.base.String(), strings.NewReader(propfindBody), "0")
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusMultiStatus, http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		mk, err := c.do(ctx, "MKCOL", c.base.String(), nil, "")
+		if err != nil {
+			return err
+		}
+		defer mk.Body.Close()
+		if mk.StatusCode != http.StatusCreated {
+			return responseError("创建 WebDAV 目录失败", mk)
+		}
+		return nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return errors.New("WebDAV 鉴权失败，请检查用户名与密码/令牌")
+	default:
+		return fmt.Errorf("访问 WebDAV 目录失败: HTTP %d", resp.StatusCode)
+	}
+}
+
+// Upload 使用 PUT 覆盖写入对象。
+func (c *Client) Upload(ctx context.Context, name string, data []byte) error {
+	if len(data) == 0 {
+		return errors.New("上传数据为空")
+	}
+	resp, err := c.do(ctx, http.MethodPut, c.objectURL(name), bytes.NewReader(data), "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		return nil
+	default:
+		return responseError("上传失败", resp)
+	}
+}
+
+// Create 使用带 If-None-Match: * 的 PUT 条件写入，对象已存在时服务端返回 412。
+func (c *Client) Create(ctx context.Context, name string, data []byte) error {
+	if len(data) == 0 {
+		return errors.New("上传数据为空")
+	}
+	header := http.Header{}
+	header.Set("If-None-Match", "*")
+	resp, err := c.do(ctx, http.MethodPut, c.objectURL(name), bytes.NewReader(data), "", header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		return nil
+	case http.StatusPreconditionFailed:
+		return fmt.Errorf("%w: %s", remote.ErrObjectExists, name)
+	default:
+		return responseError("上传失败", resp)
+	}
+}
+
+// Download 使用 GET 读取对象。
+func (c *Client) Download(ctx context.Context, name string) ([]byte, error) {
+	data, _, err := c.DownloadTagged(ctx, name)
+	return data, err
+}
+
+// DownloadTagged 使用 GET 读取对象，并返回响应中的 ETag。
+func (c *Client) DownloadTagged(ctx context.Context, name string) ([]byte, string, error) {
+	resp, err := c.do(ctx, http.MethodGet, c.objectURL(name), nil, "")
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, "", fmt.Errorf("%w: %s", remote.ErrNotFound, name)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", responseError("下载失败", resp)
+	}
+	data, err := io.ReadAll(resp.Body)
+	return data, resp.Header.Get("ETag"), err
+}
+
+// Delete 删除对象，不存在时视为成功。
+func (c *Client) Delete(ctx context.Context, name string) error {
+	resp, err := c.do(ctx, http.MethodDelete, c.objectURL(name), nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusAccepted, http.StatusNotFound:
+		return nil
+	default:
+		return responseError("删除失败", resp)
+	}
+}
+
+// DeleteIf 使用带 If-Match 的 DELETE 条件删除，ETag 不一致时服务端返回 412。
+// 服务端未返回 ETag 时无法条件删除，直接返回错误。
+func (c *Client) DeleteIf(ctx context.Context, name string, tag string) error {
+	if tag == "" {
+		return fmt.Errorf("WebDAV 服务未返回 ETag，无法条件删除 %s", name)
+	}
+	header := http.Header{}
+	header.Set("If-Match", tag)
+	resp, err := c.do(ctx, http.MethodDelete, c.objectURL(name), nil, "", header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusAccepted, http.StatusNotFound:
+		return nil
+	case http.StatusPreconditionFailed:
+		return fmt.Errorf("%w: %s", remote.ErrConditionFailed, name)
+	default:
+		return responseError("删除失败", resp)
+	}
+}
+
+// List 使用 Depth: 1 的 PROPFIND 列出目录下以 prefix 开头的文件。
+func (c *Client) List(ctx context.Context, prefix string) ([]remote.ObjectInfo, error) {
+	resp, err := c.do(ctx, "PROPFIND", c.base.String(), strings.NewReader(propfindBody), "1")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, responseError("列出对象失败", resp)
+	}
+
+	var result struct {
+		Responses []struct {
+			Href     string `xml:"href"`
+			Propstat []struct {
+				Prop struct {
+					ContentLength string `xml:"getcontentlength"`
+					LastModified  string `xml:"getlastmodified"`
+					ResourceType  struct {
+						Collection *struct{} `xml:"collection"`
+					} `xml:"resourcetype"`
+				} `xml:"prop"`
+			} `xml:"propstat"`
+		} `xml:"response"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	var items []remote.ObjectInfo
+	for _, r := range result.Responses {
+		name := c.relativeName(r.Href)
+		if name == "" || strings.Contains(name, "/") || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		info := remote.ObjectInfo{Name: name}
+		isCollection := false
+		for _, ps := range r.Propstat {
+			if ps.Prop.ResourceType.Collection != nil {
+				isCollection = true
+			}
+			if ps.Prop.ContentLength != "" {
+				info.Size, _ = strconv.ParseInt(ps.Prop.ContentLength, 10, 64)
+			}
+			if ps.Prop.LastModified != "" {
+				if t, err := http.ParseTime(ps.Prop.LastModified); err == nil {
+					info.UpdatedAt = t.UTC()
+				}
+			}
+		}
+		if !isCollection {
+			items = append(items, info)
+		}
+	}
+	return items, nil
+}
+
+// relativeName 将 PROPFIND 返回的 href 转换为相对于基础目录的对象名
+func (c *Client) relativeName(href string) string {
+	parsed, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+	p := parsed.Path
+	if !strings.HasPrefix(p, c.base.Path) {
+		return ""
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(p, c.base.Path), "/")
+}
+
+// objectURL 返回对象的完整地址，对象名中的特殊字符会被转义
+func (c *Client) objectURL(name string) string {
+	u := *c.base
+	u.Path = path.Join(c.base.Path, name)
+	return u.String()
+}
+
+// do 发送附带鉴权信息的请求，depth 非空时设置 Depth 头，extra 为需要额外附加的请求头
+func (c *Client) do(ctx context.Context, method string, target string, body io.Reader, depth string, extra ...http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range extra {
+		for name, values := range h {
+			for _, v := range values {
+				req.Header.Add(name, v)
+			}
+		}
+	}
+	if depth != "" {
+		req.Header.Set("Depth", depth)
+		req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+	} else if method == http.MethodPut {
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+
+	switch {
+	case strings.TrimSpace(c.settings.BearerToken) != "":
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(c.settings.BearerToken))
+	case strings.TrimSpace(c.settings.Username) != "":
+		req.SetBasicAuth(c.settings.Username, c.settings.Password)
+	}
+	return c.httpClient.Do(req)
+}
+
+func responseError(action string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return fmt.Errorf("%s: HTTP %d %s", action, resp.StatusCode, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,203 @@
+package webdav
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/codex-switch/codex-switch/internal/config"
+	"github.com/codex-switch/codex-switch/internal/remote"
+)
+
+// fakeDAV 实现测试所需的最小 WebDAV 语义，目录固定为 /dav/ckm/，支持 If-None-Match 与 If-Match 条件请求
+type fakeDAV struct {
+	mu      sync.Mutex
+	created bool
+	files   map[string][]byte
+	// conflict 为 true 时下一个条件请求返回 409，模拟父目录缺失等冲突
+	conflict bool
+}
+
+// failNextCondition 使下一个条件请求返回 409
+func (f *fakeDAV) failNextCondition() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.conflict = true
+}
+
+func fakeETag(data []byte) string {
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// checkCondition 按条件请求头判断是否执行，不满足时写入对应状态码并返回 false
+func (f *fakeDAV) checkCondition(w http.ResponseWriter, r *http.Request, name string) bool {
+	ifNoneMatch, ifMatch := r.Header.Get("If-None-Match"), r.Header.Get("If-Match")
+	if ifNoneMatch == "" && ifMatch == "" {
+		return true
+	}
+	if f.conflict {
+		f.conflict = false
+		w.WriteHeader(http.StatusConflict)
+		return false
+	}
+	data, exists := f.files[name]
+	if (ifNoneMatch == "*" && exists) || (ifMatch != "" && (!exists || fakeETag(data) != ifMatch)) {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return false
+	}
+	return true
+}
+
+func (f *fakeDAV) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, pass, ok := r.BasicAuth()
+	if !ok || user != "alice" || pass != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	const dir = "/dav/ckm/"
+	name := strings.TrimPrefix(r.URL.Path, dir)
+	switch r.Method {
+	case "MKCOL":
+		f.created = true
+		w.WriteHeader(http.StatusCreated)
+	case "PROPFIND":
+		if !f.created {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusMultiStatus)
+		fmt.Fprint(w, `<?xml version="1.0"?><d:multistatus xmlns:d="DAV:">`)
+		fmt.Fprintf(w, `<d:response><d:href>%s</d:href><d:propstat><d:prop><d:resourcetype><d:collection/></d:resourcetype></d:prop></d:propstat></d:response>`, dir)
+		if r.Header.Get("Depth") == "1" {
+			for file, data := range f.files {
+				fmt.Fprintf(w, `<d:response><d:href>%s%s</d:href><d:propstat><d:prop><d:getcontentlength>%d</d:getcontentlength><d:getlastmodified>Wed, 01 Jan 2025 00:00:00 GMT</d:getlastmodified><d:resourcetype/></d:prop></d:propstat></d:response>`, dir, file, len(data))
+			}
+		}
+		fmt.Fprint(w, `</d:multistatus>`)
+	case http.MethodPut:
+		if !f.checkCondition(w, r, name) {
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		f.files[name] = data
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodGet:
+		data, ok := f.files[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("ETag", fakeETag(data))
+		_, _ = w.Write(data)
+	case http.MethodDelete:
+		if !f.checkCondition(w, r, name) {
+			return
+		}
+		if _, ok := f.files[name]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		delete(f.files, name)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// TestClientAgainstFakeDAV 验证 WebDAV 的完整读写流程
+func TestClientAgainstFakeDAV(t *testing.T) {
+	fake := &fakeDAV{files: map[string][]byte{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client, err := NewClient(&config.RemoteSettings{
+		Provider: remote.ProviderWebDAV,
+		URL:      server.URL + "/dav/ckm",
+		Username: "alice",
+		Password: "secret",
+	})
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := client.Prepare(ctx); err != nil {
+		t.Fatalf("Prepare 失败: %v", err)
+	}
+	if err := client.Upload(ctx, "default.json", []byte("payload")); err != nil {
+		t.Fatalf("上传失败: %v", err)
+	}
+	data, err := client.Download(ctx, "default.json")
+	if err != nil || string(data) != "payload" {
+		t.Fatalf("下载结果不正确: %q err=%v", data, err)
+	}
+
+	items, err := client.List(ctx, "default")
+	if err != nil || len(items) != 1 || items[0].Name != "default.json" || items[0].Size != 7 || items[0].UpdatedAt.Year() != 2025 {
+		t.Fatalf("列举结果不正确: %#v err=%v", items, err)
+	}
+
+	if err := client.Delete(ctx, "default.json"); err != nil {
+		t.Fatalf("删除失败: %v", err)
+	}
+	if err := client.Delete(ctx, "default.json"); err != nil {
+		t.Fatalf("重复删除应视为成功: %v", err)
+	}
+	if _, err := client.Download(ctx, "default.json"); !errors.Is(err, remote.ErrNotFound) {
+		t.Fatalf("删除后下载应返回 ErrNotFound, got %v", err)
+	}
+
+	// 条件写入：对象已存在时返回 412；409 表示父目录缺失等冲突，不能视为对象已存在
+	if err := client.Create(ctx, "lease.json", []byte("alice")); err != nil {
+		t.Fatalf("条件写入失败: %v", err)
+	}
+	if err := client.Create(ctx, "lease.json", []byte("bob")); !errors.Is(err, remote.ErrObjectExists) {
+		t.Fatalf("对象已存在时应返回 ErrObjectExists, got %v", err)
+	}
+	fake.failNextCondition()
+	if err := client.Create(ctx, "other.json", []byte("bob")); err == nil || errors.Is(err, remote.ErrObjectExists) {
+		t.Fatalf("409 不应视为对象已存在, got %v", err)
+	}
+
+	// 条件删除：ETag 不一致返回 412，409 作为普通错误返回，均不应删除对象
+	_, tag, err := client.DownloadTagged(ctx, "lease.json")
+	if err != nil || tag == "" {
+		t.Fatalf("读取 ETag 失败: %q err=%v", tag, err)
+	}
+	if err := client.DeleteIf(ctx, "lease.json", `"stale"`); !errors.Is(err, remote.ErrConditionFailed) {
+		t.Fatalf("ETag 不一致时应返回 ErrConditionFailed, got %v", err)
+	}
+	fake.failNextCondition()
+	if err := client.DeleteIf(ctx, "lease.json", tag); err == nil || errors.Is(err, remote.ErrConditionFailed) {
+		t.Fatalf("409 应作为普通错误返回, got %v", err)
+	}
+	if err := client.DeleteIf(ctx, "lease.json", ""); err == nil {
+		t.Fatalf("空 ETag 应直接拒绝")
+	}
+	if _, err := client.Download(ctx, "lease.json"); err != nil {
+		t.Fatalf("条件删除失败后对象应保留: %v", err)
+	}
+	if err := client.DeleteIf(ctx, "lease.json", tag); err != nil {
+		t.Fatalf("条件删除失败: %v", err)
+	}
+	if _, err := client.Download(ctx, "lease.json"); !errors.Is(err, remote.ErrNotFound) {
+		t.Fatalf("条件删除后下载应返回 ErrNotFound, got %v", err)
+	}
+
+	bad, _ := NewClient(&config.RemoteSettings{URL: server.URL + "/dav/ckm", Username: "alice", Password: "wrong"})
+	if err := bad.Prepare(ctx); err == nil {
+		t.Fatalf("错误密码应鉴权失败")
+	}
+}